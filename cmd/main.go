@@ -2,18 +2,48 @@ package main
 
 import (
 	"app/internal/application"
+	"app/internal/audit"
+	"app/internal/auth"
+	"flag"
 	"fmt"
+	"os"
+	"strings"
+	"time"
 )
 
 func main() {
+	// commands
+	if len(os.Args) > 1 && os.Args[1] == "token" {
+		if err := token(os.Args[2:]); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "audit" {
+		if err := auditCommand(os.Args[2:]); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	// env
-	// ...
+	// - AUTH_KEYS_PATH: the keys file of the clients, the server does not start without it
+	authKeysPath := os.Getenv("AUTH_KEYS_PATH")
+	// - AUTH_DISABLED: true to start without a keys file, every request is anonymous and can only read
+	authDisabled := os.Getenv("AUTH_DISABLED") == "true"
+	// - AUDIT_LOG_PATH: the audit log of the requests that change the server, they are not recorded without it
+	auditLogPath := os.Getenv("AUDIT_LOG_PATH")
 
 	// app
 	// - config
 	cfg := &application.ConfigServerChi{
-		ServerAddress: ":8080",
+		ServerAddress:  ":8080",
 		LoaderFilePath: "docs/db/vehicles_100.json",
+		AuthKeysPath:   authKeysPath,
+		AuthDisabled:   authDisabled,
+		AuditLogPath:   auditLogPath,
 	}
 	app := application.NewServerChi(cfg)
 	// - run
@@ -21,4 +51,80 @@ func main() {
 		fmt.Println(err)
 		return
 	}
-}
\ No newline at end of file
+}
+
+// token is a function that prints a bearer token signed with a signing key of a keys file:
+// token -keys file -kid id -sub subject -scope "vehicles:read vehicles:write" -tenant id -ttl 1h
+func token(args []string) (err error) {
+	fs := flag.NewFlagSet("token", flag.ContinueOnError)
+	keysPath := fs.String("keys", os.Getenv("AUTH_KEYS_PATH"), "path to the keys file")
+	kid := fs.String("kid", "", "id of the signing key, the last one of the file by default")
+	sub := fs.String("sub", "", "subject of the token")
+	scope := fs.String("scope", "vehicles:read", "scopes of the token, separated by spaces")
+	tenant := fs.String("tenant", "", "tenant the token is bound to, any tenant if empty")
+	ttl := fs.Duration("ttl", time.Hour, "time the token is valid")
+	if err = fs.Parse(args); err != nil {
+		return
+	}
+	if *sub == "" {
+		err = fmt.Errorf("token: -sub is required")
+		return
+	}
+
+	kf, err := auth.LoadKeysFile(*keysPath)
+	if err != nil {
+		return
+	}
+	if len(kf.SigningKeys) == 0 {
+		err = fmt.Errorf("token: the keys file has no signing keys")
+		return
+	}
+	sk := kf.SigningKeys[len(kf.SigningKeys)-1]
+	if *kid != "" {
+		found := false
+		for _, k := range kf.SigningKeys {
+			if k.ID == *kid {
+				sk, found = k, true
+			}
+		}
+		if !found {
+			err = fmt.Errorf("token: unknown signing key %q", *kid)
+			return
+		}
+	}
+
+	now := time.Now()
+	t, err := auth.Sign(sk, auth.Claims{
+		Subject:   *sub,
+		Scope:     strings.Join(strings.Fields(*scope), " "),
+		Tenant:    *tenant,
+		ExpiresAt: now.Add(*ttl).Unix(),
+		IssuedAt:  now.Unix(),
+	})
+	if err != nil {
+		return
+	}
+	fmt.Println(t)
+	return
+}
+
+// auditCommand is a function that runs a command on an audit log:
+// audit verify -path file, checks that the records of the log were not changed, removed or inserted
+func auditCommand(args []string) (err error) {
+	if len(args) == 0 || args[0] != "verify" {
+		err = fmt.Errorf("usage: audit verify -path file")
+		return
+	}
+	fs := flag.NewFlagSet("audit verify", flag.ContinueOnError)
+	path := fs.String("path", os.Getenv("AUDIT_LOG_PATH"), "path to the audit log")
+	if err = fs.Parse(args[1:]); err != nil {
+		return
+	}
+
+	last, n, err := audit.Verify(*path)
+	if err != nil {
+		return
+	}
+	fmt.Printf("ok: %d records, last hash %s\n", n, last.Hash)
+	return
+}
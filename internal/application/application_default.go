@@ -1,11 +1,22 @@
 package application
 
 import (
+	"app/internal"
+	"app/internal/audit"
+	"app/internal/auth"
 	"app/internal/handler"
+	"app/internal/i18n"
+	"app/internal/job"
 	"app/internal/loader"
 	"app/internal/repository"
 	"app/internal/service"
+	"app/internal/validation"
+	"errors"
+	"fmt"
 	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
@@ -15,28 +26,173 @@ import (
 type ConfigServerChi struct {
 	// ServerAddress is the address where the server will be listening
 	ServerAddress string
-	// LoaderFilePath is the path to the file that contains the vehicles
+	// LoaderFilePath is the path to the file that contains the vehicles.
+	// The format is chosen by extension: .json, .ndjson, .json.gz, .ndjson.gz or a directory of .json files.
+	// An http or https url is downloaded, with conditional requests on every reload
 	LoaderFilePath string
+	// LoaderFilePaths are the paths to the files that contain the vehicles, in order of precedence.
+	// If set, they are used instead of LoaderFilePath
+	LoaderFilePaths []string
+	// LoaderConflictRule is the rule applied when two files contain a vehicle with the same id:
+	// first_wins, last_wins, fail or merge
+	LoaderConflictRule string
+	// LoaderReloadInterval is the time between two checks of the files for changes,
+	// or between two refreshes of the urls. A negative value disables the reloads
+	LoaderReloadInterval time.Duration
+	// LoaderHTTPTimeout is the time limit of each request of the urls, 0 means the default of the loader
+	LoaderHTTPTimeout time.Duration
+	// LoaderHTTPRetries is the amount of retries of a failed request of the urls,
+	// 0 means the default of the loader and a negative value disables them
+	LoaderHTTPRetries int
+	// LoaderHTTPRetryDelay is the delay before the first retry of a failed request of the urls, doubled on every retry
+	LoaderHTTPRetryDelay time.Duration
+	// ValidationRulesPath is the path to the JSON file with the validation rules of the vehicles,
+	// reloaded as the dataset when it changes. If empty, the default rules are used
+	ValidationRulesPath string
+	// DefaultLanguage is the language of the messages when the client accepts none of the supported ones
+	DefaultLanguage string
+	// JobWorkers is the amount of jobs, such as imports, processed at the same time in the background
+	JobWorkers int
+	// JobQueueSize is the amount of jobs that can wait for a worker, more are rejected until one finishes
+	JobQueueSize int
+	// JobRetention is the time the status of a finished job can be read, then it is forgotten
+	JobRetention time.Duration
+	// IdempotencyTTL is the time the response of a request with an Idempotency-Key header is replayed to its retries
+	IdempotencyTTL time.Duration
+	// AuthKeysPath is the path to the JSON file with the API keys and the keys that sign the bearer tokens,
+	// reloaded as the dataset when it changes to rotate them. It is required unless AuthDisabled is set
+	AuthKeysPath string
+	// AuthDisabled serves the requests without authentication, as an anonymous client that can only read.
+	// It is ignored if AuthKeysPath is set
+	AuthDisabled bool
+	// AuditLogPath is the path to the file of the audit log, where the requests that change the server are recorded.
+	// If empty, the requests are not recorded
+	AuditLogPath string
+	// RateLimitReads is the rate of the requests that read of each client, by API key or by IP.
+	// A negative rate disables the limit
+	RateLimitReads handler.RateLimit
+	// RateLimitWrites is the rate of the requests that write of each client, by API key or by IP.
+	// A negative rate disables the limit
+	RateLimitWrites handler.RateLimit
+	// MaxConcurrentRequests is the amount of requests of each client processed at the same time.
+	// A negative value disables the limit
+	MaxConcurrentRequests int
+	// Tenants are the fleets served by the server, by tenant id, each one with its own vehicles.
+	// If empty, the server has a single tenant, the default one, with the vehicles of LoaderFilePaths
+	Tenants map[string]ConfigTenant
+}
+
+// ConfigTenant is a struct that represents the configuration of a tenant
+type ConfigTenant struct {
+	// LoaderFilePaths are the paths to the files that contain the vehicles of the tenant, in order of precedence,
+	// with the same formats as ConfigServerChi.LoaderFilePath. If empty, the tenant starts without vehicles
+	LoaderFilePaths []string
+	// MaxVehicles is the maximum amount of vehicles of the tenant, 0 means no limit
+	MaxVehicles int
 }
 
 // NewServerChi is a function that returns a new instance of ServerChi
 func NewServerChi(cfg *ConfigServerChi) *ServerChi {
 	// default values
 	defaultConfig := &ConfigServerChi{
-		ServerAddress: ":8080",
+		ServerAddress:         ":8080",
+		LoaderConflictRule:    string(loader.ConflictFail),
+		LoaderReloadInterval:  5 * time.Second,
+		DefaultLanguage:       "es",
+		JobWorkers:            2,
+		JobQueueSize:          16,
+		JobRetention:          time.Hour,
+		IdempotencyTTL:        24 * time.Hour,
+		RateLimitReads:        handler.RateLimit{Rate: 50, Burst: 100},
+		RateLimitWrites:       handler.RateLimit{Rate: 10, Burst: 20},
+		MaxConcurrentRequests: 16,
 	}
 	if cfg != nil {
 		if cfg.ServerAddress != "" {
 			defaultConfig.ServerAddress = cfg.ServerAddress
 		}
 		if cfg.LoaderFilePath != "" {
-			defaultConfig.LoaderFilePath = cfg.LoaderFilePath
+			defaultConfig.LoaderFilePaths = []string{cfg.LoaderFilePath}
+		}
+		if len(cfg.LoaderFilePaths) > 0 {
+			defaultConfig.LoaderFilePaths = cfg.LoaderFilePaths
+		}
+		if cfg.LoaderConflictRule != "" {
+			defaultConfig.LoaderConflictRule = cfg.LoaderConflictRule
+		}
+		if cfg.LoaderReloadInterval != 0 {
+			defaultConfig.LoaderReloadInterval = cfg.LoaderReloadInterval
+		}
+		// - the loader of the urls applies its own defaults
+		defaultConfig.LoaderHTTPTimeout = cfg.LoaderHTTPTimeout
+		defaultConfig.LoaderHTTPRetries = cfg.LoaderHTTPRetries
+		defaultConfig.LoaderHTTPRetryDelay = cfg.LoaderHTTPRetryDelay
+		if cfg.ValidationRulesPath != "" {
+			defaultConfig.ValidationRulesPath = cfg.ValidationRulesPath
+		}
+		if cfg.DefaultLanguage != "" {
+			defaultConfig.DefaultLanguage = cfg.DefaultLanguage
+		}
+		if cfg.JobWorkers > 0 {
+			defaultConfig.JobWorkers = cfg.JobWorkers
+		}
+		if cfg.JobQueueSize > 0 {
+			defaultConfig.JobQueueSize = cfg.JobQueueSize
+		}
+		if cfg.JobRetention > 0 {
+			defaultConfig.JobRetention = cfg.JobRetention
+		}
+		if cfg.IdempotencyTTL > 0 {
+			defaultConfig.IdempotencyTTL = cfg.IdempotencyTTL
+		}
+		if cfg.AuthKeysPath != "" {
+			defaultConfig.AuthKeysPath = cfg.AuthKeysPath
+		}
+		defaultConfig.AuthDisabled = cfg.AuthDisabled
+		if cfg.AuditLogPath != "" {
+			defaultConfig.AuditLogPath = cfg.AuditLogPath
+		}
+		if cfg.RateLimitReads != (handler.RateLimit{}) {
+			defaultConfig.RateLimitReads = cfg.RateLimitReads
+		}
+		if cfg.RateLimitWrites != (handler.RateLimit{}) {
+			defaultConfig.RateLimitWrites = cfg.RateLimitWrites
+		}
+		if cfg.MaxConcurrentRequests != 0 {
+			defaultConfig.MaxConcurrentRequests = cfg.MaxConcurrentRequests
+		}
+		if len(cfg.Tenants) > 0 {
+			defaultConfig.Tenants = cfg.Tenants
+		}
+	}
+	if len(defaultConfig.Tenants) == 0 {
+		defaultConfig.Tenants = map[string]ConfigTenant{
+			internal.DefaultTenant: {LoaderFilePaths: defaultConfig.LoaderFilePaths},
 		}
 	}
 
 	return &ServerChi{
-		serverAddress:  defaultConfig.ServerAddress,
-		loaderFilePath: defaultConfig.LoaderFilePath,
+		serverAddress:        defaultConfig.ServerAddress,
+		loaderConflictRule:   defaultConfig.LoaderConflictRule,
+		loaderReloadInterval: defaultConfig.LoaderReloadInterval,
+		loaderHTTPTimeout:    defaultConfig.LoaderHTTPTimeout,
+		loaderHTTPRetries:    defaultConfig.LoaderHTTPRetries,
+		loaderHTTPRetryDelay: defaultConfig.LoaderHTTPRetryDelay,
+		validationRulesPath:  defaultConfig.ValidationRulesPath,
+		defaultLanguage:      defaultConfig.DefaultLanguage,
+		jobWorkers:           defaultConfig.JobWorkers,
+		jobQueueSize:         defaultConfig.JobQueueSize,
+		jobRetention:         defaultConfig.JobRetention,
+		idempotencyTTL:       defaultConfig.IdempotencyTTL,
+		authKeysPath:         defaultConfig.AuthKeysPath,
+		authDisabled:         defaultConfig.AuthDisabled,
+		tenants:              defaultConfig.Tenants,
+		auditLogPath:         defaultConfig.AuditLogPath,
+		rateLimits: handler.ConfigRateLimiter{
+			Reads:         defaultConfig.RateLimitReads,
+			Writes:        defaultConfig.RateLimitWrites,
+			MaxConcurrent: defaultConfig.MaxConcurrentRequests,
+		},
 	}
 }
 
@@ -44,57 +200,365 @@ func NewServerChi(cfg *ConfigServerChi) *ServerChi {
 type ServerChi struct {
 	// serverAddress is the address where the server will be listening
 	serverAddress string
-	// loaderFilePath is the path to the file that contains the vehicles
-	loaderFilePath string
+	// loaderConflictRule is the rule applied when two files contain a vehicle with the same id
+	loaderConflictRule string
+	// loaderReloadInterval is the time between two checks of the files for changes
+	loaderReloadInterval time.Duration
+	// loaderHTTPTimeout is the time limit of each request of the urls
+	loaderHTTPTimeout time.Duration
+	// loaderHTTPRetries is the amount of retries of a failed request of the urls
+	loaderHTTPRetries int
+	// loaderHTTPRetryDelay is the delay before the first retry of a failed request of the urls
+	loaderHTTPRetryDelay time.Duration
+	// validationRulesPath is the path to the JSON file with the validation rules of the vehicles
+	validationRulesPath string
+	// defaultLanguage is the language of the messages when the client accepts none of the supported ones
+	defaultLanguage string
+	// jobWorkers is the amount of jobs processed at the same time in the background
+	jobWorkers int
+	// jobQueueSize is the amount of jobs that can wait for a worker
+	jobQueueSize int
+	// jobRetention is the time the status of a finished job can be read
+	jobRetention time.Duration
+	// idempotencyTTL is the time the response of a request with an Idempotency-Key header is replayed to its retries
+	idempotencyTTL time.Duration
+	// authKeysPath is the path to the JSON file with the API keys and the signing keys
+	authKeysPath string
+	// authDisabled serves the requests without keys as an anonymous client that can only read
+	authDisabled bool
+	// tenants are the fleets served by the server, by tenant id
+	tenants map[string]ConfigTenant
+	// auditLogPath is the path to the file of the audit log
+	auditLogPath string
+	// rateLimits are the limits of the requests of each client
+	rateLimits handler.ConfigRateLimiter
 }
 
 // Run is a method that runs the application
 func (a *ServerChi) Run() (err error) {
 	// dependencies
-	// - loader
-	ld := loader.NewVehicleJSONFile(a.loaderFilePath)
-	db, err := ld.Load()
+	// - validator: the same rules for the loaded and the received vehicles
+	vl, rw, err := a.newValidator()
 	if err != nil {
 		return
 	}
-	// - repository
-	rp := repository.NewVehicleMap(db)
-	// - service
-	sv := service.NewVehicleDefault(rp)
+	// - tenants: each one with its own repository and service, loaded from its own files, and its own fleets
+	services := make(map[string]internal.VehicleService, len(a.tenants))
+	fleets := make(map[string]internal.FleetService, len(a.tenants))
+	reloaders := []*loader.Watcher{rw}
+	watchers := make(map[string]internal.Watcher)
+	for id, cfg := range a.tenants {
+		var sv internal.VehicleService
+		var wt *loader.Watcher
+		fm := repository.NewFleetMap()
+		sv, wt, err = a.newTenant(cfg, vl, fm.Release)
+		if err != nil {
+			err = fmt.Errorf("tenant %s: %w", id, err)
+			return
+		}
+		services[id] = sv
+		fleets[id] = service.NewFleetDefault(fm, sv)
+		if wt != nil {
+			reloaders = append(reloaders, wt)
+			if id == internal.DefaultTenant {
+				watchers["vehicles"] = wt
+			} else {
+				watchers["vehicles:"+id] = wt
+			}
+		}
+	}
+	// - authentication: the keys of the clients, without them every request is anonymous and read only, if it is disabled
+	authn, kw, err := a.newAuthentication()
+	if err != nil {
+		return
+	}
+	// - watchers: reload the repositories when the files change or the urls serve a new dataset, and the rules and keys
+	reloaders = append(reloaders, kw)
+	if rw != nil {
+		watchers["rules"] = rw
+	}
+	if kw != nil {
+		watchers["keys"] = kw
+	}
+	if a.loaderReloadInterval > 0 {
+		for _, w := range reloaders {
+			if w == nil {
+				continue
+			}
+			if err = w.Start(); err != nil {
+				return
+			}
+			defer w.Stop()
+		}
+	}
+	// - service: the one of the default tenant, for the requests without a tenant
+	sv := services[internal.DefaultTenant]
+	// - job runner: a bounded pool of workers for the jobs processed in the background
+	rn := job.NewRunner(&job.ConfigRunner{Workers: a.jobWorkers, QueueSize: a.jobQueueSize, Retention: a.jobRetention})
+	rn.Start()
+	defer rn.Stop()
 	// - handler
-	hd := handler.NewVehicleDefault(sv)
+	hd := handler.NewVehicleDefault(sv, vl)
+	rl := handler.NewRulesDefault(vl)
+	sc := handler.NewSchemaDefault(vl)
+	ad := handler.NewAdminDefault(watchers)
+	jb := handler.NewJobDefault(rn, sv, vl)
+	fl := handler.NewFleetDefault(fleets)
+	// - audit log: the requests that change the server, chained by their hashes
+	var au *handler.Audit
+	if a.auditLogPath != "" {
+		var al *audit.File
+		al, err = audit.NewFile(a.auditLogPath)
+		if err != nil {
+			return
+		}
+		defer al.Close()
+		au = handler.NewAudit(al)
+	}
+	// - idempotency: the responses replayed to the retries of the requests that create or update vehicles
+	ik := handler.NewIdempotency(a.idempotencyTTL)
+	// - localizer: the messages in the language accepted by the client
+	if _, ok := i18n.Catalogs[a.defaultLanguage]; !ok {
+		err = fmt.Errorf("%w: %s", i18n.ErrUnknownLanguage, a.defaultLanguage)
+		return
+	}
+	lc := i18n.NewLocalizer(i18n.Catalogs, a.defaultLanguage)
 	// router
 	rt := chi.NewRouter()
 	// - middlewares
 	rt.Use(middleware.Logger)
 	rt.Use(middleware.Recoverer)
+	rt.Use(lc.Middleware)
+	rt.Use(handler.Units)
+	rt.Use(authn)
+	if au != nil {
+		rt.Use(au.Middleware)
+	}
+	rt.Use(handler.NewRateLimiter(a.rateLimits).Middleware)
+	rt.Use(handler.Tenants(services))
+	rt.Use(handler.Permissions(handler.DefaultFieldPolicy))
+	// - scopes of the routes
+	read := handler.RequireScope(internal.ScopeRead)
+	write := handler.RequireScope(internal.ScopeWrite)
+	admin := handler.RequireScope(internal.ScopeAdmin)
 	// - endpoints
 	rt.Route("/vehicles", func(rt chi.Router) {
 		// - GET /vehicles
-		rt.Get("/", hd.GetAll())
+		rt.With(read).Get("/", hd.GetAll())
+		// - PATCH /vehicles?{filter}
+		rt.With(write, ik.Middleware, sc.Enforce(handler.SchemaMergePatch)).Patch("/", hd.PatchByFilter())
+		// - DELETE /vehicles?{filter}
+		rt.With(admin).Delete("/", hd.DeleteByFilter())
 		// - POST /vehicles
-		rt.Post("/", hd.Create())
+		rt.With(write, ik.Middleware, sc.Enforce(handler.SchemaCreate)).Post("/", hd.Create())
 		// - GET /vehicles/color/{color}/year/{year}
-		rt.Get("/color/{color}/year/{year}", hd.GetByColorYear())
+		rt.With(read).Get("/color/{color}/year/{year}", hd.GetByColorYear())
 		// - GET /vehicles/brand/{brand}/between/{start_year}/{end_year}
-		rt.Get("/brand/{brand}/between/{start_year}/{end_year}", hd.GetByBrandRange())
+		rt.With(read).Get("/brand/{brand}/between/{start_year}/{end_year}", hd.GetByBrandRange())
 		// - GET /vehicles/average_speed/brand/{brand}
-		rt.Get("/average_speed/brand/{brand}", hd.GetAverageSpeed())
+		rt.With(read).Get("/average_speed/brand/{brand}", hd.GetAverageSpeed())
+		// - POST /vehicles/bulk
+		rt.With(write).Post("/bulk", hd.Bulk())
 		// - POST /vehicles/batch
-		rt.Post("/batch", hd.CreateBatch())
+		rt.With(write, ik.Middleware, sc.Enforce(handler.SchemaBatch)).Post("/batch", hd.CreateBatch())
 		// - PATCH /vehicles/{id}/update_speed
-		rt.Patch("/{id}/update_speed", hd.UpdateSpeed())
+		rt.With(write, ik.Middleware, sc.Enforce(handler.SchemaPatch)).Patch("/{id}/update_speed", hd.UpdateSpeed())
 		// - GET /vehicles/fuel_type/{type}
-		rt.Get("/fuel_type/{type}", hd.GetByFuelType())
+		rt.With(read).Get("/fuel_type/{type}", hd.GetByFuelType())
+		// - GET /vehicles/{id}
+		rt.With(read).Get("/{id}", hd.GetById())
+		// - PUT /vehicles/{id}
+		rt.With(write, sc.Enforce(handler.SchemaReplace)).Put("/{id}", hd.Replace())
+		// - PATCH /vehicles/{id}
+		rt.With(write, ik.Middleware, sc.Enforce(handler.SchemaMergePatch)).Patch("/{id}", hd.Patch())
 		// - DELETE /vehicles/{id}
-		rt.Delete("/{id}", hd.Delete())
+		rt.With(admin).Delete("/{id}", hd.Delete())
 		// - PATCH /vehicles/{id}/update_fuel
-		rt.Patch("/{id}/update_fuel", hd.UpdateFuelType())
+		rt.With(write, ik.Middleware, sc.Enforce(handler.SchemaPatch)).Patch("/{id}/update_fuel", hd.UpdateFuelType())
 		// - GET /vehicles/dimensions
-		rt.Get("/dimensions", hd.GetByDimensions())
+		rt.With(read).Get("/dimensions", hd.GetByDimensions())
+		// - GET /vehicles/rules
+		rt.With(read).Get("/rules", rl.GetAll())
+		// - GET /vehicles/export
+		rt.With(read).Get("/export", hd.Export())
+		// - POST /vehicles/import
+		rt.With(write).Post("/import", hd.Import())
+	})
+	rt.Route("/fleets", func(rt chi.Router) {
+		// - GET /fleets
+		rt.With(read).Get("/", fl.GetAll())
+		// - POST /fleets
+		rt.With(write, ik.Middleware).Post("/", fl.Create())
+		// - GET /fleets/{id}
+		rt.With(read).Get("/{id}", fl.GetById())
+		// - PUT /fleets/{id}
+		rt.With(write).Put("/{id}", fl.Update())
+		// - DELETE /fleets/{id}
+		rt.With(admin).Delete("/{id}", fl.Delete())
+		// - GET /fleets/{id}/vehicles
+		rt.With(read).Get("/{id}/vehicles", fl.GetVehicles())
+		// - POST /fleets/{id}/vehicles
+		rt.With(write, ik.Middleware).Post("/{id}/vehicles", fl.Assign())
+		// - GET /fleets/{id}/vehicles/stats
+		rt.With(read).Get("/{id}/vehicles/stats", fl.GetStats())
+		// - DELETE /fleets/{id}/vehicles/{vehicle_id}
+		rt.With(write).Delete("/{id}/vehicles/{vehicle_id}", fl.Unassign())
+	})
+	rt.Route("/schemas", func(rt chi.Router) {
+		// - GET /schemas/vehicle.json
+		rt.With(read).Get("/vehicle.json", sc.GetVehicle())
+	})
+	rt.Route("/jobs", func(rt chi.Router) {
+		// - POST /jobs/import
+		rt.With(write).Post("/import", jb.Import())
+		// - GET /jobs/{id}
+		rt.With(read).Get("/{id}", jb.GetById())
+		// - DELETE /jobs/{id}
+		rt.With(write).Delete("/{id}", jb.Cancel())
+	})
+	if au != nil {
+		// - GET /audit
+		rt.With(admin).Get("/audit", au.GetAll())
+	}
+	rt.Route("/admin", func(rt chi.Router) {
+		// - GET /admin/status
+		rt.With(admin).Get("/status", ad.GetStatus())
 	})
 
 	// run server
 	err = http.ListenAndServe(a.serverAddress, rt)
 	return
 }
+
+// newTenant is a method that returns the service of the vehicles of a tenant,
+// and the watcher that reloads them, nil if the tenant has no files. onDelete is called with the ids of the deleted vehicles
+func (a *ServerChi) newTenant(cfg ConfigTenant, vl internal.VehicleValidator, onDelete func(ids []int)) (sv internal.VehicleService, wt *loader.Watcher, err error) {
+	// - repository, with the quota of the tenant, that tells the fleets about the deleted vehicles
+	rp := repository.NewVehicleMap(nil)
+	rp.SetLimit(cfg.MaxVehicles)
+	rp.OnDelete(onDelete)
+	sv = service.NewVehicleDefault(rp)
+	if len(cfg.LoaderFilePaths) == 0 {
+		return
+	}
+
+	// - loader, and the watcher that reloads the repository
+	ld, probes, err := a.newLoader(cfg.LoaderFilePaths)
+	if err != nil {
+		return
+	}
+	ld = loader.NewVehicleValidated(ld, vl)
+	db, err := ld.Load()
+	if err != nil {
+		return
+	}
+	rp.Swap(db)
+	// - the reloads are merged, so the vehicles written through the api since the previous load are kept
+	wt = loader.NewVehicleWatcher(strings.Join(cfg.LoaderFilePaths, ","), probes, ld, len(db), a.loaderReloadInterval, rp.Merge)
+	return
+}
+
+// newLoader is a method that returns the loader for the paths, and the probes that detect their changes
+func (a *ServerChi) newLoader(paths []string) (ld internal.VehicleLoader, probes []loader.Probe, err error) {
+	// - loader by format: json, ndjson, their .gz versions, a directory or an http url
+	rg := loader.NewRegistry(&loader.ConfigVehicleHTTP{
+		Timeout:    a.loaderHTTPTimeout,
+		Retries:    a.loaderHTTPRetries,
+		RetryDelay: a.loaderHTTPRetryDelay,
+	})
+
+	sources := make([]loader.VehicleSource, 0, len(paths))
+	for _, path := range paths {
+		var src internal.VehicleLoader
+		src, err = rg.New(path)
+		if err != nil {
+			return
+		}
+		sources = append(sources, loader.VehicleSource{
+			Name:   filepath.Base(path),
+			Loader: src,
+		})
+
+		// - probe: loaders that know when their source changed (urls) are their own probe
+		p, ok := src.(loader.Probe)
+		if !ok {
+			p = loader.NewFileProbe(path)
+		}
+		probes = append(probes, p)
+	}
+
+	// - single path
+	if len(sources) == 1 {
+		ld = sources[0].Loader
+		return
+	}
+
+	// - multiple paths: combined in order, each one is a source named after its path
+	rule, err := loader.ParseConflictRule(a.loaderConflictRule)
+	if err != nil {
+		return
+	}
+	ld = loader.NewVehicleComposite(sources, rule)
+	return
+}
+
+// newAuthentication is a method that returns the middleware that authenticates the requests with the configured keys,
+// and the watcher that reloads them, nil if the requests are not authenticated. Without keys it fails, unless the authentication is disabled
+func (a *ServerChi) newAuthentication() (authn func(http.Handler) http.Handler, kw *loader.Watcher, err error) {
+	// - no keys: the requests are only served, read only, if the authentication is disabled explicitly
+	if a.authKeysPath == "" {
+		if !a.authDisabled {
+			err = errors.New("auth: a keys file is required, or the authentication must be disabled explicitly")
+			return
+		}
+		authn = handler.Anonymous
+		return
+	}
+
+	// - keys file
+	kf, err := auth.LoadKeysFile(a.authKeysPath)
+	if err != nil {
+		return
+	}
+	keys := auth.NewKeys(kf)
+	authn = handler.Authenticate(keys)
+	probes := []loader.Probe{loader.NewFileProbe(a.authKeysPath)}
+	kw = loader.NewWatcher(a.authKeysPath, probes, keys.Len(), a.loaderReloadInterval, func() (items int, err error) {
+		kf, err := auth.LoadKeysFile(a.authKeysPath)
+		if err != nil {
+			return
+		}
+		keys.Set(kf)
+		items = keys.Len()
+		return
+	})
+	return
+}
+
+// newValidator is a method that returns the validator of the vehicles with the configured rules,
+// and the watcher that reloads them, nil if the default rules are used
+func (a *ServerChi) newValidator() (vl *validation.VehicleRules, rw *loader.Watcher, err error) {
+	// - default rules
+	if a.validationRulesPath == "" {
+		vl = validation.NewVehicleRules(nil)
+		return
+	}
+
+	// - rules file
+	rules, err := validation.LoadRulesFile(a.validationRulesPath)
+	if err != nil {
+		return
+	}
+	vl = validation.NewVehicleRules(rules)
+	probes := []loader.Probe{loader.NewFileProbe(a.validationRulesPath)}
+	rw = loader.NewWatcher(a.validationRulesPath, probes, len(rules), a.loaderReloadInterval, func() (items int, err error) {
+		rules, err := validation.LoadRulesFile(a.validationRulesPath)
+		if err != nil {
+			return
+		}
+		vl.Set(rules)
+		items = len(rules)
+		return
+	})
+	return
+}
@@ -0,0 +1,97 @@
+package internal
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrAuditTampered is an error that represents an audit record that does not match the chain of the previous ones
+	ErrAuditTampered = errors.New("audit log tampered")
+)
+
+// AuditRecord is a struct that represents a request recorded in the audit log. Each record is chained
+// to the previous one by its hash, so a record changed, removed or inserted breaks the chain
+type AuditRecord struct {
+	// Seq is the position of the record in the log, starting at 1
+	Seq int64 `json:"seq"`
+	// Time is the time the request finished
+	Time time.Time `json:"time"`
+	// Tenant is the tenant of the request
+	Tenant string `json:"tenant"`
+	// Subject is the name of the client
+	Subject string `json:"subject"`
+	// KeyID is the id of the API key or the signing key of the client, empty if it was not authenticated
+	KeyID string `json:"key_id,omitempty"`
+	// Method is the HTTP method of the request
+	Method string `json:"method"`
+	// Route is the pattern of the route of the request, such as /vehicles/{id}
+	Route string `json:"route"`
+	// Path is the path of the request
+	Path string `json:"path"`
+	// VehicleIDs are the ids of the vehicles the request changed or tried to change, sorted
+	VehicleIDs []int `json:"vehicle_ids,omitempty"`
+	// Status is the HTTP status code of the response
+	Status int `json:"status"`
+	// Outcome is success, or the code of the problem of the response
+	Outcome string `json:"outcome"`
+	// PrevHash is the hash of the previous record, empty for the first one
+	PrevHash string `json:"prev_hash"`
+	// Hash is the hex encoded SHA-256 hash of the record and the hash of the previous one
+	Hash string `json:"hash"`
+}
+
+// AuditFilter is a struct that represents the criteria to read the audit log, the zero value of a field matches any record
+type AuditFilter struct {
+	// Tenant is the tenant of the records
+	Tenant string
+	// Subject is the client of the records
+	Subject string
+	// Method is the HTTP method of the records
+	Method string
+	// VehicleID is the id of a vehicle the records changed
+	VehicleID int
+	// Since is the time from which the records are read
+	Since time.Time
+	// Until is the time until which the records are read, not included
+	Until time.Time
+	// AfterSeq is the position after which the records are read, to read the log by pages
+	AfterSeq int64
+	// Limit is the maximum amount of records, 0 means no limit
+	Limit int
+}
+
+// Match is a method that returns true if the record matches the filter, regardless of its limit
+func (f AuditFilter) Match(r AuditRecord) bool {
+	switch {
+	case f.Tenant != "" && r.Tenant != f.Tenant:
+		return false
+	case f.Subject != "" && r.Subject != f.Subject:
+		return false
+	case f.Method != "" && r.Method != f.Method:
+		return false
+	case !f.Since.IsZero() && r.Time.Before(f.Since):
+		return false
+	case !f.Until.IsZero() && !r.Time.Before(f.Until):
+		return false
+	case r.Seq <= f.AfterSeq:
+		return false
+	}
+	if f.VehicleID == 0 {
+		return true
+	}
+	for _, id := range r.VehicleIDs {
+		if id == f.VehicleID {
+			return true
+		}
+	}
+	return false
+}
+
+// AuditLog is an interface that represents an append-only log of the requests
+type AuditLog interface {
+	// Append is a method that adds a record at the end of the log, setting its position and its hashes
+	Append(r AuditRecord) (rec AuditRecord, err error)
+	// Find is a method that returns the records that match the filter, in order
+	Find(f AuditFilter) (r []AuditRecord, err error)
+}
@@ -0,0 +1,180 @@
+package audit
+
+import (
+	"app/internal"
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// maxRecordSize is the maximum size in bytes of a record of the file, the ids of a large import are in a single record
+const maxRecordSize = 16 << 20
+
+// NewFile is a function that opens the audit log of a file, creating it if it does not exist.
+// The chain of the records already in the file is verified, so new records are never chained to a tampered log
+func NewFile(path string) (f *File, err error) {
+	last, _, err := Verify(path)
+	if err != nil && !os.IsNotExist(err) {
+		return
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return
+	}
+	f = &File{path: path, file: file, last: last, now: time.Now}
+	return
+}
+
+// File is a struct that implements the AuditLog interface with a file of JSON lines, one record per line.
+// Records are only appended, and each one is synced to disk before Append returns
+type File struct {
+	// path is the path of the file
+	path string
+	// mu is the mutex that serializes the writes and the reads of the file
+	mu sync.Mutex
+	// file is the file, opened to append
+	file *os.File
+	// last is the last record of the file, the one the next record is chained to
+	last internal.AuditRecord
+	// now returns the current time, for the records without one
+	now func() time.Time
+}
+
+// Append is a method that adds a record at the end of the file, chained to the last one
+func (f *File) Append(r internal.AuditRecord) (rec internal.AuditRecord, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	// - chain
+	rec = r
+	if rec.Time.IsZero() {
+		rec.Time = f.now()
+	}
+	rec.Time = rec.Time.UTC()
+	rec.Seq = f.last.Seq + 1
+	rec.PrevHash = f.last.Hash
+	if rec.Hash, err = Hash(rec); err != nil {
+		return
+	}
+
+	// - write
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return
+	}
+	if _, err = f.file.Write(append(line, '\n')); err != nil {
+		return
+	}
+	if err = f.file.Sync(); err != nil {
+		return
+	}
+	f.last = rec
+	return
+}
+
+// Find is a method that returns the records of the file that match the filter, in order
+func (f *File) Find(filter internal.AuditFilter) (r []internal.AuditRecord, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	file, err := os.Open(f.path)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	r = make([]internal.AuditRecord, 0)
+	err = read(file, func(rec internal.AuditRecord, _ int) error {
+		if filter.Limit > 0 && len(r) >= filter.Limit {
+			return io.EOF
+		}
+		if filter.Match(rec) {
+			r = append(r, rec)
+		}
+		return nil
+	})
+	return
+}
+
+// Close is a method that closes the file
+func (f *File) Close() (err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	err = f.file.Close()
+	return
+}
+
+// Verify is a function that checks the chain of the records of a file: the position and the previous hash of each record,
+// and its hash. It returns the last record and the amount of records, or an ErrAuditTampered with the first broken record.
+// Records removed from the end of the file can not be detected by the chain alone, the last hash must be kept elsewhere
+func Verify(path string) (last internal.AuditRecord, n int, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	err = read(file, func(rec internal.AuditRecord, line int) (err error) {
+		hash, err := Hash(rec)
+		switch {
+		case err != nil:
+		case rec.Seq != last.Seq+1:
+			err = fmt.Errorf("%w: line %d: record %d follows record %d", internal.ErrAuditTampered, line, rec.Seq, last.Seq)
+		case rec.PrevHash != last.Hash:
+			err = fmt.Errorf("%w: line %d: record %d is not chained to the previous record", internal.ErrAuditTampered, line, rec.Seq)
+		case rec.Hash != hash:
+			err = fmt.Errorf("%w: line %d: record %d does not match its hash", internal.ErrAuditTampered, line, rec.Seq)
+		}
+		if err != nil {
+			return
+		}
+		last = rec
+		n++
+		return
+	})
+	return
+}
+
+// Hash is a function that returns the hex encoded SHA-256 hash of the JSON encoding of a record without its hash,
+// which includes the hash of the previous record
+func Hash(r internal.AuditRecord) (hash string, err error) {
+	r.Hash = ""
+	b, err := json.Marshal(r)
+	if err != nil {
+		return
+	}
+	sum := sha256.Sum256(b)
+	hash = hex.EncodeToString(sum[:])
+	return
+}
+
+// read is a function that decodes the records of a file in order, until fn returns an error, io.EOF to stop
+func read(rd io.Reader, fn func(rec internal.AuditRecord, line int) error) (err error) {
+	sc := bufio.NewScanner(rd)
+	sc.Buffer(make([]byte, 0, 64*1024), maxRecordSize)
+	for line := 1; sc.Scan(); line++ {
+		var rec internal.AuditRecord
+		dec := json.NewDecoder(bytes.NewReader(sc.Bytes()))
+		dec.DisallowUnknownFields()
+		if err = dec.Decode(&rec); err != nil {
+			err = fmt.Errorf("%w: line %d: %v", internal.ErrAuditTampered, line, err)
+			return
+		}
+		if err = fn(rec, line); err != nil {
+			if err == io.EOF {
+				err = nil
+			}
+			return
+		}
+	}
+	err = sc.Err()
+	return
+}
@@ -0,0 +1,138 @@
+package audit
+
+import (
+	"app/internal"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newLog is a function that returns the path of an audit log with a record of each tenant, acme, globex and acme
+func newLog(t *testing.T) (path string) {
+	t.Helper()
+	path = filepath.Join(t.TempDir(), "audit.log")
+	f, err := NewFile(path)
+	if err != nil {
+		t.Fatalf("new file: %v", err)
+	}
+	defer f.Close()
+	for i, tenant := range []string{"acme", "globex", "acme"} {
+		if _, err := f.Append(internal.AuditRecord{Tenant: tenant, Method: "POST", VehicleIDs: []int{i + 1}, Status: 201}); err != nil {
+			t.Fatalf("append: %v", err)
+		}
+	}
+	return
+}
+
+// lines is a function that returns the lines of a file
+func lines(t *testing.T, path string) []string {
+	t.Helper()
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Split(strings.TrimSuffix(string(b), "\n"), "\n")
+}
+
+func TestFile_Append(t *testing.T) {
+	path := newLog(t)
+
+	last, n, err := Verify(path)
+	if err != nil || n != 3 || last.Seq != 3 {
+		t.Fatalf("verify: last = %d, n = %d, err = %v, want 3, 3 and nil", last.Seq, n, err)
+	}
+
+	// - a reopened log chains the new records to the last one
+	f, err := NewFile(path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer f.Close()
+	rec, err := f.Append(internal.AuditRecord{Tenant: "acme", Method: "DELETE"})
+	if err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	if rec.Seq != 4 || rec.PrevHash != last.Hash || rec.Time.IsZero() {
+		t.Fatalf("record = %+v, want seq 4 chained to %s", rec, last.Hash)
+	}
+	if _, n, err := Verify(path); err != nil || n != 4 {
+		t.Fatalf("verify: n = %d, err = %v, want 4 and nil", n, err)
+	}
+}
+
+func TestFile_Find(t *testing.T) {
+	f, err := NewFile(newLog(t))
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer f.Close()
+
+	cases := []struct {
+		name   string
+		filter internal.AuditFilter
+		seqs   []int64
+	}{
+		{name: "all", seqs: []int64{1, 2, 3}},
+		{name: "tenant", filter: internal.AuditFilter{Tenant: "acme"}, seqs: []int64{1, 3}},
+		{name: "vehicle", filter: internal.AuditFilter{VehicleID: 2}, seqs: []int64{2}},
+		{name: "page", filter: internal.AuditFilter{AfterSeq: 1, Limit: 1}, seqs: []int64{2}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r, err := f.Find(c.filter)
+			if err != nil {
+				t.Fatalf("find: %v", err)
+			}
+			if len(r) != len(c.seqs) {
+				t.Fatalf("found %d records, want %v", len(r), c.seqs)
+			}
+			for i, seq := range c.seqs {
+				if r[i].Seq != seq {
+					t.Fatalf("record %d is %d, want %v", i, r[i].Seq, c.seqs)
+				}
+			}
+		})
+	}
+}
+
+func TestVerify_Tampered(t *testing.T) {
+	cases := []struct {
+		name   string
+		tamper func(lines []string) []string
+	}{
+		{name: "edited", tamper: func(lines []string) []string {
+			lines[1] = strings.Replace(lines[1], `"status":201`, `"status":200`, 1)
+			return lines
+		}},
+		{name: "removed", tamper: func(lines []string) []string {
+			return append(lines[:1], lines[2:]...)
+		}},
+		{name: "reordered", tamper: func(lines []string) []string {
+			lines[1], lines[2] = lines[2], lines[1]
+			return lines
+		}},
+		{name: "malformed", tamper: func(lines []string) []string {
+			lines[1] = lines[1][:len(lines[1])/2]
+			return lines
+		}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			path := newLog(t)
+			tampered := c.tamper(lines(t, path))
+			if err := os.WriteFile(path, []byte(strings.Join(tampered, "\n")+"\n"), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, _, err := Verify(path); !errors.Is(err, internal.ErrAuditTampered) {
+				t.Fatalf("verify: err = %v, want ErrAuditTampered", err)
+			}
+			// - no record is chained to a tampered log
+			if _, err := NewFile(path); !errors.Is(err, internal.ErrAuditTampered) {
+				t.Fatalf("open: err = %v, want ErrAuditTampered", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,182 @@
+package auth
+
+import (
+	"app/internal"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrInvalidKey is an error that represents a key of the keys file that can not be used
+	ErrInvalidKey = errors.New("invalid key")
+)
+
+// minSecretLength is the minimum length in bytes of the secret of a signing key
+const minSecretLength = 32
+
+// APIKey is a struct that represents an API key of a client. Only the SHA-256 hash of the key is kept,
+// a key is rotated by adding the new one and removing the old one once the client uses the new one
+type APIKey struct {
+	// ID is the identifier of the key
+	ID string `json:"id"`
+	// SHA256 is the hex encoded SHA-256 hash of the key
+	SHA256 string `json:"sha256"`
+	// Subject is the name of the client
+	Subject string `json:"subject"`
+	// Scopes are the scopes granted to the client
+	Scopes []internal.Scope `json:"scopes"`
+	// Tenant is the tenant the client is bound to, empty if it may act on any tenant
+	Tenant string `json:"tenant,omitempty"`
+	// ExpiresAt is the time from which the key is rejected, it never expires if nil
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// SigningKey is a struct that represents a secret that signs bearer tokens with HMAC-SHA256.
+// The key that signed a token is its kid, so tokens signed with the previous key are valid while it is in the file
+type SigningKey struct {
+	// ID is the identifier of the key
+	ID string `json:"id"`
+	// Secret is the secret of the key, base64 encoded in the file
+	Secret []byte `json:"secret"`
+	// ExpiresAt is the time from which the tokens signed with the key are rejected, it never expires if nil
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// KeysFile is a struct that represents the content of a keys file
+type KeysFile struct {
+	// APIKeys are the API keys of the clients
+	APIKeys []APIKey `json:"api_keys"`
+	// SigningKeys are the keys that sign the bearer tokens
+	SigningKeys []SigningKey `json:"signing_keys"`
+}
+
+// LoadKeysFile is a function that reads the keys from a JSON file and checks that they can be used
+func LoadKeysFile(path string) (kf KeysFile, err error) {
+	// open file
+	file, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	// decode file
+	dec := json.NewDecoder(file)
+	dec.DisallowUnknownFields()
+	if err = dec.Decode(&kf); err != nil {
+		return
+	}
+
+	// check keys
+	ids := make(map[string]bool)
+	for i, k := range kf.APIKeys {
+		if k.ID == "" || ids[k.ID] {
+			err = fmt.Errorf("%w: api key %d: empty or duplicated id %q", ErrInvalidKey, i, k.ID)
+			return
+		}
+		ids[k.ID] = true
+		if hash, errHash := hex.DecodeString(k.SHA256); errHash != nil || len(hash) != sha256.Size {
+			err = fmt.Errorf("%w: api key %q: sha256 is not a hex encoded SHA-256 hash", ErrInvalidKey, k.ID)
+			return
+		}
+		for _, s := range k.Scopes {
+			if !s.Valid() {
+				err = fmt.Errorf("%w: api key %q: unknown scope %q", ErrInvalidKey, k.ID, s)
+				return
+			}
+		}
+	}
+	for i, k := range kf.SigningKeys {
+		if k.ID == "" || ids[k.ID] {
+			err = fmt.Errorf("%w: signing key %d: empty or duplicated id %q", ErrInvalidKey, i, k.ID)
+			return
+		}
+		ids[k.ID] = true
+		if len(k.Secret) < minSecretLength {
+			err = fmt.Errorf("%w: signing key %q: the secret must have at least %d bytes", ErrInvalidKey, k.ID, minSecretLength)
+			return
+		}
+	}
+	return
+}
+
+// NewKeys is a function that returns a new instance of Keys
+func NewKeys(kf KeysFile) *Keys {
+	return &Keys{kf: kf, now: time.Now}
+}
+
+// Keys is a struct that implements the Authenticator interface with the keys of a keys file,
+// which can be replaced while the server runs to rotate them
+type Keys struct {
+	// mu is the mutex that protects the keys
+	mu sync.RWMutex
+	// kf are the keys
+	kf KeysFile
+	// now returns the current time, to check the expiry of the keys
+	now func() time.Time
+}
+
+// Set is a method that replaces the keys
+func (k *Keys) Set(kf KeysFile) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.kf = kf
+}
+
+// Len is a method that returns the amount of keys
+func (k *Keys) Len() int {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return len(k.kf.APIKeys) + len(k.kf.SigningKeys)
+}
+
+// AuthenticateKey is a method that returns the principal of an API key
+func (k *Keys) AuthenticateKey(key string) (p internal.Principal, err error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	sum := sha256.Sum256([]byte(key))
+	hash := hex.EncodeToString(sum[:])
+	for _, ak := range k.kf.APIKeys {
+		if subtle.ConstantTimeCompare([]byte(hash), []byte(ak.SHA256)) != 1 {
+			continue
+		}
+		if expired(ak.ExpiresAt, k.now()) {
+			err = fmt.Errorf("%w: the api key expired", internal.ErrUnauthenticated)
+			return
+		}
+		p = internal.Principal{Subject: ak.Subject, KeyID: ak.ID, Scopes: ak.Scopes, Tenant: ak.Tenant}
+		return
+	}
+	err = fmt.Errorf("%w: unknown api key", internal.ErrUnauthenticated)
+	return
+}
+
+// signingKey is a method that returns the signing key with an id, if it exists and did not expire
+func (k *Keys) signingKey(id string) (sk SigningKey, err error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	for _, sk = range k.kf.SigningKeys {
+		if sk.ID != id {
+			continue
+		}
+		if expired(sk.ExpiresAt, k.now()) {
+			err = fmt.Errorf("%w: the signing key expired", internal.ErrUnauthenticated)
+		}
+		return
+	}
+	err = fmt.Errorf("%w: unknown signing key %q", internal.ErrUnauthenticated, id)
+	return
+}
+
+// expired is a function that returns true if an expiry time is set and has been reached
+func expired(expiresAt *time.Time, now time.Time) bool {
+	return expiresAt != nil && !now.Before(*expiresAt)
+}
@@ -0,0 +1,86 @@
+package auth
+
+import (
+	"app/internal"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeKeysFile is a function that writes a keys file in a temporary directory and returns its path
+func writeKeysFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "keys.json")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// hashKey is a function that returns the hex encoded SHA-256 hash of an API key
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestLoadKeysFile(t *testing.T) {
+	// - the sample only has placeholders, it can not be deployed as is
+	if _, err := LoadKeysFile("../../docs/auth/example.keys.json"); err == nil {
+		t.Fatalf("example: err = nil, want the placeholders rejected")
+	}
+
+	hash := `"` + hashKey("read-key") + `"`
+	secret := `"` + base64.StdEncoding.EncodeToString([]byte(strings.Repeat("s", minSecretLength))) + `"`
+	kf, err := LoadKeysFile(writeKeysFile(t, `{"api_keys": [{"id": "a", "sha256": `+hash+`, "scopes": ["vehicles:read"]}], "signing_keys": [{"id": "b", "secret": `+secret+`}]}`))
+	if err != nil {
+		t.Fatalf("valid: %v", err)
+	}
+	if len(kf.APIKeys) != 1 || len(kf.SigningKeys) != 1 {
+		t.Fatalf("valid: %d api keys and %d signing keys, want 1 and 1", len(kf.APIKeys), len(kf.SigningKeys))
+	}
+
+	cases := []struct {
+		name    string
+		content string
+	}{
+		{name: "duplicated id", content: `{"api_keys": [{"id": "a", "sha256": ` + hash + `}], "signing_keys": [{"id": "a", "secret": ` + secret + `}]}`},
+		{name: "not a hash", content: `{"api_keys": [{"id": "a", "sha256": "read-key"}]}`},
+		{name: "unknown scope", content: `{"api_keys": [{"id": "a", "sha256": ` + hash + `, "scopes": ["vehicles:delete"]}]}`},
+		{name: "short secret", content: `{"signing_keys": [{"id": "a", "secret": "c2hvcnQ="}]}`},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if _, err := LoadKeysFile(writeKeysFile(t, c.content)); !errors.Is(err, ErrInvalidKey) {
+				t.Fatalf("err = %v, want ErrInvalidKey", err)
+			}
+		})
+	}
+}
+
+func TestKeys_AuthenticateKey(t *testing.T) {
+	expired := time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC)
+	k := NewKeys(KeysFile{APIKeys: []APIKey{
+		{ID: "ops-2026", SHA256: hashKey("admin-key"), Subject: "ops", Scopes: []internal.Scope{internal.ScopeAdmin}},
+		{ID: "ops-2025", SHA256: hashKey("expired-key"), Subject: "ops", Scopes: []internal.Scope{internal.ScopeAdmin}, ExpiresAt: &expired},
+	}})
+	k.now = func() time.Time { return time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC) }
+
+	p, err := k.AuthenticateKey("admin-key")
+	if err != nil {
+		t.Fatalf("admin key: %v", err)
+	}
+	if p.Subject != "ops" || p.KeyID != "ops-2026" || len(p.Scopes) != 1 || p.Scopes[0] != internal.ScopeAdmin {
+		t.Fatalf("principal = %+v, want ops with the admin scope", p)
+	}
+	for _, key := range []string{"expired-key", "unknown-key", ""} {
+		if _, err := k.AuthenticateKey(key); !errors.Is(err, internal.ErrUnauthenticated) {
+			t.Fatalf("key %q: err = %v, want ErrUnauthenticated", key, err)
+		}
+	}
+}
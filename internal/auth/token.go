@@ -0,0 +1,135 @@
+package auth
+
+import (
+	"app/internal"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// algorithm is the only signing algorithm of the tokens, HMAC-SHA256
+const algorithm = "HS256"
+
+// tokenHeader is a struct that represents the header of a token
+type tokenHeader struct {
+	// Alg is the signing algorithm
+	Alg string `json:"alg"`
+	// Typ is the type of the token
+	Typ string `json:"typ"`
+	// Kid is the id of the signing key
+	Kid string `json:"kid"`
+}
+
+// Claims is a struct that represents the claims of a bearer token
+type Claims struct {
+	// Subject is the name of the client
+	Subject string `json:"sub"`
+	// Scope are the scopes granted to the client, separated by spaces
+	Scope string `json:"scope"`
+	// Tenant is the tenant the client is bound to, empty if it may act on any tenant
+	Tenant string `json:"tenant,omitempty"`
+	// ExpiresAt is the unix time from which the token is rejected, it is required
+	ExpiresAt int64 `json:"exp"`
+	// NotBefore is the unix time before which the token is rejected
+	NotBefore int64 `json:"nbf,omitempty"`
+	// IssuedAt is the unix time the token was signed
+	IssuedAt int64 `json:"iat,omitempty"`
+}
+
+// Sign is a function that returns a bearer token with the claims, signed with the key.
+// Tokens have the compact format of a JSON Web Token: header.claims.signature, each part base64url encoded
+func Sign(sk SigningKey, c Claims) (token string, err error) {
+	header, err := json.Marshal(tokenHeader{Alg: algorithm, Typ: "JWT", Kid: sk.ID})
+	if err != nil {
+		return
+	}
+	claims, err := json.Marshal(c)
+	if err != nil {
+		return
+	}
+
+	signed := encode(header) + "." + encode(claims)
+	token = signed + "." + encode(signature(sk.Secret, signed))
+	return
+}
+
+// AuthenticateToken is a method that returns the principal of a bearer token signed with one of the signing keys
+func (k *Keys) AuthenticateToken(token string) (p internal.Principal, err error) {
+	// parts
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		err = fmt.Errorf("%w: malformed token", internal.ErrUnauthenticated)
+		return
+	}
+	var header tokenHeader
+	if err = decodeJSON(parts[0], &header); err != nil {
+		return
+	}
+	if header.Alg != algorithm {
+		err = fmt.Errorf("%w: unsupported algorithm %q", internal.ErrUnauthenticated, header.Alg)
+		return
+	}
+
+	// signature, checked before the claims are read
+	sk, err := k.signingKey(header.Kid)
+	if err != nil {
+		return
+	}
+	sig, errSig := base64.RawURLEncoding.DecodeString(parts[2])
+	if errSig != nil || !hmac.Equal(sig, signature(sk.Secret, parts[0]+"."+parts[1])) {
+		err = fmt.Errorf("%w: invalid signature", internal.ErrUnauthenticated)
+		return
+	}
+
+	// claims
+	var c Claims
+	if err = decodeJSON(parts[1], &c); err != nil {
+		return
+	}
+	now := k.now().Unix()
+	switch {
+	case c.ExpiresAt == 0:
+		err = fmt.Errorf("%w: the token has no expiry", internal.ErrUnauthenticated)
+		return
+	case now >= c.ExpiresAt:
+		err = fmt.Errorf("%w: the token expired at %s", internal.ErrUnauthenticated, time.Unix(c.ExpiresAt, 0).UTC().Format(time.RFC3339))
+		return
+	case c.NotBefore != 0 && now < c.NotBefore:
+		err = fmt.Errorf("%w: the token is not valid yet", internal.ErrUnauthenticated)
+		return
+	}
+
+	p = internal.Principal{Subject: c.Subject, KeyID: sk.ID, Tenant: c.Tenant}
+	for _, s := range strings.Fields(c.Scope) {
+		p.Scopes = append(p.Scopes, internal.Scope(s))
+	}
+	return
+}
+
+// signature is a function that returns the HMAC-SHA256 of a text with a secret
+func signature(secret []byte, text string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(text))
+	return mac.Sum(nil)
+}
+
+// encode is a function that returns the base64url encoding of the bytes, without padding
+func encode(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// decodeJSON is a function that decodes a base64url encoded JSON part of a token
+func decodeJSON(part string, v any) (err error) {
+	b, err := base64.RawURLEncoding.DecodeString(part)
+	if err == nil {
+		err = json.Unmarshal(b, v)
+	}
+	if err != nil {
+		err = fmt.Errorf("%w: malformed token: %v", internal.ErrUnauthenticated, err)
+	}
+	return
+}
@@ -0,0 +1,130 @@
+package auth
+
+import (
+	"app/internal"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// now is the time the keys of the tests are used at
+var now = time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+// newTestKeys is a function that returns keys with a current and a previous signing key, used at now
+func newTestKeys() *Keys {
+	expiry := now.Add(time.Hour)
+	k := NewKeys(KeysFile{SigningKeys: []SigningKey{
+		{ID: "previous", Secret: []byte(strings.Repeat("p", minSecretLength)), ExpiresAt: &expiry},
+		{ID: "current", Secret: []byte(strings.Repeat("c", minSecretLength))},
+	}})
+	k.now = func() time.Time { return now }
+	return k
+}
+
+// sign is a function that signs the claims with a signing key of the keys, failing the test on errors
+func sign(t *testing.T, k *Keys, kid string, c Claims) string {
+	t.Helper()
+	sk, err := k.signingKey(kid)
+	if err != nil {
+		t.Fatalf("signing key: %v", err)
+	}
+	return mustSign(t, sk, c)
+}
+
+func TestKeys_AuthenticateToken(t *testing.T) {
+	k := newTestKeys()
+	token := sign(t, k, "current", Claims{Subject: "reports", Scope: "vehicles:read vehicles:write", Tenant: "acme", ExpiresAt: now.Add(time.Minute).Unix()})
+
+	p, err := k.AuthenticateToken(token)
+	if err != nil {
+		t.Fatalf("authenticate: %v", err)
+	}
+	if p.Subject != "reports" || p.KeyID != "current" || p.Tenant != "acme" || len(p.Scopes) != 2 || p.Scopes[1] != internal.ScopeWrite {
+		t.Fatalf("principal = %+v, want reports of acme with the read and write scopes", p)
+	}
+}
+
+func TestKeys_AuthenticateToken_Rejected(t *testing.T) {
+	k := newTestKeys()
+	valid := Claims{Subject: "reports", Scope: "vehicles:read", ExpiresAt: now.Add(time.Minute).Unix()}
+	token := sign(t, k, "current", valid)
+	parts := strings.Split(token, ".")
+
+	// tampered is the token with other claims and the original signature
+	other := valid
+	other.Scope = "vehicles:admin"
+	tampered := strings.Split(sign(t, k, "current", other), ".")[1]
+
+	cases := []struct {
+		name  string
+		token func() string
+	}{
+		{name: "malformed", token: func() string { return "not-a-token" }},
+		{name: "tampered claims", token: func() string { return parts[0] + "." + tampered + "." + parts[2] }},
+		{name: "signature of another key", token: func() string {
+			return parts[0] + "." + parts[1] + "." + strings.Split(sign(t, k, "previous", valid), ".")[2]
+		}},
+		{name: "unsigned", token: func() string {
+			header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none","typ":"JWT","kid":"current"}`))
+			return header + "." + parts[1] + "."
+		}},
+		{name: "unknown key", token: func() string {
+			return mustSign(t, SigningKey{ID: "unknown", Secret: []byte(strings.Repeat("u", minSecretLength))}, valid)
+		}},
+		{name: "expired", token: func() string {
+			c := valid
+			c.ExpiresAt = now.Unix()
+			return sign(t, k, "current", c)
+		}},
+		{name: "without expiry", token: func() string {
+			c := valid
+			c.ExpiresAt = 0
+			return sign(t, k, "current", c)
+		}},
+		{name: "not valid yet", token: func() string {
+			c := valid
+			c.NotBefore = now.Add(time.Minute).Unix()
+			return sign(t, k, "current", c)
+		}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if _, err := k.AuthenticateToken(c.token()); !errors.Is(err, internal.ErrUnauthenticated) {
+				t.Fatalf("err = %v, want ErrUnauthenticated", err)
+			}
+		})
+	}
+}
+
+func TestKeys_AuthenticateToken_Rotation(t *testing.T) {
+	k := newTestKeys()
+	token := sign(t, k, "previous", Claims{Subject: "reports", ExpiresAt: now.Add(24 * time.Hour).Unix()})
+
+	// - the tokens of the previous key are valid until it expires
+	if _, err := k.AuthenticateToken(token); err != nil {
+		t.Fatalf("before the expiry: %v", err)
+	}
+	k.now = func() time.Time { return now.Add(2 * time.Hour) }
+	if _, err := k.AuthenticateToken(token); !errors.Is(err, internal.ErrUnauthenticated) {
+		t.Fatalf("after the expiry: err = %v, want ErrUnauthenticated", err)
+	}
+
+	// - or until it is removed from the file
+	k = newTestKeys()
+	k.Set(KeysFile{SigningKeys: k.kf.SigningKeys[1:]})
+	if _, err := k.AuthenticateToken(token); !errors.Is(err, internal.ErrUnauthenticated) {
+		t.Fatalf("after the removal: err = %v, want ErrUnauthenticated", err)
+	}
+}
+
+// mustSign is a function that signs the claims with a signing key, failing the test on errors
+func mustSign(t *testing.T, sk SigningKey, c Claims) string {
+	t.Helper()
+	token, err := Sign(sk, c)
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	return token
+}
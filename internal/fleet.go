@@ -0,0 +1,29 @@
+package internal
+
+// Fleet is a struct that represents a group of vehicles operated together, such as the vehicles of a depot
+type Fleet struct {
+	// Id is the unique identifier of the fleet, assigned when it is created
+	Id int
+	// Name is the name of the fleet, unique among the fleets
+	Name string
+	// Depot is the depot the fleet operates from
+	Depot string
+	// VehicleIds are the ids of the vehicles assigned to the fleet, sorted. A vehicle belongs to one fleet at most
+	VehicleIds []int
+}
+
+// FleetStats is a struct that represents the statistics of the vehicles of a fleet
+type FleetStats struct {
+	// Vehicles is the amount of vehicles
+	Vehicles int
+	// AverageMaxSpeed is the average of the max speed of the vehicles, in miles per hour
+	AverageMaxSpeed float64
+	// AverageYear is the average fabrication year of the vehicles
+	AverageYear float64
+	// TotalCapacity is the amount of people the vehicles can carry
+	TotalCapacity int
+	// ByFuelType is the amount of vehicles by fuel type
+	ByFuelType map[string]int
+	// ByBrand is the amount of vehicles by brand
+	ByBrand map[string]int
+}
@@ -0,0 +1,32 @@
+package internal
+
+import "errors"
+
+var (
+	// ErrFleetNotFound is an error for a fleet id that does not exist
+	ErrFleetNotFound = errors.New("fleet not found")
+	// ErrFleetAlreadyExists is an error for a fleet name that already exists
+	ErrFleetAlreadyExists = errors.New("fleet already exists")
+	// ErrVehicleAssigned is an error for a vehicle that is assigned to another fleet
+	ErrVehicleAssigned = errors.New("vehicle assigned to another fleet")
+	// ErrVehicleNotInFleet is an error for a vehicle that is not assigned to the fleet
+	ErrVehicleNotInFleet = errors.New("vehicle not in fleet")
+)
+
+// FleetRepository is an interface that represents a fleet repository
+type FleetRepository interface {
+	// FindAll is a method that returns a map of all fleets
+	FindAll() (f map[int]Fleet, err error)
+	// FindById is a method that returns a fleet by id
+	FindById(id int) (f Fleet, err error)
+	// Create is a method that creates a new fleet without vehicles, returning it with its assigned id
+	Create(f Fleet) (created Fleet, err error)
+	// Update is a method that replaces the name and the depot of a fleet, its vehicles are kept
+	Update(f Fleet) (updated Fleet, err error)
+	// Delete is a method that deletes a fleet, its vehicles are no longer assigned to any fleet
+	Delete(id int) (err error)
+	// Assign is a method that assigns vehicles to a fleet, atomically. Vehicles already in the fleet are kept
+	Assign(id int, vehicleIds []int) (f Fleet, err error)
+	// Unassign is a method that removes vehicles from a fleet, atomically
+	Unassign(id int, vehicleIds []int) (f Fleet, err error)
+}
@@ -0,0 +1,24 @@
+package internal
+
+// FleetService is an interface that represents a fleet service
+type FleetService interface {
+	// FindAll is a method that returns a map of all fleets
+	FindAll() (f map[int]Fleet, err error)
+	// FindById is a method that returns a fleet by id
+	FindById(id int) (f Fleet, err error)
+	// Create is a method that creates a new fleet without vehicles, returning it with its assigned id
+	Create(f Fleet) (created Fleet, err error)
+	// Update is a method that replaces the name and the depot of a fleet, its vehicles are kept
+	Update(f Fleet) (updated Fleet, err error)
+	// Delete is a method that deletes a fleet, its vehicles are not deleted
+	Delete(id int) (err error)
+	// Assign is a method that assigns existing vehicles to a fleet
+	Assign(id int, vehicleIds []int) (f Fleet, err error)
+	// Unassign is a method that removes vehicles from a fleet
+	Unassign(id int, vehicleIds []int) (f Fleet, err error)
+	// Vehicles is a method that returns a map of the vehicles of a fleet that match the filter, it may be empty.
+	// The vehicles deleted since they were assigned are left out
+	Vehicles(id int, vf VehicleFilter) (v map[int]Vehicle, err error)
+	// Stats is a method that returns the statistics of the vehicles of a fleet
+	Stats(id int) (s FleetStats, err error)
+}
@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"app/internal"
+	"fmt"
+	"net/http"
+)
+
+// NewAdminDefault is a function that returns a new instance of AdminDefault
+func NewAdminDefault(wts map[string]internal.Watcher) *AdminDefault {
+	return &AdminDefault{wts: wts, rs: NewResponder()}
+}
+
+// AdminDefault is a struct with methods that represent handlers for the administration of the server
+type AdminDefault struct {
+	// wts are the watchers that reload the sources of the server, such as the vehicles dataset, by name
+	wts map[string]internal.Watcher
+	// rs is the responder that writes the responses in the format accepted by the client
+	rs *Responder
+}
+
+// GetStatus is a method that returns a handler for the route GET /admin/status.
+// The status covers every tenant, so the clients bound to a tenant may not see it
+func (h *AdminDefault) GetStatus() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		if p, _ := PrincipalFrom(r.Context()); p.Tenant != "" {
+			h.rs.Error(w, r, fmt.Errorf("%w: the status of the server is not available to the clients of a tenant", internal.ErrTenantForbidden))
+			return
+		}
+
+		// process
+		// - get the status of the reloads
+		message := h.rs.Message(r, "success")
+		data := make(map[string]internal.ReloadStatus)
+		for name, wt := range h.wts {
+			s := wt.Status()
+			if s.LastError != "" {
+				message = h.rs.Message(r, "reload_failed")
+			}
+			data[name] = s
+		}
+
+		// response
+		h.rs.Respond(w, r, http.StatusOK, map[string]any{
+			"message": message,
+			"data":    data,
+		})
+	}
+}
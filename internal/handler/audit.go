@@ -0,0 +1,339 @@
+package handler
+
+import (
+	"app/internal"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	// auditDefaultLimit is the amount of records returned by GET /audit without a limit
+	auditDefaultLimit = 100
+	// auditMaxLimit is the maximum amount of records returned by GET /audit
+	auditMaxLimit = 1000
+)
+
+// NewAudit is a function that returns a new instance of Audit
+func NewAudit(al internal.AuditLog) *Audit {
+	return &Audit{al: al, rs: NewResponder()}
+}
+
+// Audit is a struct that records the requests that change the server in the audit log, and reads them back
+type Audit struct {
+	// al is the audit log
+	al internal.AuditLog
+	// rs is the responder that writes the responses in the format accepted by the client
+	rs *Responder
+}
+
+// auditKey is the type of the key of the audit entry in the context
+type auditKey struct{}
+
+// auditEntry is a struct that collects the ids of the vehicles changed by a request while it is processed
+type auditEntry struct {
+	// mu is the mutex that protects the ids
+	mu sync.Mutex
+	// ids are the ids of the vehicles, as a set
+	ids map[int]bool
+	// sealed is true once the request is recorded, the later changes, such as the ones of a job, are not added
+	sealed bool
+}
+
+// add is a method that adds the ids of vehicles to the entry, it does nothing on a nil entry
+func (e *auditEntry) add(ids ...int) {
+	if e == nil {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.sealed {
+		return
+	}
+	for _, id := range ids {
+		e.ids[id] = true
+	}
+}
+
+// seal is a method that returns the sorted ids of the entry and stops collecting them
+func (e *auditEntry) seal() (ids []int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.sealed = true
+	for id := range e.ids {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return
+}
+
+// auditFrom is a function that returns the audit entry of the context, nil if the request is not audited
+func auditFrom(ctx context.Context) *auditEntry {
+	e, _ := ctx.Value(auditKey{}).(*auditEntry)
+	return e
+}
+
+// Middleware is a method that returns a middleware that records in the audit log the requests that may change the server,
+// every method but GET, HEAD and OPTIONS: the client, the tenant, the route, the vehicles and the outcome.
+// It runs after the authentication, so the requests rejected by the limits, the tenants or the scopes are recorded too
+func (a *Audit) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodOptions {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		e := &auditEntry{ids: make(map[int]bool)}
+		rec := &responseRecorder{ResponseWriter: w, code: http.StatusOK}
+		defer func() {
+			// a panic is answered as a server error by the recoverer
+			if p := recover(); p != nil {
+				rec.code = http.StatusInternalServerError
+				a.record(r, rec, e)
+				panic(p)
+			}
+			a.record(r, rec, e)
+		}()
+		next.ServeHTTP(rec, r.WithContext(context.WithValue(r.Context(), auditKey{}, e)))
+	})
+}
+
+// record is a method that appends the record of a request to the audit log. The response is already written,
+// so a failure to record it is only logged
+func (a *Audit) record(r *http.Request, rec *responseRecorder, e *auditEntry) {
+	p, _ := PrincipalFrom(r.Context())
+	ar := internal.AuditRecord{
+		Time:       time.Now(),
+		Subject:    p.Subject,
+		KeyID:      p.KeyID,
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		VehicleIDs: e.seal(),
+		Status:     rec.code,
+		Outcome:    "success",
+	}
+
+	// - tenant: the one echoed by the response, or else the one requested
+	for _, tenant := range []string{rec.Header().Get("X-Tenant-ID"), p.Tenant, r.Header.Get("X-Tenant-ID"), internal.DefaultTenant} {
+		if tenant != "" {
+			ar.Tenant = tenant
+			break
+		}
+	}
+	// - route: the pattern matched by the router
+	if rc := chi.RouteContext(r.Context()); rc != nil {
+		ar.Route = rc.RoutePattern()
+	}
+	// - outcome: the code of the problem of the response
+	if rec.code >= http.StatusBadRequest {
+		var problem Problem
+		if err := json.Unmarshal(rec.body.Bytes(), &problem); err == nil && problem.Code != "" {
+			ar.Outcome = problem.Code
+		} else {
+			ar.Outcome = "error"
+		}
+	}
+
+	if _, err := a.al.Append(ar); err != nil {
+		log.Printf("audit: %s %s: %v", r.Method, r.URL.Path, err)
+	}
+}
+
+// GetAll is a method that returns a handler for the route GET /audit.
+// The records are filtered by the query parameters tenant, subject, method, vehicle_id, since and until as RFC 3339 times,
+// and paged by after, the last seq read, and limit. The clients bound to a tenant only read the records of their tenant
+func (a *Audit) GetAll() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		f, err := auditFilterFromQuery(r)
+		if err != nil {
+			a.rs.Error(w, r, ErrBadFilter.Wrap(err))
+			return
+		}
+		if p, _ := PrincipalFrom(r.Context()); p.Tenant != "" {
+			if f.Tenant != "" && f.Tenant != p.Tenant {
+				a.rs.Error(w, r, fmt.Errorf("%w: the credentials are bound to another tenant", internal.ErrTenantForbidden))
+				return
+			}
+			f.Tenant = p.Tenant
+		}
+
+		// process
+		records, err := a.al.Find(f)
+		if err != nil {
+			a.rs.Error(w, r, err)
+			return
+		}
+
+		// response
+		a.rs.Respond(w, r, http.StatusOK, map[string]any{
+			"message": a.rs.Message(r, "success"),
+			"data":    records,
+		})
+	}
+}
+
+// auditFilterFromQuery is a function that reads the filter of the audit log from the query parameters of the request
+func auditFilterFromQuery(r *http.Request) (f internal.AuditFilter, err error) {
+	q := r.URL.Query()
+
+	f.Tenant = q.Get("tenant")
+	f.Subject = q.Get("subject")
+	f.Method = q.Get("method")
+
+	// numbers
+	f.Limit = auditDefaultLimit
+	if value := q.Get("vehicle_id"); value != "" {
+		if f.VehicleID, err = strconv.Atoi(value); err != nil {
+			err = fmt.Errorf("vehicle_id: %w", err)
+			return
+		}
+	}
+	if value := q.Get("after"); value != "" {
+		if f.AfterSeq, err = strconv.ParseInt(value, 10, 64); err != nil {
+			err = fmt.Errorf("after: %w", err)
+			return
+		}
+	}
+	if value := q.Get("limit"); value != "" {
+		if f.Limit, err = strconv.Atoi(value); err != nil {
+			err = fmt.Errorf("limit: %w", err)
+			return
+		}
+		if f.Limit < 1 || f.Limit > auditMaxLimit {
+			err = fmt.Errorf("limit: must be between 1 and %d", auditMaxLimit)
+			return
+		}
+	}
+
+	// times
+	for name, ptr := range map[string]*time.Time{"since": &f.Since, "until": &f.Until} {
+		value := q.Get(name)
+		if value == "" {
+			continue
+		}
+		if *ptr, err = time.Parse(time.RFC3339, value); err != nil {
+			err = fmt.Errorf("%s: %w", name, err)
+			return
+		}
+	}
+	return
+}
+
+// newVehicleAudited is a function that returns a new instance of vehicleAudited
+func newVehicleAudited(sv internal.VehicleService, e *auditEntry) *vehicleAudited {
+	return &vehicleAudited{VehicleService: sv, e: e}
+}
+
+// vehicleAudited is a struct that implements the VehicleService interface,
+// it adds the ids of the vehicles that each change affects, even if it fails, to the audit entry of the request
+type vehicleAudited struct {
+	internal.VehicleService
+	// e is the audit entry of the request, nil if it is not audited
+	e *auditEntry
+}
+
+// Create is a method that creates a new vehicle
+func (s *vehicleAudited) Create(v internal.Vehicle) (err error) {
+	s.e.add(v.Id)
+	err = s.VehicleService.Create(v)
+	return
+}
+
+// CreateBatch is a method that creates multiple vehicles
+func (s *vehicleAudited) CreateBatch(v []internal.Vehicle, partial, dryRun bool) (err error) {
+	if !dryRun {
+		s.e.add(vehicleIds(v)...)
+	}
+	err = s.VehicleService.CreateBatch(v, partial, dryRun)
+	return
+}
+
+// UpdateSpeed is a method that updates the max speed of a vehicle
+func (s *vehicleAudited) UpdateSpeed(id int, speed float64) (err error) {
+	s.e.add(id)
+	err = s.VehicleService.UpdateSpeed(id, speed)
+	return
+}
+
+// Delete is a method that deletes a vehicle
+func (s *vehicleAudited) Delete(id int) (err error) {
+	s.e.add(id)
+	err = s.VehicleService.Delete(id)
+	return
+}
+
+// UpdateFuelType is a method that updates the fuel type of a vehicle
+func (s *vehicleAudited) UpdateFuelType(id int, fuelType string) (err error) {
+	s.e.add(id)
+	err = s.VehicleService.UpdateFuelType(id, fuelType)
+	return
+}
+
+// Import is a method that creates or replaces multiple vehicles applying the policy on id conflicts
+func (s *vehicleAudited) Import(v []internal.Vehicle, policy internal.ImportPolicy, dryRun bool) (outcomes []internal.ImportOutcome, err error) {
+	if !dryRun {
+		s.e.add(vehicleIds(v)...)
+	}
+	outcomes, err = s.VehicleService.Import(v, policy, dryRun)
+	return
+}
+
+// Save is a method that creates a vehicle or replaces an existing one
+func (s *vehicleAudited) Save(v internal.Vehicle) (created bool, err error) {
+	s.e.add(v.Id)
+	created, err = s.VehicleService.Save(v)
+	return
+}
+
+// Update is a method that replaces a vehicle with the result of a function of the current one
+func (s *vehicleAudited) Update(id int, update func(v internal.Vehicle) (internal.Vehicle, error)) (v internal.Vehicle, err error) {
+	s.e.add(id)
+	v, err = s.VehicleService.Update(id, update)
+	return
+}
+
+// UpdateByFilter is a method that replaces the vehicles that match the filter with the result of a function of each one
+func (s *vehicleAudited) UpdateByFilter(f internal.VehicleFilter, update func(v internal.Vehicle) (internal.Vehicle, error), dryRun bool) (ids []int, err error) {
+	ids, err = s.VehicleService.UpdateByFilter(f, update, dryRun)
+	if !dryRun {
+		s.e.add(ids...)
+	}
+	return
+}
+
+// DeleteByFilter is a method that deletes the vehicles that match the filter
+func (s *vehicleAudited) DeleteByFilter(f internal.VehicleFilter, dryRun bool) (ids []int, err error) {
+	ids, err = s.VehicleService.DeleteByFilter(f, dryRun)
+	if !dryRun {
+		s.e.add(ids...)
+	}
+	return
+}
+
+// Transaction is a method that runs a function with an audited service whose changes are saved only if it succeeds
+func (s *vehicleAudited) Transaction(fn func(sv internal.VehicleService) error) (err error) {
+	err = s.VehicleService.Transaction(func(sv internal.VehicleService) error {
+		return fn(newVehicleAudited(sv, s.e))
+	})
+	return
+}
+
+// vehicleIds is a function that returns the ids of the vehicles
+func vehicleIds(v []internal.Vehicle) (ids []int) {
+	ids = make([]int, 0, len(v))
+	for _, vh := range v {
+		ids = append(ids, vh.Id)
+	}
+	return
+}
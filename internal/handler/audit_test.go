@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"app/internal"
+	"app/internal/repository"
+	"app/internal/service"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// memoryLog is a struct that implements the AuditLog interface in memory
+type memoryLog struct {
+	// records are the appended records
+	records []internal.AuditRecord
+}
+
+// Append is a method that adds a record at the end of the log
+func (l *memoryLog) Append(r internal.AuditRecord) (rec internal.AuditRecord, err error) {
+	rec = r
+	rec.Seq = int64(len(l.records) + 1)
+	l.records = append(l.records, rec)
+	return
+}
+
+// Find is a method that returns the records that match the filter
+func (l *memoryLog) Find(f internal.AuditFilter) (r []internal.AuditRecord, err error) {
+	for _, rec := range l.records {
+		if f.Match(rec) {
+			r = append(r, rec)
+		}
+	}
+	return
+}
+
+func TestAudit_Middleware(t *testing.T) {
+	al := &memoryLog{}
+	rt := chi.NewRouter()
+	rt.Use(NewAudit(al).Middleware)
+	rp := repository.NewVehicleMap(map[int]internal.Vehicle{1: testVehicle(1)})
+	hd := NewVehicleDefault(service.NewVehicleDefault(rp), nil)
+	rt.Get("/vehicles/{id}", hd.GetById())
+	rt.Delete("/vehicles/{id}", hd.Delete())
+
+	for _, target := range []string{"/vehicles/1", "/vehicles/1", "/vehicles/x"} {
+		rt.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodDelete, target, nil))
+	}
+	rt.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/vehicles/1", nil))
+
+	// - the changes are recorded with their outcome, the reads are not
+	want := []string{"200 success [1]", "404 vehicle_not_found [1]", "400 bad_request []"}
+	if len(al.records) != len(want) {
+		t.Fatalf("records = %+v, want %v", al.records, want)
+	}
+	for i, rec := range al.records {
+		if rec.Route != "/vehicles/{id}" || rec.Tenant != internal.DefaultTenant {
+			t.Fatalf("record %d = %+v, want the route and the default tenant", i, rec)
+		}
+		if got := fmt.Sprintf("%d %s %v", rec.Status, rec.Outcome, rec.VehicleIDs); got != want[i] {
+			t.Fatalf("record %d = %q, want %q", i, got, want[i])
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"app/internal"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+var (
+	// ErrUnauthenticated is an error for a request without valid credentials
+	ErrUnauthenticated = &APIError{Status: http.StatusUnauthorized, Code: "unauthenticated"}
+	// ErrForbidden is an error for a request whose credentials lack the scope of the route
+	ErrForbidden = &APIError{Status: http.StatusForbidden, Code: "insufficient_scope"}
+)
+
+// principalKey is the type of the key of the principal in the context
+type principalKey struct{}
+
+// Authenticate is a function that returns a middleware that adds to the context of the request the principal of its credentials:
+// an API key in the X-API-Key header, or a signed token in the Authorization header with the Bearer scheme.
+// Requests without valid credentials are rejected
+func Authenticate(au internal.Authenticator) func(http.Handler) http.Handler {
+	rs := NewResponder()
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var p internal.Principal
+			var err error
+			scheme, token, _ := strings.Cut(r.Header.Get("Authorization"), " ")
+			switch {
+			case r.Header.Get("X-API-Key") != "":
+				p, err = au.AuthenticateKey(r.Header.Get("X-API-Key"))
+			case strings.EqualFold(scheme, "Bearer") && token != "":
+				p, err = au.AuthenticateToken(strings.TrimSpace(token))
+			default:
+				err = fmt.Errorf("%w: no api key or bearer token", internal.ErrUnauthenticated)
+			}
+			if err != nil {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="vehicles"`)
+				rs.Error(w, r, ErrUnauthenticated.Wrap(err))
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), principalKey{}, p)))
+		})
+	}
+}
+
+// Anonymous is a middleware that adds to the context of the request an anonymous principal that can only read,
+// for the servers with the authentication disabled
+func Anonymous(next http.Handler) http.Handler {
+	p := internal.Principal{Subject: "anonymous", Scopes: []internal.Scope{internal.ScopeRead}}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), principalKey{}, p)))
+	})
+}
+
+// RequireScope is a function that returns a middleware that rejects the requests whose principal lacks the scope
+func RequireScope(scope internal.Scope) func(http.Handler) http.Handler {
+	rs := NewResponder()
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if err := checkScope(r.Context(), scope); err != nil {
+				rs.Error(w, r, err)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// PrincipalFrom is a function that returns the principal of the context, false if there is none
+func PrincipalFrom(ctx context.Context) (p internal.Principal, ok bool) {
+	p, ok = ctx.Value(principalKey{}).(internal.Principal)
+	return
+}
+
+// checkScope is a function that returns an error if the principal of the context lacks the scope
+func checkScope(ctx context.Context, scope internal.Scope) (err error) {
+	p, ok := PrincipalFrom(ctx)
+	switch {
+	case !ok:
+		err = ErrUnauthenticated
+	case !p.HasScope(scope):
+		err = ErrForbidden.Wrap(fmt.Errorf("the scope %s is required", scope))
+	}
+	return
+}
@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"app/internal"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// withPrincipal is a function that returns the request with a principal granted the scope in its context
+func withPrincipal(r *http.Request, scope internal.Scope) *http.Request {
+	p := internal.Principal{Subject: "test", Scopes: []internal.Scope{scope}}
+	return r.WithContext(context.WithValue(r.Context(), principalKey{}, p))
+}
+
+// authenticatorStub is an authenticator that accepts a single API key and a single token
+type authenticatorStub struct{}
+
+// AuthenticateKey is a method that returns a principal with the write scope for the key "write-key"
+func (authenticatorStub) AuthenticateKey(key string) (p internal.Principal, err error) {
+	if key != "write-key" {
+		err = internal.ErrUnauthenticated
+		return
+	}
+	p = internal.Principal{Subject: "sync", KeyID: "k1", Scopes: []internal.Scope{internal.ScopeWrite}}
+	return
+}
+
+// AuthenticateToken is a method that returns a principal with the read scope for the token "read-token"
+func (authenticatorStub) AuthenticateToken(token string) (p internal.Principal, err error) {
+	if token != "read-token" {
+		err = errors.Join(internal.ErrUnauthenticated, errors.New("bad signature"))
+		return
+	}
+	p = internal.Principal{Subject: "reports", KeyID: "s1", Scopes: []internal.Scope{internal.ScopeRead}}
+	return
+}
+
+func TestAuthenticate(t *testing.T) {
+	cases := []struct {
+		name    string
+		headers map[string]string
+		scope   internal.Scope
+		status  int
+	}{
+		{name: "api key", headers: map[string]string{"X-API-Key": "write-key"}, scope: internal.ScopeWrite, status: http.StatusNoContent},
+		{name: "bearer token", headers: map[string]string{"Authorization": "Bearer read-token"}, scope: internal.ScopeRead, status: http.StatusNoContent},
+		{name: "insufficient scope", headers: map[string]string{"Authorization": "bearer read-token"}, scope: internal.ScopeWrite, status: http.StatusForbidden},
+		{name: "write lacks admin", headers: map[string]string{"X-API-Key": "write-key"}, scope: internal.ScopeAdmin, status: http.StatusForbidden},
+		{name: "unknown key", headers: map[string]string{"X-API-Key": "admin-key"}, scope: internal.ScopeRead, status: http.StatusUnauthorized},
+		{name: "forged token", headers: map[string]string{"Authorization": "Bearer forged"}, scope: internal.ScopeRead, status: http.StatusUnauthorized},
+		{name: "no credentials", scope: internal.ScopeRead, status: http.StatusUnauthorized},
+		{name: "basic scheme", headers: map[string]string{"Authorization": "Basic dXNlcjpwYXNz"}, scope: internal.ScopeRead, status: http.StatusUnauthorized},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNoContent)
+			})
+			h := Authenticate(authenticatorStub{})(RequireScope(c.scope)(next))
+
+			req := httptest.NewRequest(http.MethodGet, "/vehicles", nil)
+			for name, value := range c.headers {
+				req.Header.Set(name, value)
+			}
+			res := httptest.NewRecorder()
+			h.ServeHTTP(res, req)
+			if res.Code != c.status {
+				t.Fatalf("status = %d, want %d: %s", res.Code, c.status, res.Body)
+			}
+			if c.status == http.StatusUnauthorized && res.Header().Get("WWW-Authenticate") == "" {
+				t.Fatalf("WWW-Authenticate is missing")
+			}
+		})
+	}
+
+	// - a route without authentication has no principal
+	res := httptest.NewRecorder()
+	RequireScope(internal.ScopeRead)(http.NotFoundHandler()).ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/", nil))
+	if res.Code != http.StatusUnauthorized {
+		t.Fatalf("no principal: status = %d, want %d", res.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestAnonymous(t *testing.T) {
+	// - the anonymous client of a server without authentication can only read
+	cases := []struct {
+		scope  internal.Scope
+		status int
+	}{
+		{scope: internal.ScopeRead, status: http.StatusOK},
+		{scope: internal.ScopeWrite, status: http.StatusForbidden},
+		{scope: internal.ScopeAdmin, status: http.StatusForbidden},
+	}
+	for _, c := range cases {
+		t.Run(string(c.scope), func(t *testing.T) {
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+			res := httptest.NewRecorder()
+			Anonymous(RequireScope(c.scope)(next)).ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/vehicles", nil))
+			if res.Code != c.status {
+				t.Fatalf("status = %d, want %d", res.Code, c.status)
+			}
+		})
+	}
+}
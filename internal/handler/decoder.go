@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+)
+
+var (
+	// ErrInvalidRecord is an error that represents a record that can not be decoded, the next records can still be read
+	ErrInvalidRecord = errors.New("invalid record")
+)
+
+// VehicleDecoder is an interface that represents a streaming decoder of vehicle records
+type VehicleDecoder interface {
+	// Decode is a method that returns the next record as a JSON object, io.EOF when there are no more records.
+	// A record that can not be decoded returns an error wrapping ErrInvalidRecord
+	Decode() (record []byte, err error)
+}
+
+// NewVehicleDecoder is a function that returns a vehicle decoder for the format: ndjson or csv
+func NewVehicleDecoder(format string, r io.Reader) (dec VehicleDecoder, err error) {
+	switch format {
+	case "ndjson":
+		dec = &vehicleDecoderNDJSON{sc: bufio.NewScanner(r)}
+	case "csv":
+		dec = &vehicleDecoderCSV{r: csv.NewReader(r)}
+	default:
+		err = ErrUnknownEncoding
+	}
+	return
+}
+
+// vehicleDecoderNDJSON is a struct that decodes vehicles in JSON format, one per line
+type vehicleDecoderNDJSON struct {
+	// sc is the scanner of the lines
+	sc *bufio.Scanner
+}
+
+// Decode is a method that returns the next non blank line
+func (d *vehicleDecoderNDJSON) Decode() (record []byte, err error) {
+	for d.sc.Scan() {
+		line := bytes.TrimSpace(d.sc.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		record = append([]byte(nil), line...)
+		return
+	}
+	err = d.sc.Err()
+	if err == nil {
+		err = io.EOF
+	}
+	return
+}
+
+// vehicleDecoderCSV is a struct that decodes vehicles in CSV format with a header row named after the VehicleJSON fields
+type vehicleDecoderCSV struct {
+	// r is the CSV reader
+	r *csv.Reader
+	// header are the field names of the columns
+	header []string
+}
+
+// vehicleFieldKinds are the kinds of the fields of VehicleJSON by name
+var vehicleFieldKinds = func() (kinds map[string]reflect.Kind) {
+	kinds = make(map[string]reflect.Kind)
+	t := reflect.TypeOf(VehicleJSON{})
+	for i, name := range vehicleFieldNames {
+		kinds[name] = t.Field(i).Type.Kind()
+	}
+	return
+}()
+
+// Decode is a method that returns the next row as a JSON object, with its values typed as the VehicleJSON fields
+func (d *vehicleDecoderCSV) Decode() (record []byte, err error) {
+	if d.header == nil {
+		d.header, err = d.r.Read()
+		if err != nil {
+			return
+		}
+		d.r.FieldsPerRecord = len(d.header)
+	}
+
+	row, err := d.r.Read()
+	var errParse *csv.ParseError
+	if errors.As(err, &errParse) {
+		err = fmt.Errorf("%w: %v", ErrInvalidRecord, err)
+		return
+	}
+	if err != nil {
+		return
+	}
+
+	fields := make(map[string]any)
+	for i, name := range d.header {
+		// the provenance is not imported, the vehicles are created through the API
+		if name == "source" {
+			continue
+		}
+		value := row[i]
+		switch vehicleFieldKinds[name] {
+		case reflect.Int:
+			fields[name], err = strconv.Atoi(value)
+		case reflect.Float64:
+			fields[name], err = strconv.ParseFloat(value, 64)
+		default:
+			fields[name] = value
+		}
+		if err != nil {
+			err = fmt.Errorf("%w: %s: %v", ErrInvalidRecord, name, err)
+			return
+		}
+	}
+	record, err = json.Marshal(fields)
+	return
+}
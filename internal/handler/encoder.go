@@ -0,0 +1,245 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrUnknownEncoding is an error that represents an unsupported encoding format
+	ErrUnknownEncoding = errors.New("unknown encoding format")
+)
+
+// encodings are the content types of the supported encoding formats
+var encodings = map[string]string{
+	"json":   "application/json",
+	"ndjson": "application/x-ndjson",
+	"csv":    "text/csv",
+	"xml":    "application/xml",
+}
+
+// VehicleEncoder is an interface that represents a streaming encoder of vehicles
+type VehicleEncoder interface {
+	// Encode is a method that writes a vehicle
+	Encode(v VehicleJSON) (err error)
+	// Close is a method that writes whatever the format needs after the last vehicle
+	Close() (err error)
+}
+
+// NewVehicleEncoder is a function that returns a vehicle encoder for the format: json, ndjson, csv or xml
+func NewVehicleEncoder(format string, w io.Writer) (enc VehicleEncoder, err error) {
+	switch format {
+	case "json":
+		enc = &vehicleEncoderJSON{w: w}
+	case "ndjson":
+		enc = &vehicleEncoderNDJSON{enc: json.NewEncoder(w)}
+	case "csv":
+		enc = &vehicleEncoderCSV{w: csv.NewWriter(w)}
+	case "xml":
+		enc = &vehicleEncoderXML{enc: xml.NewEncoder(w)}
+	default:
+		err = ErrUnknownEncoding
+	}
+	return
+}
+
+// vehicleField is a struct that represents a field of a vehicle, named after its JSON tag
+type vehicleField struct {
+	// name is the name of the field
+	name string
+	// value is the value of the field
+	value any
+}
+
+// vehicleFieldNames are the names of the fields of VehicleJSON, in order
+var vehicleFieldNames = func() (names []string) {
+	t := reflect.TypeOf(VehicleJSON{})
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		names = append(names, name)
+	}
+	return
+}()
+
+// vehicleFields is a function that returns the fields of a vehicle, in the order of VehicleJSON
+func vehicleFields(v VehicleJSON) (fields []vehicleField) {
+	rv := reflect.ValueOf(v)
+	for i, name := range vehicleFieldNames {
+		fields = append(fields, vehicleField{name: name, value: rv.Field(i).Interface()})
+	}
+	return
+}
+
+// formatValue is a function that formats the value of a field as text
+func formatValue(value any) string {
+	switch val := value.(type) {
+	case string:
+		return val
+	case int:
+		return strconv.Itoa(val)
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	}
+	return ""
+}
+
+// vehicleEncoderJSON is a struct that encodes vehicles as a JSON array
+type vehicleEncoderJSON struct {
+	// w is the writer of the array
+	w io.Writer
+	// count is the amount of vehicles written
+	count int
+}
+
+// Encode is a method that writes a vehicle
+func (e *vehicleEncoderJSON) Encode(v VehicleJSON) (err error) {
+	sep := ","
+	if e.count == 0 {
+		sep = "["
+	}
+	bytes, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	if _, err = io.WriteString(e.w, sep); err != nil {
+		return
+	}
+	_, err = e.w.Write(bytes)
+	e.count++
+	return
+}
+
+// Close is a method that closes the array
+func (e *vehicleEncoderJSON) Close() (err error) {
+	end := "]"
+	if e.count == 0 {
+		end = "[]"
+	}
+	_, err = io.WriteString(e.w, end)
+	return
+}
+
+// vehicleEncoderNDJSON is a struct that encodes vehicles as JSON, one per line
+type vehicleEncoderNDJSON struct {
+	// enc is the JSON encoder, which ends every value with a newline
+	enc *json.Encoder
+}
+
+// Encode is a method that writes a vehicle
+func (e *vehicleEncoderNDJSON) Encode(v VehicleJSON) (err error) {
+	err = e.enc.Encode(v)
+	return
+}
+
+// Close is a method that does nothing, NDJSON has no trailer
+func (e *vehicleEncoderNDJSON) Close() (err error) {
+	return
+}
+
+// vehicleEncoderCSV is a struct that encodes vehicles as CSV with a header row
+type vehicleEncoderCSV struct {
+	// w is the CSV writer
+	w *csv.Writer
+	// header is true once the header row is written
+	header bool
+}
+
+// Encode is a method that writes a vehicle
+func (e *vehicleEncoderCSV) Encode(v VehicleJSON) (err error) {
+	if err = e.writeHeader(); err != nil {
+		return
+	}
+
+	fields := vehicleFields(v)
+	record := make([]string, 0, len(fields))
+	for _, f := range fields {
+		record = append(record, formatValue(f.value))
+	}
+	if err = e.w.Write(record); err != nil {
+		return
+	}
+	e.w.Flush()
+	err = e.w.Error()
+	return
+}
+
+// Close is a method that writes the header if no vehicle was written
+func (e *vehicleEncoderCSV) Close() (err error) {
+	if err = e.writeHeader(); err != nil {
+		return
+	}
+	e.w.Flush()
+	err = e.w.Error()
+	return
+}
+
+// writeHeader is a method that writes the header row once
+func (e *vehicleEncoderCSV) writeHeader() (err error) {
+	if e.header {
+		return
+	}
+	e.header = true
+	err = e.w.Write(vehicleFieldNames)
+	return
+}
+
+// vehicleEncoderXML is a struct that encodes vehicles as XML elements inside a <vehicles> root
+type vehicleEncoderXML struct {
+	// enc is the XML encoder
+	enc *xml.Encoder
+	// open is true once the root element is written
+	open bool
+}
+
+// Encode is a method that writes a vehicle
+func (e *vehicleEncoderXML) Encode(v VehicleJSON) (err error) {
+	if err = e.writeRoot(); err != nil {
+		return
+	}
+
+	vehicle := xml.StartElement{Name: xml.Name{Local: "vehicle"}}
+	if err = e.enc.EncodeToken(vehicle); err != nil {
+		return
+	}
+	for _, f := range vehicleFields(v) {
+		if err = e.enc.EncodeElement(formatValue(f.value), xml.StartElement{Name: xml.Name{Local: f.name}}); err != nil {
+			return
+		}
+	}
+	if err = e.enc.EncodeToken(vehicle.End()); err != nil {
+		return
+	}
+	err = e.enc.Flush()
+	return
+}
+
+// Close is a method that closes the root element
+func (e *vehicleEncoderXML) Close() (err error) {
+	if err = e.writeRoot(); err != nil {
+		return
+	}
+	if err = e.enc.EncodeToken(xml.EndElement{Name: xml.Name{Local: "vehicles"}}); err != nil {
+		return
+	}
+	err = e.enc.Flush()
+	return
+}
+
+// writeRoot is a method that writes the XML header and the root element once
+func (e *vehicleEncoderXML) writeRoot() (err error) {
+	if e.open {
+		return
+	}
+	e.open = true
+	if err = e.enc.EncodeToken(xml.ProcInst{Target: "xml", Inst: []byte(`version="1.0" encoding="UTF-8"`)}); err != nil {
+		return
+	}
+	err = e.enc.EncodeToken(xml.StartElement{Name: xml.Name{Local: "vehicles"}})
+	return
+}
@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"app/internal"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// filterParams are the query parameters of a vehicle filter
+var filterParams = []string{"brand", "model", "color", "fuel_type", "year", "start_year", "end_year", "length", "width"}
+
+// requestParams are the query parameters that any request may have, they are read by the middlewares
+var requestParams = []string{"units"}
+
+// FilterFromQuery is a function that reads a vehicle filter from the query parameters of the request.
+// The parameters are the same criteria of the list endpoints: brand, model, color, fuel_type, year,
+// start_year, end_year, and length and width as ranges in the form min-max, in the unit system of the request.
+// params are the other parameters the route accepts, any parameter that is not known is an error
+func FilterFromQuery(r *http.Request, params ...string) (f internal.VehicleFilter, err error) {
+	q := r.URL.Query()
+	if err = knownParams(q, filterParams, requestParams, params); err != nil {
+		return
+	}
+
+	f.Brand = q.Get("brand")
+	f.Model = q.Get("model")
+	f.Color = q.Get("color")
+	f.FuelType = q.Get("fuel_type")
+
+	// years
+	for name, ptr := range map[string]*int{"year": &f.Year, "start_year": &f.StartYear, "end_year": &f.EndYear} {
+		value := q.Get(name)
+		if value == "" {
+			continue
+		}
+		*ptr, err = strconv.Atoi(value)
+		if err != nil {
+			err = fmt.Errorf("%s: %w", name, err)
+			return
+		}
+	}
+
+	// dimensions
+	if f.MinLength, f.MaxLength, err = parseRange(q.Get("length")); err != nil {
+		err = fmt.Errorf("length: %w", err)
+		return
+	}
+	if f.MinWidth, f.MaxWidth, err = parseRange(q.Get("width")); err != nil {
+		err = fmt.Errorf("width: %w", err)
+		return
+	}
+	u := UnitsFrom(r.Context())
+	f.MinLength, f.MaxLength = u.CanonicalLength(f.MinLength), u.CanonicalLength(f.MaxLength)
+	f.MinWidth, f.MaxWidth = u.CanonicalLength(f.MinWidth), u.CanonicalLength(f.MaxWidth)
+
+	return
+}
+
+// bulkFromQuery is a function that reads the filter and the dry_run option of a bulk operation from the query parameters of the request.
+// The filter can only be empty with all=true, so a bulk operation never affects every vehicle by mistake
+func bulkFromQuery(r *http.Request) (f internal.VehicleFilter, dryRun bool, err error) {
+	f, err = FilterFromQuery(r, "dry_run", "all")
+	if err != nil {
+		return
+	}
+	var all bool
+	for name, ptr := range map[string]*bool{"dry_run": &dryRun, "all": &all} {
+		value := r.URL.Query().Get(name)
+		if value == "" {
+			continue
+		}
+		if *ptr, err = strconv.ParseBool(value); err != nil {
+			err = fmt.Errorf("%s: %w", name, err)
+			return
+		}
+	}
+	if f.IsZero() && !all {
+		err = errors.New("a bulk operation requires at least one criterion, or all=true")
+		return
+	}
+	return
+}
+
+// knownParams is a function that returns an error if the query has a parameter that is not in any of the lists
+func knownParams(q url.Values, lists ...[]string) (err error) {
+	known := make(map[string]bool)
+	for _, list := range lists {
+		for _, name := range list {
+			known[name] = true
+		}
+	}
+
+	var unknown []string
+	for name := range q {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		err = fmt.Errorf("unknown query parameters: %s", strings.Join(unknown, ", "))
+	}
+	return
+}
+
+// parseRange is a function that parses a range in the form min-max, an empty value is an unbounded range
+func parseRange(value string) (min, max float64, err error) {
+	if value == "" {
+		return
+	}
+
+	minValue, maxValue, ok := strings.Cut(value, "-")
+	if !ok {
+		err = fmt.Errorf("range %q is not in the form min-max", value)
+		return
+	}
+	if min, err = strconv.ParseFloat(minValue, 64); err != nil {
+		return
+	}
+	max, err = strconv.ParseFloat(maxValue, 64)
+	return
+}
@@ -0,0 +1,427 @@
+package handler
+
+import (
+	"app/internal"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+var (
+	// ErrFleetNotFound is an error for a fleet that does not exist
+	ErrFleetNotFound = &APIError{Status: http.StatusNotFound, Code: "fleet_not_found"}
+	// ErrFleetConflict is an error for a fleet name that already exists
+	ErrFleetConflict = &APIError{Status: http.StatusConflict, Code: "fleet_conflict"}
+	// ErrVehicleAssigned is an error for a vehicle that is assigned to another fleet
+	ErrVehicleAssigned = &APIError{Status: http.StatusConflict, Code: "vehicle_assigned"}
+	// ErrVehicleNotInFleet is an error for a vehicle that is not assigned to the fleet
+	ErrVehicleNotInFleet = &APIError{Status: http.StatusNotFound, Code: "vehicle_not_in_fleet"}
+)
+
+// fleetNameMaxLength is the maximum length of the name of a fleet
+const fleetNameMaxLength = 100
+
+// FleetJSON is a struct that represents a fleet in JSON format
+type FleetJSON struct {
+	ID         int    `json:"id"`
+	Name       string `json:"name"`
+	Depot      string `json:"depot"`
+	VehicleIDs []int  `json:"vehicle_ids"`
+}
+
+// FleetToFleetJSON is a function that converts a fleet to its JSON format
+func FleetToFleetJSON(f internal.Fleet) FleetJSON {
+	return FleetJSON{
+		ID:         f.Id,
+		Name:       f.Name,
+		Depot:      f.Depot,
+		VehicleIDs: f.VehicleIds,
+	}
+}
+
+// FleetRequestJSON is a struct that represents the body of the requests that create or update a fleet
+type FleetRequestJSON struct {
+	Name  string `json:"name"`
+	Depot string `json:"depot"`
+}
+
+// AssignmentJSON is a struct that represents the body of the requests that assign vehicles to a fleet
+type AssignmentJSON struct {
+	VehicleIDs []int `json:"vehicle_ids"`
+}
+
+// FleetStatsJSON is a struct that represents the statistics of a fleet in JSON format
+type FleetStatsJSON struct {
+	Vehicles        int            `json:"vehicles"`
+	AverageMaxSpeed float64        `json:"average_max_speed"`
+	AverageYear     float64        `json:"average_year"`
+	TotalPassengers int            `json:"total_passengers"`
+	ByFuelType      map[string]int `json:"by_fuel_type"`
+	ByBrand         map[string]int `json:"by_brand"`
+}
+
+// NewFleetDefault is a function that returns a new instance of FleetDefault
+func NewFleetDefault(svs map[string]internal.FleetService) *FleetDefault {
+	return &FleetDefault{svs: svs, rs: NewResponder()}
+}
+
+// FleetDefault is a struct with methods that represent handlers for fleets
+type FleetDefault struct {
+	// svs are the services of the fleets, by tenant
+	svs map[string]internal.FleetService
+	// rs is the responder that writes the responses in the format accepted by the client
+	rs *Responder
+}
+
+// service is a method that returns the service of the fleets of the tenant of a request
+func (h *FleetDefault) service(r *http.Request) (sv internal.FleetService, err error) {
+	tenant := TenantFrom(r.Context())
+	sv, ok := h.svs[tenant]
+	if !ok {
+		err = fmt.Errorf("%w: %s", internal.ErrTenantNotFound, tenant)
+	}
+	return
+}
+
+// GetAll is a method that returns a handler for the route GET /fleets
+func (h *FleetDefault) GetAll() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		sv, err := h.service(r)
+		if err != nil {
+			h.rs.Error(w, r, err)
+			return
+		}
+
+		// process
+		// - get all fleets
+		f, err := sv.FindAll()
+		if err != nil {
+			h.rs.Error(w, r, err)
+			return
+		}
+
+		// response
+		data := make(map[int]FleetJSON, len(f))
+		for id, fleet := range f {
+			data[id] = FleetToFleetJSON(fleet)
+		}
+		h.rs.Respond(w, r, http.StatusOK, map[string]any{
+			"message": h.rs.Message(r, "success"),
+			"data":    data,
+		})
+	}
+}
+
+// Create is a method that returns a handler for the route POST /fleets
+func (h *FleetDefault) Create() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		sv, err := h.service(r)
+		if err != nil {
+			h.rs.Error(w, r, err)
+			return
+		}
+		fleet, err := fleetFromBody(r)
+		if err != nil {
+			h.rs.Error(w, r, ErrBadRequest.Wrap(err))
+			return
+		}
+
+		// process
+		// - create the fleet
+		f, err := sv.Create(fleet)
+		if err != nil {
+			h.rs.Error(w, r, err)
+			return
+		}
+
+		// response
+		w.Header().Set("Location", "/fleets/"+strconv.Itoa(f.Id))
+		h.rs.Respond(w, r, http.StatusCreated, map[string]any{
+			"message": h.rs.Message(r, "fleet_created"),
+			"data":    FleetToFleetJSON(f),
+		})
+	}
+}
+
+// GetById is a method that returns a handler for the route GET /fleets/{id}
+func (h *FleetDefault) GetById() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		sv, err := h.service(r)
+		if err != nil {
+			h.rs.Error(w, r, err)
+			return
+		}
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			h.rs.Error(w, r, ErrBadRequest)
+			return
+		}
+
+		// process
+		// - get fleet by id
+		f, err := sv.FindById(id)
+		if err != nil {
+			h.rs.Error(w, r, err)
+			return
+		}
+
+		// response
+		h.rs.Respond(w, r, http.StatusOK, map[string]any{
+			"message": h.rs.Message(r, "success"),
+			"data":    FleetToFleetJSON(f),
+		})
+	}
+}
+
+// Update is a method that returns a handler for the route PUT /fleets/{id}, which replaces the name and the depot of the fleet
+func (h *FleetDefault) Update() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		sv, err := h.service(r)
+		if err != nil {
+			h.rs.Error(w, r, err)
+			return
+		}
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			h.rs.Error(w, r, ErrBadRequest)
+			return
+		}
+		fleet, err := fleetFromBody(r)
+		if err != nil {
+			h.rs.Error(w, r, ErrBadRequest.Wrap(err))
+			return
+		}
+		fleet.Id = id
+
+		// process
+		// - update the fleet
+		f, err := sv.Update(fleet)
+		if err != nil {
+			h.rs.Error(w, r, err)
+			return
+		}
+
+		// response
+		h.rs.Respond(w, r, http.StatusOK, map[string]any{
+			"message": h.rs.Message(r, "fleet_updated"),
+			"data":    FleetToFleetJSON(f),
+		})
+	}
+}
+
+// Delete is a method that returns a handler for the route DELETE /fleets/{id}, its vehicles are not deleted
+func (h *FleetDefault) Delete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		sv, err := h.service(r)
+		if err != nil {
+			h.rs.Error(w, r, err)
+			return
+		}
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			h.rs.Error(w, r, ErrBadRequest)
+			return
+		}
+
+		// process
+		// - delete the fleet, the audit log records the vehicles it had
+		f, err := sv.FindById(id)
+		if err != nil {
+			h.rs.Error(w, r, err)
+			return
+		}
+		auditFrom(r.Context()).add(f.VehicleIds...)
+		if err = sv.Delete(id); err != nil {
+			h.rs.Error(w, r, err)
+			return
+		}
+
+		// response
+		h.rs.Respond(w, r, http.StatusOK, map[string]string{
+			"message": h.rs.Message(r, "fleet_deleted"),
+		})
+	}
+}
+
+// Assign is a method that returns a handler for the route POST /fleets/{id}/vehicles,
+// which assigns the vehicles of the body to the fleet: {"vehicle_ids": [1, 2]}
+func (h *FleetDefault) Assign() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		sv, err := h.service(r)
+		if err != nil {
+			h.rs.Error(w, r, err)
+			return
+		}
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			h.rs.Error(w, r, ErrBadRequest)
+			return
+		}
+		var body AssignmentJSON
+		if err = json.NewDecoder(r.Body).Decode(&body); err != nil {
+			h.rs.Error(w, r, ErrBadRequest.Wrap(err))
+			return
+		}
+		if len(body.VehicleIDs) == 0 {
+			h.rs.Error(w, r, ErrBadRequest.Wrap(errors.New("vehicle_ids can not be empty")))
+			return
+		}
+
+		// process
+		// - assign the vehicles
+		auditFrom(r.Context()).add(body.VehicleIDs...)
+		f, err := sv.Assign(id, body.VehicleIDs)
+		if err != nil {
+			h.rs.Error(w, r, err)
+			return
+		}
+
+		// response
+		h.rs.Respond(w, r, http.StatusOK, map[string]any{
+			"message": h.rs.Message(r, "vehicles_assigned"),
+			"data":    FleetToFleetJSON(f),
+		})
+	}
+}
+
+// Unassign is a method that returns a handler for the route DELETE /fleets/{id}/vehicles/{vehicle_id},
+// which removes the vehicle from the fleet, the vehicle is not deleted
+func (h *FleetDefault) Unassign() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		sv, err := h.service(r)
+		if err != nil {
+			h.rs.Error(w, r, err)
+			return
+		}
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			h.rs.Error(w, r, ErrBadRequest)
+			return
+		}
+		vehicleId, err := strconv.Atoi(chi.URLParam(r, "vehicle_id"))
+		if err != nil {
+			h.rs.Error(w, r, ErrBadRequest)
+			return
+		}
+
+		// process
+		// - remove the vehicle
+		auditFrom(r.Context()).add(vehicleId)
+		f, err := sv.Unassign(id, []int{vehicleId})
+		if err != nil {
+			h.rs.Error(w, r, err)
+			return
+		}
+
+		// response
+		h.rs.Respond(w, r, http.StatusOK, map[string]any{
+			"message": h.rs.Message(r, "vehicle_removed"),
+			"data":    FleetToFleetJSON(f),
+		})
+	}
+}
+
+// GetVehicles is a method that returns a handler for the route GET /fleets/{id}/vehicles.
+// The vehicles can be filtered with the same query parameters of GET /vehicles
+func (h *FleetDefault) GetVehicles() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		sv, err := h.service(r)
+		if err != nil {
+			h.rs.Error(w, r, err)
+			return
+		}
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			h.rs.Error(w, r, ErrBadRequest)
+			return
+		}
+		filter, err := FilterFromQuery(r)
+		if err != nil {
+			h.rs.Error(w, r, ErrBadFilter.Wrap(err))
+			return
+		}
+
+		// process
+		// - get the vehicles of the fleet
+		v, err := sv.Vehicles(id, filter)
+		if err != nil {
+			h.rs.Error(w, r, err)
+			return
+		}
+
+		// response
+		h.rs.Vehicles(w, r, http.StatusOK, DataMap(&v))
+	}
+}
+
+// GetStats is a method that returns a handler for the route GET /fleets/{id}/vehicles/stats
+func (h *FleetDefault) GetStats() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		sv, err := h.service(r)
+		if err != nil {
+			h.rs.Error(w, r, err)
+			return
+		}
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			h.rs.Error(w, r, ErrBadRequest)
+			return
+		}
+
+		// process
+		// - get the statistics of the vehicles of the fleet
+		st, err := sv.Stats(id)
+		if err != nil {
+			h.rs.Error(w, r, err)
+			return
+		}
+
+		// response
+		u := UnitsFrom(r.Context())
+		h.rs.Respond(w, r, http.StatusOK, map[string]any{
+			"message": h.rs.Message(r, "success"),
+			"units":   map[string]string{"average_max_speed": u.SpeedUnit()},
+			"data": FleetStatsJSON{
+				Vehicles:        st.Vehicles,
+				AverageMaxSpeed: round(u.Speed(st.AverageMaxSpeed)),
+				AverageYear:     round(st.AverageYear),
+				TotalPassengers: st.TotalCapacity,
+				ByFuelType:      st.ByFuelType,
+				ByBrand:         st.ByBrand,
+			},
+		})
+	}
+}
+
+// fleetFromBody is a function that reads the name and the depot of a fleet from the body of the request,
+// the name is required
+func fleetFromBody(r *http.Request) (f internal.Fleet, err error) {
+	var body FleetRequestJSON
+	if err = json.NewDecoder(r.Body).Decode(&body); err != nil {
+		return
+	}
+
+	f.Name = strings.TrimSpace(body.Name)
+	f.Depot = strings.TrimSpace(body.Depot)
+	switch {
+	case f.Name == "":
+		err = errors.New("name is required")
+	case len(f.Name) > fleetNameMaxLength:
+		err = errors.New("name is too long")
+	}
+	return
+}
@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"app/internal"
+	"app/internal/repository"
+	"app/internal/service"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// newFleetRouter is a function that returns a router with the routes of /fleets,
+// the vehicles 1 to 3 exist and there are no fleets
+func newFleetRouter(t *testing.T) (rt *chi.Mux, rp *repository.VehicleMap) {
+	t.Helper()
+	db := make(map[int]internal.Vehicle)
+	for id := 1; id <= 3; id++ {
+		db[id] = testVehicle(id)
+	}
+	rp = repository.NewVehicleMap(db)
+	sv := service.NewFleetDefault(repository.NewFleetMap(), service.NewVehicleDefault(rp))
+	hd := NewFleetDefault(map[string]internal.FleetService{internal.DefaultTenant: sv})
+
+	rt = chi.NewRouter()
+	rt.Get("/fleets", hd.GetAll())
+	rt.Post("/fleets", hd.Create())
+	rt.Get("/fleets/{id}", hd.GetById())
+	rt.Put("/fleets/{id}", hd.Update())
+	rt.Delete("/fleets/{id}", hd.Delete())
+	rt.Post("/fleets/{id}/vehicles", hd.Assign())
+	rt.Get("/fleets/{id}/vehicles", hd.GetVehicles())
+	rt.Get("/fleets/{id}/vehicles/stats", hd.GetStats())
+	rt.Delete("/fleets/{id}/vehicles/{vehicle_id}", hd.Unassign())
+	return
+}
+
+// fleetOf is a function that decodes the fleet of a response
+func fleetOf(t *testing.T, res *httptest.ResponseRecorder) (f FleetJSON) {
+	t.Helper()
+	var body struct {
+		Data FleetJSON `json:"data"`
+	}
+	if err := json.Unmarshal(res.Body.Bytes(), &body); err != nil {
+		t.Fatalf("fleet: %v: %s", err, res.Body)
+	}
+	f = body.Data
+	return
+}
+
+func TestFleetDefault_Create(t *testing.T) {
+	rt, _ := newFleetRouter(t)
+	do := func(body string) *httptest.ResponseRecorder {
+		res := httptest.NewRecorder()
+		rt.ServeHTTP(res, httptest.NewRequest(http.MethodPost, "/fleets", strings.NewReader(body)))
+		return res
+	}
+
+	res := do(`{"name": " North ", "depot": "Madrid"}`)
+	if res.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want %d: %s", res.Code, http.StatusCreated, res.Body)
+	}
+	if f := fleetOf(t, res); f.ID != 1 || f.Name != "North" || len(f.VehicleIDs) != 0 {
+		t.Fatalf("fleet = %+v, want fleet 1 North without vehicles", f)
+	}
+	if loc := res.Header().Get("Location"); loc != "/fleets/1" {
+		t.Fatalf("location = %q, want /fleets/1", loc)
+	}
+
+	cases := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{name: "duplicated name", body: `{"name": "North"}`, status: http.StatusConflict},
+		{name: "missing name", body: `{"depot": "Madrid"}`, status: http.StatusBadRequest},
+		{name: "long name", body: `{"name": "` + strings.Repeat("a", fleetNameMaxLength+1) + `"}`, status: http.StatusBadRequest},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if res := do(c.body); res.Code != c.status {
+				t.Fatalf("status = %d, want %d: %s", res.Code, c.status, res.Body)
+			}
+		})
+	}
+}
+
+func TestFleetDefault_Assign(t *testing.T) {
+	rt, rp := newFleetRouter(t)
+	do := func(method, target, body string) *httptest.ResponseRecorder {
+		res := httptest.NewRecorder()
+		rt.ServeHTTP(res, httptest.NewRequest(method, target, strings.NewReader(body)))
+		return res
+	}
+	do(http.MethodPost, "/fleets", `{"name": "North"}`)
+	do(http.MethodPost, "/fleets", `{"name": "South"}`)
+
+	res := do(http.MethodPost, "/fleets/1/vehicles", `{"vehicle_ids": [2, 1]}`)
+	if res.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", res.Code, http.StatusOK, res.Body)
+	}
+	if got := fleetOf(t, res).VehicleIDs; len(got) != 2 || got[0] != 1 || got[1] != 2 {
+		t.Fatalf("vehicle ids = %v, want [1 2]", got)
+	}
+
+	cases := []struct {
+		name   string
+		target string
+		body   string
+		status int
+		code   string
+		detail string
+	}{
+		{name: "vehicle of another fleet", target: "/fleets/2/vehicles", body: `{"vehicle_ids": [3, 1]}`, status: http.StatusConflict, code: "vehicle_assigned", detail: "vehicle_id=1, fleet_id=1"},
+		{name: "unknown vehicle", target: "/fleets/2/vehicles", body: `{"vehicle_ids": [9]}`, status: http.StatusNotFound, code: "vehicle_not_found", detail: "id=9"},
+		{name: "unknown fleet", target: "/fleets/9/vehicles", body: `{"vehicle_ids": [3]}`, status: http.StatusNotFound, code: "fleet_not_found", detail: "id=9"},
+		{name: "no vehicles", target: "/fleets/2/vehicles", body: `{"vehicle_ids": []}`, status: http.StatusBadRequest, code: "bad_request"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			res := do(http.MethodPost, c.target, c.body)
+			if res.Code != c.status {
+				t.Fatalf("status = %d, want %d: %s", res.Code, c.status, res.Body)
+			}
+			if p := problemOf(t, res); p.Code != c.code || !strings.Contains(p.Detail, c.detail) {
+				t.Fatalf("problem = %+v, want the code %s and the detail with %q", p, c.code, c.detail)
+			}
+		})
+	}
+	// - a failed assignment assigns none of the vehicles
+	if got := fleetOf(t, do(http.MethodGet, "/fleets/2", "")).VehicleIDs; len(got) != 0 {
+		t.Fatalf("vehicle ids of fleet 2 = %v, want none", got)
+	}
+
+	// - the vehicles deleted since they were assigned are left out of the listing and the stats
+	if err := rp.Delete(2); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	res = do(http.MethodGet, "/fleets/1/vehicles/stats", "")
+	var stats struct {
+		Data FleetStatsJSON `json:"data"`
+	}
+	json.Unmarshal(res.Body.Bytes(), &stats)
+	if stats.Data.Vehicles != 1 || stats.Data.TotalPassengers != 5 || stats.Data.ByBrand["Ford"] != 1 {
+		t.Fatalf("stats = %+v, want the vehicle 1 only", stats.Data)
+	}
+
+	// - unassign
+	if res := do(http.MethodDelete, "/fleets/1/vehicles/1", ""); res.Code != http.StatusOK {
+		t.Fatalf("unassign: status = %d, want %d: %s", res.Code, http.StatusOK, res.Body)
+	}
+	res = do(http.MethodDelete, "/fleets/1/vehicles/1", "")
+	if res.Code != http.StatusNotFound || problemOf(t, res).Code != "vehicle_not_in_fleet" {
+		t.Fatalf("unassign again: status = %d, want %d: %s", res.Code, http.StatusNotFound, res.Body)
+	}
+}
@@ -0,0 +1,188 @@
+package handler
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrBadIdempotencyKey is an error for an empty or too long Idempotency-Key header
+	ErrBadIdempotencyKey = &APIError{Status: http.StatusBadRequest, Code: "invalid_idempotency_key"}
+	// ErrIdempotencyMismatch is an error for a key reused with a different request
+	ErrIdempotencyMismatch = &APIError{Status: http.StatusUnprocessableEntity, Code: "idempotency_key_mismatch"}
+	// ErrIdempotencyInProgress is an error for a retry received while the original request is being processed
+	ErrIdempotencyInProgress = &APIError{Status: http.StatusConflict, Code: "idempotency_key_in_progress"}
+)
+
+// idempotencyKeyMaxLength is the maximum length of an Idempotency-Key header
+const idempotencyKeyMaxLength = 255
+
+// replayedHeaders are the headers of a response that are stored with it and replayed to the retries
+var replayedHeaders = []string{"Content-Type", "Content-Language", "Location", "Accept-Patch", "X-Units"}
+
+// NewIdempotency is a function that returns a new instance of Idempotency, whose responses are kept for ttl
+func NewIdempotency(ttl time.Duration) *Idempotency {
+	return &Idempotency{ttl: ttl, responses: make(map[string]*idempotentResponse)}
+}
+
+// Idempotency is a struct that stores the first response of the requests with an Idempotency-Key header,
+// to replay it to the retries of the same request
+type Idempotency struct {
+	// ttl is the time a response is kept
+	ttl time.Duration
+	// mu is the mutex that protects the responses
+	mu sync.Mutex
+	// responses are the stored responses, by key
+	responses map[string]*idempotentResponse
+}
+
+// idempotentResponse is a struct that represents the stored response of a request
+type idempotentResponse struct {
+	// fingerprint is the hash of the method, the url and the body of the request
+	fingerprint [sha256.Size]byte
+	// done is false while the request is being processed
+	done bool
+	// expires is the time the response is discarded
+	expires time.Time
+	// code is the status code of the response
+	code int
+	// header are the replayed headers of the response
+	header http.Header
+	// body is the body of the response
+	body []byte
+}
+
+// Middleware is a method that returns a middleware that processes once the requests with an Idempotency-Key header:
+// an identical retry gets the stored response, with the Idempotent-Replayed header,
+// and a retry with a different body is rejected. Requests without the header are processed as usual.
+// Server errors are not stored, so the retries of a failed request are processed again
+func (h *Idempotency) Middleware(next http.Handler) http.Handler {
+	rs := NewResponder()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key, ok := r.Header["Idempotency-Key"]
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if key[0] == "" || len(key[0]) > idempotencyKeyMaxLength {
+			rs.Error(w, r, ErrBadIdempotencyKey.Wrap(errors.New("the key must have between 1 and 255 characters")))
+			return
+		}
+
+		// read into bytes, the body is restored for the handler
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			rs.Error(w, r, ErrBadRequest.Wrap(err))
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		fingerprint := sha256.Sum256(bytes.Join([][]byte{[]byte(r.Method), []byte(r.URL.RequestURI()), body}, []byte{0}))
+
+		// stored response, or a reservation of the key for this request.
+		// Keys are scoped by tenant and client, so a client can not get the responses of another one
+		p, _ := PrincipalFrom(r.Context())
+		scoped := TenantFrom(r.Context()) + "\x00" + p.Subject + "\x00" + key[0]
+		stored, err := h.reserve(scoped, fingerprint)
+		if err != nil {
+			rs.Error(w, r, err)
+			return
+		}
+		if stored != nil {
+			for name, values := range stored.header {
+				w.Header()[name] = values
+			}
+			w.Header().Set("Idempotent-Replayed", "true")
+			w.WriteHeader(stored.code)
+			w.Write(stored.body)
+			return
+		}
+
+		rec := &responseRecorder{ResponseWriter: w, code: http.StatusOK}
+		defer func() {
+			// a panic is answered as a server error by the recoverer, so the key is released
+			if p := recover(); p != nil {
+				rec.code = http.StatusInternalServerError
+				h.store(scoped, rec)
+				panic(p)
+			}
+			h.store(scoped, rec)
+		}()
+		next.ServeHTTP(rec, r)
+	})
+}
+
+// reserve is a method that returns the stored response of a key, or reserves the key for a new request if it has none.
+// The expired responses are discarded
+func (h *Idempotency) reserve(key string, fingerprint [sha256.Size]byte) (stored *idempotentResponse, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := time.Now()
+	for k, res := range h.responses {
+		if res.done && now.After(res.expires) {
+			delete(h.responses, k)
+		}
+	}
+
+	res, ok := h.responses[key]
+	switch {
+	case !ok:
+		h.responses[key] = &idempotentResponse{fingerprint: fingerprint}
+	case res.fingerprint != fingerprint:
+		err = ErrIdempotencyMismatch.Wrap(errors.New("the key was used by a request with a different method, url or body"))
+	case !res.done:
+		err = ErrIdempotencyInProgress
+	default:
+		stored = res
+	}
+	return
+}
+
+// store is a method that stores the recorded response of a key, or releases the key if it was a server error
+func (h *Idempotency) store(key string, rec *responseRecorder) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if rec.code >= http.StatusInternalServerError {
+		delete(h.responses, key)
+		return
+	}
+	header := make(http.Header)
+	for _, name := range replayedHeaders {
+		if values := rec.Header().Values(name); len(values) > 0 {
+			header[name] = values
+		}
+	}
+	res := h.responses[key]
+	res.done = true
+	res.expires = time.Now().Add(h.ttl)
+	res.code = rec.code
+	res.header = header
+	res.body = rec.body.Bytes()
+}
+
+// responseRecorder is a struct that writes a response while keeping a copy of its status code and body
+type responseRecorder struct {
+	http.ResponseWriter
+	// code is the status code of the response
+	code int
+	// body is the copy of the body of the response
+	body bytes.Buffer
+}
+
+// WriteHeader is a method that writes the status code of the response
+func (rec *responseRecorder) WriteHeader(code int) {
+	rec.code = code
+	rec.ResponseWriter.WriteHeader(code)
+}
+
+// Write is a method that writes the body of the response
+func (rec *responseRecorder) Write(b []byte) (int, error) {
+	rec.body.Write(b)
+	return rec.ResponseWriter.Write(b)
+}
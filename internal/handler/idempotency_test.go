@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// counter is a handler that answers every request with the amount of requests it processed, or with status if it is set
+type counter struct {
+	// calls is the amount of requests processed
+	calls int32
+	// status is the status code of the responses, 201 if it is 0
+	status int
+}
+
+// ServeHTTP is a method that processes a request
+func (c *counter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	n := atomic.AddInt32(&c.calls, 1)
+	status := c.status
+	if status == 0 {
+		status = http.StatusCreated
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, `{"call": %d}`, n)
+}
+
+// post is a function that sends a POST request with an Idempotency-Key header, if key is not empty
+func post(h http.Handler, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/vehicles", strings.NewReader(body))
+	if key != "" {
+		req.Header.Set("Idempotency-Key", key)
+	}
+	res := httptest.NewRecorder()
+	h.ServeHTTP(res, req)
+	return res
+}
+
+func TestIdempotency_Middleware_Replay(t *testing.T) {
+	c := &counter{}
+	h := NewIdempotency(time.Hour).Middleware(c)
+
+	first := post(h, "k1", `{"id": 1}`)
+	retry := post(h, "k1", `{"id": 1}`)
+
+	// - the retry gets the stored response and the request is processed once
+	if c.calls != 1 {
+		t.Fatalf("calls = %d, want 1", c.calls)
+	}
+	if retry.Code != first.Code || retry.Body.String() != first.Body.String() {
+		t.Fatalf("retry = %d %s, want %d %s", retry.Code, retry.Body, first.Code, first.Body)
+	}
+	if retry.Header().Get("Idempotent-Replayed") != "true" || retry.Header().Get("Content-Type") != "application/json" {
+		t.Fatalf("retry headers = %v, want the stored ones and Idempotent-Replayed", retry.Header())
+	}
+
+	// - another key, or no key, is another request
+	post(h, "k2", `{"id": 1}`)
+	post(h, "", `{"id": 1}`)
+	post(h, "", `{"id": 1}`)
+	if c.calls != 4 {
+		t.Fatalf("calls = %d, want 4", c.calls)
+	}
+}
+
+func TestIdempotency_Middleware_Rejected(t *testing.T) {
+	ct := &counter{}
+	h := NewIdempotency(time.Hour).Middleware(ct)
+	post(h, "k1", `{"id": 1}`)
+
+	cases := []struct {
+		name   string
+		key    string
+		body   string
+		status int
+	}{
+		{name: "different body", key: "k1", body: `{"id": 2}`, status: http.StatusUnprocessableEntity},
+		{name: "empty key", key: "", body: `{"id": 1}`, status: http.StatusBadRequest},
+		{name: "too long key", key: strings.Repeat("k", idempotencyKeyMaxLength+1), body: `{"id": 1}`, status: http.StatusBadRequest},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/vehicles", strings.NewReader(c.body))
+			req.Header["Idempotency-Key"] = []string{c.key}
+			res := httptest.NewRecorder()
+			h.ServeHTTP(res, req)
+
+			if res.Code != c.status {
+				t.Fatalf("status = %d, want %d: %s", res.Code, c.status, res.Body)
+			}
+		})
+	}
+	if ct.calls != 1 {
+		t.Fatalf("calls = %d, want 1", ct.calls)
+	}
+}
+
+func TestIdempotency_Middleware_InProgress(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	h := NewIdempotency(time.Hour).Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() { done <- post(h, "k1", `{"id": 1}`) }()
+	<-started
+
+	if res := post(h, "k1", `{"id": 1}`); res.Code != http.StatusConflict {
+		t.Fatalf("status = %d, want %d", res.Code, http.StatusConflict)
+	}
+	close(release)
+	if res := <-done; res.Code != http.StatusCreated {
+		t.Fatalf("original: status = %d, want %d", res.Code, http.StatusCreated)
+	}
+}
+
+func TestIdempotency_Middleware_NotStored(t *testing.T) {
+	t.Run("server error", func(t *testing.T) {
+		c := &counter{status: http.StatusInternalServerError}
+		h := NewIdempotency(time.Hour).Middleware(c)
+
+		post(h, "k1", `{"id": 1}`)
+		if res := post(h, "k1", `{"id": 1}`); res.Header().Get("Idempotent-Replayed") != "" || c.calls != 2 {
+			t.Fatalf("calls = %d, replayed = %q, want the retry processed again", c.calls, res.Header().Get("Idempotent-Replayed"))
+		}
+	})
+	t.Run("expired", func(t *testing.T) {
+		c := &counter{}
+		h := NewIdempotency(10 * time.Millisecond).Middleware(c)
+
+		post(h, "k1", `{"id": 1}`)
+		time.Sleep(20 * time.Millisecond)
+		if res := post(h, "k1", `{"id": 1}`); res.Header().Get("Idempotent-Replayed") != "" || c.calls != 2 {
+			t.Fatalf("calls = %d, replayed = %q, want the retry processed again", c.calls, res.Header().Get("Idempotent-Replayed"))
+		}
+	})
+}
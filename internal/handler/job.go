@@ -0,0 +1,213 @@
+package handler
+
+import (
+	"app/internal"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// jobChunkSize is the amount of vehicles imported at once by an import job, the job can be canceled between two chunks
+const jobChunkSize = 100
+
+// NewJobDefault is a function that returns a new instance of JobDefault
+func NewJobDefault(rn internal.JobRunner, sv internal.VehicleService, vl internal.VehicleValidator) *JobDefault {
+	return &JobDefault{rn: rn, sv: sv, vl: vl, rs: NewResponder()}
+}
+
+// JobDefault is a struct with methods that represent handlers for the jobs processed in the background
+type JobDefault struct {
+	// rn is the runner of the jobs
+	rn internal.JobRunner
+	// sv is the service of the vehicles imported by the jobs of the requests without a tenant
+	sv internal.VehicleService
+	// vl is the validator of the imported vehicles
+	vl internal.VehicleValidator
+	// rs is the responder that writes the responses in the format accepted by the client
+	rs *Responder
+}
+
+// jobRecord is a struct that represents a record of the file of an import job
+type jobRecord struct {
+	// row is the number of the record in the file, starting at 1
+	row int
+	// data is the record as JSON
+	data []byte
+	// err is the reason the record could not be decoded
+	err error
+}
+
+// Import is a method that returns a handler for the route POST /jobs/import.
+// It takes the same options and file as POST /vehicles/import, the file is read with the request
+// and its vehicles are validated and imported in the background
+func (h *JobDefault) Import() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		// - options and file
+		dec, policy, dryRun, err := importRequest(r)
+		if err != nil {
+			h.rs.Error(w, r, err)
+			return
+		}
+
+		// - records
+		records := []jobRecord{}
+		for row := 1; ; row++ {
+			data, err := dec.Decode()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if errors.Is(err, ErrInvalidRecord) {
+				records = append(records, jobRecord{row: row, err: err})
+				continue
+			}
+			if err != nil {
+				h.rs.Error(w, r, ErrBadRequest.Wrap(err))
+				return
+			}
+			records = append(records, jobRecord{row: row, data: data})
+		}
+
+		// process
+		// - audit: the vehicles are imported after the request is recorded, so the ids of the file are recorded instead
+		if e := auditFrom(r.Context()); e != nil && !dryRun {
+			for _, rd := range records {
+				var v struct {
+					ID int `json:"id"`
+				}
+				if rd.err == nil && json.Unmarshal(rd.data, &v) == nil {
+					e.add(v.ID)
+				}
+			}
+		}
+		// - queue the job
+		j, err := h.rn.Submit(TenantFrom(r.Context()), "import", len(records), h.importTask(h.service(r), records, UnitsFrom(r.Context()), policy, dryRun))
+		if err != nil {
+			h.rs.Error(w, r, err)
+			return
+		}
+
+		// response
+		w.Header().Set("Location", "/jobs/"+j.ID)
+		h.rs.Respond(w, r, http.StatusAccepted, map[string]any{
+			"message": h.rs.Message(r, "job_accepted"),
+			"data":    j,
+		})
+	}
+}
+
+// service is a method that returns the service of the vehicles for a request: the one of its tenant,
+// which rejects the changes of the fields that the role of the client may not modify
+func (h *JobDefault) service(r *http.Request) internal.VehicleService {
+	return NewVehicleGuarded(serviceFrom(r.Context(), h.sv), FieldRulesFrom(r.Context()))
+}
+
+// importTask is a method that returns the task of an import job, run with the service of the request that submitted it.
+// The invalid records are reported, the valid ones are imported in chunks.
+// With the fail policy the conflicts are checked before importing any chunk
+func (h *JobDefault) importTask(sv internal.VehicleService, records []jobRecord, u internal.UnitSystem, policy internal.ImportPolicy, dryRun bool) internal.JobTask {
+	return func(ctx context.Context, p internal.JobProgress) (result any, err error) {
+		summary := map[string]int{"created": 0, "updated": 0, "skipped": 0, "invalid": 0}
+		result = map[string]any{"dry_run": dryRun, "summary": summary}
+
+		// - validate the records
+		valid := []internal.Vehicle{}
+		for _, rec := range records {
+			if rec.err != nil {
+				p.Fail(rec.row, 0, rec.err)
+				summary["invalid"]++
+				continue
+			}
+			vehicle, v, errRecord := importRecord(rec.data, u, h.vl)
+			if errRecord != nil {
+				p.Fail(rec.row, vehicle.ID, errRecord)
+				summary["invalid"]++
+				continue
+			}
+			valid = append(valid, v)
+		}
+
+		// - check the conflicts of the whole file
+		if policy == internal.ImportFail && !dryRun {
+			if _, err = sv.Import(valid, policy, true); err != nil {
+				return
+			}
+		}
+
+		// - import by chunks, until the job is canceled
+		for start := 0; start < len(valid); start += jobChunkSize {
+			if err = ctx.Err(); err != nil {
+				return
+			}
+			end := min(start+jobChunkSize, len(valid))
+
+			var outcomes []internal.ImportOutcome
+			outcomes, err = sv.Import(valid[start:end], policy, dryRun)
+			if err != nil {
+				return
+			}
+			for _, outcome := range outcomes {
+				summary[string(outcome)]++
+			}
+			p.Add(end - start)
+		}
+		return
+	}
+}
+
+// GetById is a method that returns a handler for the route GET /jobs/{id}
+func (h *JobDefault) GetById() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// process
+		j, err := h.job(r)
+		if err != nil {
+			h.rs.Error(w, r, err)
+			return
+		}
+
+		// response
+		h.rs.Respond(w, r, http.StatusOK, map[string]any{
+			"message": h.rs.Message(r, "success"),
+			"data":    j,
+		})
+	}
+}
+
+// Cancel is a method that returns a handler for the route DELETE /jobs/{id}.
+// A queued job is canceled at once, a running job when it finishes its current step
+func (h *JobDefault) Cancel() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// process
+		j, err := h.job(r)
+		if err == nil {
+			j, err = h.rn.Cancel(j.ID)
+		}
+		if err != nil {
+			h.rs.Error(w, r, err)
+			return
+		}
+
+		// response
+		code, message := http.StatusOK, h.rs.Message(r, "job_canceled")
+		if j.State != internal.JobCanceled {
+			code, message = http.StatusAccepted, h.rs.Message(r, "job_canceling")
+		}
+		h.rs.Respond(w, r, code, map[string]any{
+			"message": message,
+			"data":    j,
+		})
+	}
+}
+
+// job is a method that returns the job of the path of a request, if it belongs to the tenant of the request
+func (h *JobDefault) job(r *http.Request) (j internal.Job, err error) {
+	j, err = h.rn.Get(chi.URLParam(r, "id"))
+	if err == nil && j.Tenant != TenantFrom(r.Context()) {
+		j, err = internal.Job{}, internal.ErrJobNotFound
+	}
+	return
+}
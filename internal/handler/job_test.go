@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"app/internal"
+	"app/internal/job"
+	"app/internal/repository"
+	"app/internal/service"
+	"app/internal/validation"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// newJobRouter is a function that returns a router with the job routes, a runner that is started if start is true,
+// and the repository with the vehicle 1
+func newJobRouter(t *testing.T, start bool, queueSize int) (rt *chi.Mux, rp *repository.VehicleMap) {
+	t.Helper()
+	existing := internal.Vehicle{Id: 1}
+	existing.Brand = "Hummer"
+	rp = repository.NewVehicleMap(map[int]internal.Vehicle{1: existing})
+	rn := job.NewRunner(&job.ConfigRunner{Workers: 1, QueueSize: queueSize})
+	if start {
+		rn.Start()
+		t.Cleanup(rn.Stop)
+	}
+	jb := NewJobDefault(rn, service.NewVehicleDefault(rp), validation.NewVehicleRules(nil))
+
+	rt = chi.NewRouter()
+	rt.Post("/jobs/import", jb.Import())
+	rt.Get("/jobs/{id}", jb.GetById())
+	rt.Delete("/jobs/{id}", jb.Cancel())
+	return
+}
+
+// jobOf is a function that returns the job of the body of a response
+func jobOf(t *testing.T, res *httptest.ResponseRecorder) internal.Job {
+	t.Helper()
+	var body struct {
+		Data internal.Job `json:"data"`
+	}
+	if err := json.Unmarshal(res.Body.Bytes(), &body); err != nil {
+		t.Fatalf("body: %v", err)
+	}
+	return body.Data
+}
+
+func TestJobDefault_Import(t *testing.T) {
+	rt, rp := newJobRouter(t, true, 4)
+
+	res := httptest.NewRecorder()
+	rt.ServeHTTP(res, newImportRequest(t, "/jobs/import?on_conflict=skip", "vehicles.csv", importCSV))
+	if res.Code != http.StatusAccepted {
+		t.Fatalf("status = %d, want %d: %s", res.Code, http.StatusAccepted, res.Body)
+	}
+	j := jobOf(t, res)
+	if res.Header().Get("Location") != "/jobs/"+j.ID || j.Total != 3 {
+		t.Fatalf("job = %+v, location = %q, want 3 records at /jobs/{id}", j, res.Header().Get("Location"))
+	}
+
+	// - poll until the job finishes
+	deadline := time.Now().Add(5 * time.Second)
+	for !j.State.Finished() {
+		if time.Now().After(deadline) {
+			t.Fatalf("job = %+v, want it finished", j)
+		}
+		time.Sleep(5 * time.Millisecond)
+		res = httptest.NewRecorder()
+		rt.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/jobs/"+j.ID, nil))
+		j = jobOf(t, res)
+	}
+	if j.State != internal.JobSucceeded || j.Processed != 3 || len(j.Errors) != 1 || j.Errors[0].Record != 2 {
+		t.Fatalf("job = %+v, want succeeded with the record 2 failed", j)
+	}
+	if v, _ := rp.FindAll(); len(v) != 2 || v[1].Brand != "Hummer" {
+		t.Fatalf("vehicles = %+v, want the vehicle 2 created and the vehicle 1 skipped", v)
+	}
+
+	// - a finished job can not be canceled
+	res = httptest.NewRecorder()
+	rt.ServeHTTP(res, httptest.NewRequest(http.MethodDelete, "/jobs/"+j.ID, nil))
+	if res.Code != http.StatusConflict {
+		t.Fatalf("cancel: status = %d, want %d", res.Code, http.StatusConflict)
+	}
+}
+
+func TestJobDefault_Queue(t *testing.T) {
+	// the runner is not started, so the jobs stay queued
+	rt, rp := newJobRouter(t, false, 1)
+
+	res := httptest.NewRecorder()
+	rt.ServeHTTP(res, newImportRequest(t, "/jobs/import", "vehicles.csv", importCSV))
+	queued := jobOf(t, res)
+
+	// - full queue
+	res = httptest.NewRecorder()
+	rt.ServeHTTP(res, newImportRequest(t, "/jobs/import", "vehicles.csv", importCSV))
+	if res.Code != http.StatusServiceUnavailable {
+		t.Fatalf("full queue: status = %d, want %d", res.Code, http.StatusServiceUnavailable)
+	}
+
+	// - a queued job is canceled at once
+	res = httptest.NewRecorder()
+	rt.ServeHTTP(res, httptest.NewRequest(http.MethodDelete, "/jobs/"+queued.ID, nil))
+	if j := jobOf(t, res); res.Code != http.StatusOK || j.State != internal.JobCanceled {
+		t.Fatalf("cancel: status = %d, job = %+v, want %d and canceled", res.Code, j, http.StatusOK)
+	}
+	if v, _ := rp.FindAll(); len(v) != 1 {
+		t.Fatalf("repository has %d vehicles, want 1", len(v))
+	}
+
+	// - unknown job
+	res = httptest.NewRecorder()
+	rt.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/jobs/unknown", nil))
+	if res.Code != http.StatusNotFound {
+		t.Fatalf("unknown: status = %d, want %d", res.Code, http.StatusNotFound)
+	}
+}
@@ -0,0 +1,167 @@
+package handler
+
+import (
+	"app/internal"
+	"app/internal/patch"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+)
+
+// MutableFields are the fields of a vehicle that a partial update can change, by JSON name.
+// The identity of a vehicle (id, brand, model and year) and its provenance can not be changed
+var MutableFields = map[string]bool{
+	"registration": true,
+	"color":        true,
+	"passengers":   true,
+	"max_speed":    true,
+	"fuel_type":    true,
+	"transmission": true,
+	"weight":       true,
+	"height":       true,
+	"length":       true,
+	"width":        true,
+}
+
+const (
+	// mediaTypeMergePatch is the media type of a JSON Merge Patch (RFC 7396)
+	mediaTypeMergePatch = "application/merge-patch+json"
+	// mediaTypeJSONPatch is the media type of a JSON Patch (RFC 6902)
+	mediaTypeJSONPatch = "application/json-patch+json"
+)
+
+var (
+	//ErrBadPatch is an error for a malformed patch or one that can not be applied
+	ErrBadPatch = &APIError{Status: http.StatusBadRequest, Code: "invalid_patch"}
+	//ErrPatchTest is an error for a failed test operation of a JSON Patch
+	ErrPatchTest = &APIError{Status: http.StatusConflict, Code: "patch_test_failed"}
+	//ErrImmutableField is an error for a patch that changes fields that are not mutable
+	ErrImmutableField = &APIError{Status: http.StatusUnprocessableEntity, Code: "immutable_field"}
+	//ErrBadMediaType is an error for an unsupported media type of the body
+	ErrBadMediaType = &APIError{Status: http.StatusUnsupportedMediaType, Code: "unsupported_media_type"}
+)
+
+// patchVehicle is a function that applies a patch of a media type to a vehicle, a JSON Merge Patch by default.
+// The patch is applied to the vehicle as it is answered in the unit system u, and only the mutable fields may change
+func patchVehicle(v internal.Vehicle, mediaType string, body []byte, u internal.UnitSystem) (patched internal.Vehicle, err error) {
+	// - document: the vehicle as the client sees it
+	doc, err := vehicleDocument(VehicleToVehicleJSON(v).inUnits(u))
+	if err != nil {
+		return
+	}
+
+	// - apply
+	var result any
+	switch mediaType {
+	case mediaTypeMergePatch, "application/json", "":
+		var p any
+		if err = json.Unmarshal(body, &p); err != nil {
+			err = ErrBadPatch.Wrap(err)
+			return
+		}
+		result = patch.Merge(doc, p)
+	case mediaTypeJSONPatch:
+		var ops []patch.Operation
+		if err = json.Unmarshal(body, &ops); err != nil {
+			err = ErrBadPatch.Wrap(err)
+			return
+		}
+		result, err = patch.Apply(doc, ops)
+		if errors.Is(err, patch.ErrTestFailed) {
+			err = ErrPatchTest.Wrap(err)
+			return
+		}
+		if err != nil {
+			err = ErrBadPatch.Wrap(err)
+			return
+		}
+	default:
+		err = ErrBadMediaType.Wrap(fmt.Errorf("%s, supported: %s, %s", mediaType, mediaTypeMergePatch, mediaTypeJSONPatch))
+		return
+	}
+	resultDoc, ok := result.(map[string]any)
+	if !ok {
+		err = ErrBadPatch.Wrap(errors.New("the patched vehicle is not an object"))
+		return
+	}
+
+	// - changes: only the mutable fields
+	changed := changedFields(doc, resultDoc)
+	var immutable []ProblemField
+	for _, name := range changed {
+		if !MutableFields[name] {
+			immutable = append(immutable, ProblemField{Pointer: "/" + name, Message: "is not mutable"})
+		}
+	}
+	if len(immutable) > 0 {
+		err = ErrImmutableField.WithFields(immutable)
+		return
+	}
+
+	// - patched vehicle: the changed fields converted to the canonical units, the rest as they were
+	next, err := documentVehicle(resultDoc)
+	if err != nil {
+		err = ErrBadPatch.Wrap(err)
+		return
+	}
+	canonical, err := vehicleDocument(VehicleToVehicleJSON(u.CanonicalVehicle(VehicleJSONToVehicle(next))))
+	if err != nil {
+		return
+	}
+	current, err := vehicleDocument(VehicleToVehicleJSON(v))
+	if err != nil {
+		return
+	}
+	for _, name := range changed {
+		current[name] = canonical[name]
+	}
+	vehicle, err := documentVehicle(current)
+	if err != nil {
+		return
+	}
+	patched = VehicleJSONToVehicle(vehicle)
+	patched.Source = v.Source
+	return
+}
+
+// vehicleDocument is a function that returns a vehicle as a generic JSON object, without its provenance
+func vehicleDocument(v VehicleJSON) (doc map[string]any, err error) {
+	bytes, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	if err = json.Unmarshal(bytes, &doc); err != nil {
+		return
+	}
+	delete(doc, "source")
+	return
+}
+
+// documentVehicle is a function that returns the vehicle of a generic JSON object
+func documentVehicle(doc map[string]any) (v VehicleJSON, err error) {
+	bytes, err := json.Marshal(doc)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(bytes, &v)
+	return
+}
+
+// changedFields is a function that returns the sorted names of the fields added, removed or changed between two objects
+func changedFields(before, after map[string]any) (names []string) {
+	for name, value := range before {
+		if afterValue, ok := after[name]; !ok || !reflect.DeepEqual(value, afterValue) {
+			names = append(names, name)
+		}
+	}
+	for name := range after {
+		if _, ok := before[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return
+}
@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestVehicleDefault_Patch_JSONPatch(t *testing.T) {
+	cases := []struct {
+		name     string
+		body     string
+		status   int
+		pointers []string
+		color    string
+	}{
+		{name: "replace", body: `[{"op": "test", "path": "/color", "value": "red"}, {"op": "replace", "path": "/color", "value": "blue"}]`, status: http.StatusOK, color: "blue"},
+		{name: "failed test", body: `[{"op": "test", "path": "/color", "value": "green"}, {"op": "replace", "path": "/color", "value": "blue"}]`, status: http.StatusConflict, color: "red"},
+		{name: "immutable field", body: `[{"op": "replace", "path": "/brand", "value": "Ford"}]`, status: http.StatusUnprocessableEntity, pointers: []string{"/brand"}, color: "red"},
+		{name: "invalid vehicle", body: `[{"op": "replace", "path": "/passengers", "value": 12}]`, status: http.StatusUnprocessableEntity, pointers: []string{"/passengers"}, color: "red"},
+		{name: "missing path", body: `[{"op": "remove", "path": "/wings"}]`, status: http.StatusBadRequest, color: "red"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			rt, rp := newElectricFleet(t)
+
+			res := serve(rt, http.MethodPatch, "/vehicles/1", mediaTypeJSONPatch, c.body)
+			if res.Code != c.status {
+				t.Fatalf("status = %d, want %d: %s", res.Code, c.status, res.Body)
+			}
+			if c.pointers != nil {
+				checkPointers(t, res, c.pointers)
+			}
+			// - the patch is applied entirely or not at all
+			if v, _ := rp.FindById(1); v.Color != c.color {
+				t.Fatalf("color = %q, want %q", v.Color, c.color)
+			}
+		})
+	}
+}
+
+func TestVehicleDefault_Patch_MergePatch(t *testing.T) {
+	rt, rp := newElectricFleet(t)
+
+	res := serve(rt, http.MethodPatch, "/vehicles/1", mediaTypeMergePatch, `{"color": "blue", "length": null}`)
+	if res.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", res.Code, http.StatusOK, res.Body)
+	}
+	if v, _ := rp.FindById(1); v.Color != "blue" {
+		t.Fatalf("color = %q, want blue", v.Color)
+	}
+
+	res = serve(rt, http.MethodPatch, "/vehicles/1", "text/plain", `color=green`)
+	if res.Code != http.StatusUnsupportedMediaType {
+		t.Fatalf("status = %d, want %d: %s", res.Code, http.StatusUnsupportedMediaType, res.Body)
+	}
+}
@@ -0,0 +1,235 @@
+package handler
+
+import (
+	"app/internal"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// ErrFieldForbidden is an error for a change to fields of a vehicle that the role of the client may not modify
+var ErrFieldForbidden = &APIError{Status: http.StatusForbidden, Code: "field_forbidden"}
+
+// maskedValue is the value of the masked text fields
+const maskedValue = "****"
+
+// FieldRules is a struct that represents the fields of VehicleJSON, by JSON name, that a role may not see or modify
+type FieldRules struct {
+	// Masked are the fields whose values are hidden in the responses, they can not be modified either
+	Masked []string
+	// ReadOnly are the fields that can be set when a vehicle is created but not modified
+	ReadOnly []string
+}
+
+// FieldPolicy is the field rules of each role, a role without rules sees and modifies every field
+type FieldPolicy map[internal.Role]FieldRules
+
+// DefaultFieldPolicy is the field policy of the server: the registration is hidden from the readers,
+// and only the admins modify the max speed
+var DefaultFieldPolicy = FieldPolicy{
+	internal.RoleReader: {Masked: []string{"registration"}, ReadOnly: []string{"max_speed"}},
+	internal.RoleWriter: {ReadOnly: []string{"max_speed"}},
+}
+
+// fieldRulesKey is the type of the key of the field rules in the context
+type fieldRulesKey struct{}
+
+// Permissions is a function that returns a middleware that adds to the context of the request the field rules
+// of the role of its principal. They are enforced by the responder, that masks the vehicles of the responses,
+// and by the service of the handlers, that rejects the changes to the fields the role may not modify
+func Permissions(policy FieldPolicy) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			p, _ := PrincipalFrom(r.Context())
+			fr := policy[p.Role()]
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), fieldRulesKey{}, fr)))
+		})
+	}
+}
+
+// FieldRulesFrom is a function that returns the field rules of the context, none if there are none
+func FieldRulesFrom(ctx context.Context) FieldRules {
+	fr, _ := ctx.Value(fieldRulesKey{}).(FieldRules)
+	return fr
+}
+
+// mask is a method that returns the vehicle with the masked fields hidden:
+// text fields are replaced with asterisks, the rest with their zero value
+func (fr FieldRules) mask(v VehicleJSON) VehicleJSON {
+	if len(fr.Masked) == 0 {
+		return v
+	}
+	rv := reflect.ValueOf(&v).Elem()
+	for i, name := range vehicleFieldNames {
+		if !contains(fr.Masked, name) {
+			continue
+		}
+		field := rv.Field(i)
+		if field.Kind() == reflect.String {
+			field.SetString(maskedValue)
+			continue
+		}
+		field.Set(reflect.Zero(field.Type()))
+	}
+	return v
+}
+
+// check is a method that returns an error if the change of a vehicle modifies fields that may not be modified
+func (fr FieldRules) check(before, after internal.Vehicle) (err error) {
+	if len(fr.Masked) == 0 && len(fr.ReadOnly) == 0 {
+		return
+	}
+	beforeDoc, err := vehicleDocument(VehicleToVehicleJSON(before))
+	if err != nil {
+		return
+	}
+	afterDoc, err := vehicleDocument(VehicleToVehicleJSON(after))
+	if err != nil {
+		return
+	}
+	return fr.forbid(changedFields(beforeDoc, afterDoc)...)
+}
+
+// forbid is a method that returns an error if any of the fields may not be modified
+func (fr FieldRules) forbid(names ...string) (err error) {
+	var fields []ProblemField
+	var forbidden []string
+	for _, name := range names {
+		if contains(fr.Masked, name) || contains(fr.ReadOnly, name) {
+			forbidden = append(forbidden, name)
+			fields = append(fields, ProblemField{Pointer: "/" + name, Message: "the field may not be modified by this role"})
+		}
+	}
+	if len(fields) > 0 {
+		err = ErrFieldForbidden.Wrap(fmt.Errorf("forbidden fields: %s", strings.Join(forbidden, ", "))).WithFields(fields)
+	}
+	return
+}
+
+// contains is a function that returns true if the names contain the name
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// NewVehicleGuarded is a function that returns a new instance of VehicleGuarded
+func NewVehicleGuarded(sv internal.VehicleService, fr FieldRules) *VehicleGuarded {
+	return &VehicleGuarded{VehicleService: sv, fr: fr}
+}
+
+// VehicleGuarded is a struct that implements the VehicleService interface,
+// it rejects the changes of the vehicles that modify fields forbidden by its field rules.
+// New vehicles are not checked, as the read-only fields are set when the vehicles are created
+type VehicleGuarded struct {
+	internal.VehicleService
+	// fr are the field rules of the client
+	fr FieldRules
+}
+
+// Save is a method that creates a vehicle or replaces an existing one.
+// The replaced vehicle is checked within the update of the service, so no change can happen between the check and the save
+func (s *VehicleGuarded) Save(v internal.Vehicle) (created bool, err error) {
+	// - replace the vehicle, checking it against its current value
+	_, err = s.VehicleService.Update(v.Id, func(current internal.Vehicle) (internal.Vehicle, error) {
+		return v, s.fr.check(current, v)
+	})
+	if !errors.Is(err, internal.ErrVehicleNotFound) {
+		return
+	}
+
+	// - create the vehicle, in a transaction as it may have been created meanwhile
+	err = s.VehicleService.Transaction(func(sv internal.VehicleService) (err error) {
+		current, err := sv.FindById(v.Id)
+		switch {
+		case errors.Is(err, internal.ErrVehicleNotFound):
+		case err != nil:
+			return
+		default:
+			if err = s.fr.check(current, v); err != nil {
+				return
+			}
+		}
+		created, err = sv.Save(v)
+		return
+	})
+	return
+}
+
+// Update is a method that replaces a vehicle with the result of a function of the current one
+func (s *VehicleGuarded) Update(id int, update func(v internal.Vehicle) (internal.Vehicle, error)) (v internal.Vehicle, err error) {
+	v, err = s.VehicleService.Update(id, s.guard(update))
+	return
+}
+
+// UpdateByFilter is a method that replaces the vehicles that match the filter with the result of a function of each one
+func (s *VehicleGuarded) UpdateByFilter(f internal.VehicleFilter, update func(v internal.Vehicle) (internal.Vehicle, error), dryRun bool) (ids []int, err error) {
+	ids, err = s.VehicleService.UpdateByFilter(f, s.guard(update), dryRun)
+	return
+}
+
+// UpdateSpeed is a method that updates the max speed of a vehicle
+func (s *VehicleGuarded) UpdateSpeed(id int, speed float64) (err error) {
+	if err = s.fr.forbid("max_speed"); err != nil {
+		return
+	}
+	err = s.VehicleService.UpdateSpeed(id, speed)
+	return
+}
+
+// UpdateFuelType is a method that updates the fuel type of a vehicle
+func (s *VehicleGuarded) UpdateFuelType(id int, fuelType string) (err error) {
+	if err = s.fr.forbid("fuel_type"); err != nil {
+		return
+	}
+	err = s.VehicleService.UpdateFuelType(id, fuelType)
+	return
+}
+
+// Import is a method that creates or replaces multiple vehicles applying the policy on id conflicts.
+// With the overwrite policy, the replaced vehicles are checked in the same transaction that imports them
+func (s *VehicleGuarded) Import(v []internal.Vehicle, policy internal.ImportPolicy, dryRun bool) (outcomes []internal.ImportOutcome, err error) {
+	if policy != internal.ImportOverwrite {
+		outcomes, err = s.VehicleService.Import(v, policy, dryRun)
+		return
+	}
+	err = s.VehicleService.Transaction(func(sv internal.VehicleService) (err error) {
+		for _, vh := range v {
+			current, errFind := sv.FindById(vh.Id)
+			if errFind != nil {
+				continue
+			}
+			if err = s.fr.check(current, vh); err != nil {
+				return fmt.Errorf("vehicle %d: %w", vh.Id, err)
+			}
+		}
+		outcomes, err = sv.Import(v, policy, dryRun)
+		return
+	})
+	return
+}
+
+// Transaction is a method that runs a function with a guarded service whose changes are saved only if it succeeds
+func (s *VehicleGuarded) Transaction(fn func(sv internal.VehicleService) error) (err error) {
+	err = s.VehicleService.Transaction(func(sv internal.VehicleService) error {
+		return fn(NewVehicleGuarded(sv, s.fr))
+	})
+	return
+}
+
+// guard is a method that returns an update function that rejects the changes of forbidden fields
+func (s *VehicleGuarded) guard(update func(v internal.Vehicle) (internal.Vehicle, error)) func(v internal.Vehicle) (internal.Vehicle, error) {
+	return func(current internal.Vehicle) (updated internal.Vehicle, err error) {
+		if updated, err = update(current); err != nil {
+			return
+		}
+		err = s.fr.check(current, updated)
+		return
+	}
+}
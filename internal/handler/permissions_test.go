@@ -0,0 +1,152 @@
+package handler
+
+import (
+	"app/internal"
+	"app/internal/repository"
+	"app/internal/service"
+	"app/internal/validation"
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// newPermissionsRouter is a function that returns a router with the vehicle routes of /vehicles/{id} behind
+// the default field policy, for a principal with the scope, and the repository with the vehicle 1
+func newPermissionsRouter(t *testing.T, scope internal.Scope) (rt *chi.Mux, rp *repository.VehicleMap) {
+	t.Helper()
+	_, rp = newVehicleRouter(t, false)
+	hd := NewVehicleDefault(service.NewVehicleDefault(rp), validation.NewVehicleRules(nil))
+
+	rt = chi.NewRouter()
+	rt.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, withPrincipal(r, scope))
+		})
+	})
+	rt.Use(Permissions(DefaultFieldPolicy))
+	rt.Get("/vehicles/{id}", hd.GetById())
+	rt.Put("/vehicles/{id}", hd.Replace())
+	rt.Patch("/vehicles/{id}", hd.Patch())
+	rt.Patch("/vehicles/{id}/update_speed", hd.UpdateSpeed())
+	return
+}
+
+func TestPermissions_Mask(t *testing.T) {
+	cases := []struct {
+		scope        internal.Scope
+		registration string
+	}{
+		{scope: internal.ScopeRead, registration: maskedValue},
+		{scope: internal.ScopeWrite, registration: "ABC-123"},
+		{scope: internal.ScopeAdmin, registration: "ABC-123"},
+	}
+	for _, c := range cases {
+		t.Run(string(c.scope), func(t *testing.T) {
+			rt, _ := newPermissionsRouter(t, c.scope)
+
+			res := httptest.NewRecorder()
+			rt.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/vehicles/1", nil))
+			var body struct {
+				Data VehicleJSON `json:"data"`
+			}
+			if err := json.Unmarshal(res.Body.Bytes(), &body); err != nil {
+				t.Fatalf("body: %v", err)
+			}
+			if body.Data.Registration != c.registration || body.Data.Brand != "Toyota" {
+				t.Fatalf("vehicle = %+v, want the registration %q", body.Data, c.registration)
+			}
+		})
+	}
+}
+
+func TestPermissions_ReadOnly(t *testing.T) {
+	cases := []struct {
+		name   string
+		scope  internal.Scope
+		method string
+		target string
+		body   string
+		status int
+		speed  float64
+	}{
+		{name: "writer patches color", scope: internal.ScopeWrite, method: http.MethodPatch, target: "/vehicles/1", body: `{"color": "blue"}`, status: http.StatusOK, speed: 180},
+		{name: "writer patches speed", scope: internal.ScopeWrite, method: http.MethodPatch, target: "/vehicles/1", body: `{"max_speed": 200}`, status: http.StatusForbidden, speed: 180},
+		{name: "writer updates speed", scope: internal.ScopeWrite, method: http.MethodPatch, target: "/vehicles/1/update_speed", body: `{"max_speed": 200}`, status: http.StatusForbidden, speed: 180},
+		{name: "writer replaces speed", scope: internal.ScopeWrite, method: http.MethodPut, target: "/vehicles/1", body: `{` + strings.Replace(prius, `"max_speed": 180`, `"max_speed": 200`, 1) + `}`, status: http.StatusForbidden, speed: 180},
+		{name: "admin patches speed", scope: internal.ScopeAdmin, method: http.MethodPatch, target: "/vehicles/1", body: `{"max_speed": 200}`, status: http.StatusOK, speed: 200 / 1.609344},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			rt, rp := newPermissionsRouter(t, c.scope)
+
+			req := httptest.NewRequest(c.method, c.target, strings.NewReader(c.body))
+			req.Header.Set("Content-Type", "application/json")
+			res := httptest.NewRecorder()
+			rt.ServeHTTP(res, req)
+			if res.Code != c.status {
+				t.Fatalf("status = %d, want %d: %s", res.Code, c.status, res.Body)
+			}
+			if v, _ := rp.FindById(1); math.Abs(v.MaxSpeed-c.speed) > 1e-9 {
+				t.Fatalf("max speed = %v, want %v", v.MaxSpeed, c.speed)
+			}
+		})
+	}
+}
+
+func TestVehicleGuarded_Import(t *testing.T) {
+	_, rp := newVehicleRouter(t, false)
+	sv := NewVehicleGuarded(service.NewVehicleDefault(rp), DefaultFieldPolicy[internal.RoleWriter])
+
+	// - overwriting the max speed is rejected, skipping is not
+	v, _ := rp.FindById(1)
+	v.MaxSpeed = 250
+	if _, err := sv.Import([]internal.Vehicle{v}, internal.ImportOverwrite, false); !errors.Is(err, ErrFieldForbidden) {
+		t.Fatalf("overwrite: err = %v, want ErrFieldForbidden", err)
+	}
+	if _, err := sv.Import([]internal.Vehicle{v}, internal.ImportSkip, false); err != nil {
+		t.Fatalf("skip: err = %v, want nil", err)
+	}
+	if current, _ := rp.FindById(1); current.MaxSpeed != 180 {
+		t.Fatalf("max speed = %v, want 180", current.MaxSpeed)
+	}
+}
+
+// staleFinder is a struct that returns the vehicle to save as the current one, like a read made before a concurrent change
+type staleFinder struct {
+	internal.VehicleService
+	// v is the vehicle returned by FindById
+	v internal.Vehicle
+}
+
+// FindById is a method that returns the stale vehicle
+func (s staleFinder) FindById(id int) (v internal.Vehicle, err error) {
+	v = s.v
+	return
+}
+
+func TestVehicleGuarded_Save(t *testing.T) {
+	_, rp := newVehicleRouter(t, false)
+	v, _ := rp.FindById(1)
+	v.MaxSpeed = 250
+	sv := NewVehicleGuarded(staleFinder{VehicleService: service.NewVehicleDefault(rp), v: v}, DefaultFieldPolicy[internal.RoleWriter])
+
+	// - the check is made against the vehicle of the repository, not a previous read
+	if _, err := sv.Save(v); !errors.Is(err, ErrFieldForbidden) {
+		t.Fatalf("replace: err = %v, want ErrFieldForbidden", err)
+	}
+	if current, _ := rp.FindById(1); current.MaxSpeed != 180 {
+		t.Fatalf("max speed = %v, want 180", current.MaxSpeed)
+	}
+
+	// - new vehicles are not checked
+	v.Id = 99
+	if created, err := sv.Save(v); err != nil || !created {
+		t.Fatalf("create: created = %v, err = %v, want true and nil", created, err)
+	}
+}
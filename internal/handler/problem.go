@@ -0,0 +1,223 @@
+package handler
+
+import (
+	"app/internal"
+	"app/internal/i18n"
+	"app/internal/validation"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+)
+
+// Problem is a struct that represents an error response as RFC 7807 problem details
+type Problem struct {
+	// Type is the URI reference that identifies the kind of problem
+	Type string `json:"type"`
+	// Title is the summary of the kind of problem
+	Title string `json:"title"`
+	// Status is the HTTP status code
+	Status int `json:"status"`
+	// Code is the stable, machine-readable code of the kind of problem
+	Code string `json:"code"`
+	// Detail is the explanation of this occurrence of the problem
+	Detail string `json:"detail,omitempty"`
+	// Instance is the path of the request that had the problem
+	Instance string `json:"instance,omitempty"`
+	// Errors are the field-level details of the problem
+	Errors []ProblemField `json:"errors,omitempty"`
+}
+
+// ProblemField is a struct that represents a problem with a field of the request
+type ProblemField struct {
+	// Pointer is the JSON pointer to the field in the body of the request
+	Pointer string `json:"pointer"`
+	// Message is the description of the problem with the field
+	Message string `json:"message"`
+}
+
+// APIError is an error that is answered with a problem of a given status and code,
+// the title of the problem is the message of the code in the language of the client
+type APIError struct {
+	// Status is the HTTP status code of the problem
+	Status int
+	// Code is the stable, machine-readable code of the problem
+	Code string
+	// Err is the cause of the error, it is logged on the server and not shown to the client
+	Err error
+	// Fields are the field-level details of the problem
+	Fields []ProblemField
+}
+
+// Error is a method that returns the code and the cause of the error
+func (e *APIError) Error() string {
+	if e.Err == nil {
+		return e.Code
+	}
+	return e.Code + ": " + e.Err.Error()
+}
+
+// Unwrap is a method that returns the cause of the error
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+// Is is a method that returns true if the target is an APIError with the same code
+func (e *APIError) Is(target error) bool {
+	t, ok := target.(*APIError)
+	return ok && t.Code == e.Code
+}
+
+// Wrap is a method that returns a copy of the error with a cause
+func (e *APIError) Wrap(err error) *APIError {
+	wrapped := *e
+	wrapped.Err = err
+	return &wrapped
+}
+
+// WithFields is a method that returns a copy of the error with field-level details
+func (e *APIError) WithFields(fields []ProblemField) *APIError {
+	wrapped := *e
+	wrapped.Fields = fields
+	return &wrapped
+}
+
+var (
+	// ErrBadRequest is an error that represents a bad request
+	ErrBadRequest = &APIError{Status: http.StatusBadRequest, Code: "bad_request"}
+	// ErrDuplicatedId is an error that represents a duplicated id
+	ErrDuplicatedId = &APIError{Status: http.StatusConflict, Code: "vehicle_conflict"}
+	//ErrBadCriteria is an error for criteria that match no vehicles
+	ErrBadCriteria = &APIError{Status: http.StatusNotFound, Code: "no_vehicles_found"}
+	//ErrBadFilter is an error for malformed criteria
+	ErrBadFilter = &APIError{Status: http.StatusBadRequest, Code: "invalid_filter"}
+	//ErrBadSpeed is an error for bad speed
+	ErrBadSpeed = &APIError{Status: http.StatusBadRequest, Code: "invalid_speed"}
+	//ErrBadFuelType is an error for bad fuel type
+	ErrBadFuelType = &APIError{Status: http.StatusBadRequest, Code: "invalid_fuel_type"}
+	//ErrNotFound is an error for not found
+	ErrNotFound = &APIError{Status: http.StatusNotFound, Code: "vehicle_not_found"}
+	//ErrValidation is an error for vehicles that break the validation rules
+	ErrValidation = &APIError{Status: http.StatusUnprocessableEntity, Code: "validation_failed"}
+	//ErrBadFormat is an error for an unsupported format
+	ErrBadFormat = &APIError{Status: http.StatusBadRequest, Code: "unsupported_format"}
+	//ErrBadPolicy is an error for an unsupported conflict policy
+	ErrBadPolicy = &APIError{Status: http.StatusBadRequest, Code: "unsupported_conflict_policy"}
+	//ErrNotAcceptable is an error for an unsupported response format
+	ErrNotAcceptable = &APIError{Status: http.StatusNotAcceptable, Code: "not_acceptable"}
+	//ErrInternal is an error for unexpected failures
+	ErrInternal = &APIError{Status: http.StatusInternalServerError, Code: "internal_error"}
+	//ErrJobNotFound is an error for a job that does not exist
+	ErrJobNotFound = &APIError{Status: http.StatusNotFound, Code: "job_not_found"}
+	//ErrJobFinished is an error for a job that cannot be canceled because it already finished
+	ErrJobFinished = &APIError{Status: http.StatusConflict, Code: "job_finished"}
+	//ErrJobQueueFull is an error for a job that is not accepted because the queue is full
+	ErrJobQueueFull = &APIError{Status: http.StatusServiceUnavailable, Code: "job_queue_full"}
+)
+
+// repositoryErrors are the API errors of the errors of the domain
+var repositoryErrors = []struct {
+	// err is the error of the repository
+	err error
+	// api is the error it is answered with
+	api *APIError
+}{
+	{internal.ErrVehicleNotFound, ErrNotFound},
+	{internal.ErrVehicleAlreadyExists, ErrDuplicatedId},
+	{internal.ErrNoVehiclesFound, ErrBadCriteria},
+	{internal.ErrJobNotFound, ErrJobNotFound},
+	{internal.ErrJobFinished, ErrJobFinished},
+	{internal.ErrJobQueueFull, ErrJobQueueFull},
+	{internal.ErrUnauthenticated, ErrUnauthenticated},
+	{internal.ErrTenantNotFound, ErrTenantNotFound},
+	{internal.ErrTenantForbidden, ErrTenantForbidden},
+	{internal.ErrQuotaExceeded, ErrQuotaExceeded},
+	{internal.ErrFleetNotFound, ErrFleetNotFound},
+	{internal.ErrFleetAlreadyExists, ErrFleetConflict},
+	{internal.ErrVehicleAssigned, ErrVehicleAssigned},
+	{internal.ErrVehicleNotInFleet, ErrVehicleNotInFleet},
+}
+
+// ToAPIError is a function that returns the API error an error is answered with
+func ToAPIError(err error) (apiErr *APIError) {
+	// - already an API error
+	if errors.As(err, &apiErr) {
+		if len(apiErr.Fields) == 0 {
+			apiErr = apiErr.WithFields(ValidationFields("", err))
+		}
+		return
+	}
+
+	// - repository errors
+	for _, re := range repositoryErrors {
+		if errors.Is(err, re.err) {
+			apiErr = re.api.Wrap(err)
+			return
+		}
+	}
+
+	// - validation errors
+	if fields := ValidationFields("", err); len(fields) > 0 {
+		apiErr = ErrValidation.Wrap(err).WithFields(fields)
+		return
+	}
+
+	// - anything else, the cause is not shown to the client
+	apiErr = ErrInternal
+	return
+}
+
+// ValidationFields is a function that returns the field-level details of the violations of the
+// validation rules in err, with their pointers relative to prefix
+func ValidationFields(prefix string, err error) (fields []ProblemField) {
+	if errs, ok := validation.AsErrors(err); ok {
+		for _, e := range errs {
+			fields = append(fields, ProblemField{Pointer: prefix + "/" + e.Field, Message: e.Msg})
+		}
+	}
+	return
+}
+
+// Problem is a method that returns the problem details of an error, with the status and code it is mapped to
+// and its messages in the language of the client. The detail is a public, localized message: the causes
+// that are not shown to the client are logged on the server
+func (rs *Responder) Problem(r *http.Request, err error) (problem Problem) {
+	apiErr := ToAPIError(err)
+	p := i18n.FromContext(r.Context())
+
+	problem = Problem{
+		Type:     "/problems/" + apiErr.Code,
+		Title:    p.Sprintf(apiErr.Code),
+		Status:   apiErr.Status,
+		Code:     apiErr.Code,
+		Instance: r.URL.Path,
+		Errors:   apiErr.Fields,
+	}
+	var repoErr *internal.RepositoryError
+	switch {
+	case errors.As(apiErr.Err, &repoErr):
+		// repository errors are localized with their criteria
+		problem.Detail = p.Sprintf(apiErr.Code+".detail", repoErr.CriteriaString())
+	case apiErr.Err != nil && len(apiErr.Fields) == 0, apiErr == ErrInternal:
+		// the cause may have internal details, the violations of the validation rules are already in the errors
+		log.Printf("problem: %s %s: %v", r.Method, r.URL.Path, err)
+	}
+	return
+}
+
+// Error is a method that writes the error as application/problem+json, with the status and code it is mapped to
+func (rs *Responder) Error(w http.ResponseWriter, r *http.Request, err error) {
+	rs.WriteProblem(w, rs.Problem(r, err))
+}
+
+// WriteProblem is a method that writes problem details as application/problem+json
+func (rs *Responder) WriteProblem(w http.ResponseWriter, problem Problem) {
+	bytes, errMarshal := json.Marshal(problem)
+	if errMarshal != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(problem.Status)
+	w.Write(bytes)
+}
@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"app/internal"
+	"app/internal/i18n"
+	"app/internal/tools"
+	"app/internal/validation"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestToAPIError(t *testing.T) {
+	cases := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+		fields int
+	}{
+		{name: "api error", err: ErrBadSpeed, status: http.StatusBadRequest, code: "invalid_speed"},
+		{name: "not found", err: fmt.Errorf("find: %w", internal.ErrVehicleNotFound), status: http.StatusNotFound, code: "vehicle_not_found"},
+		{name: "already exists", err: internal.ErrVehicleAlreadyExists, status: http.StatusConflict, code: "vehicle_conflict"},
+		{name: "validation", err: validation.Errors{{Field: "year", Msg: "is required"}, {Field: "color", Msg: "is required"}},
+			status: http.StatusUnprocessableEntity, code: "validation_failed", fields: 2},
+		{name: "unexpected", err: errors.New("disk on fire"), status: http.StatusInternalServerError, code: "internal_error"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			apiErr := ToAPIError(c.err)
+			if apiErr.Status != c.status || apiErr.Code != c.code || len(apiErr.Fields) != c.fields {
+				t.Fatalf("error = %+v, want %d %s with %d fields", apiErr, c.status, c.code, c.fields)
+			}
+		})
+	}
+
+	// - wrapped API errors keep their code
+	if !errors.Is(ErrBadRequest.Wrap(errors.New("eof")), ErrBadRequest) {
+		t.Fatalf("wrapped error is not ErrBadRequest")
+	}
+}
+
+func TestResponder_Error(t *testing.T) {
+	rs := NewResponder()
+	err := ErrValidation.Wrap(validation.Errors{&tools.FieldError{Field: "max_speed", Msg: "must be greater than 0"}})
+
+	res := httptest.NewRecorder()
+	rs.Error(res, httptest.NewRequest(http.MethodPost, "/vehicles", nil), err)
+	if res.Code != http.StatusUnprocessableEntity || res.Header().Get("Content-Type") != "application/problem+json" {
+		t.Fatalf("status = %d, content type = %q, want %d and application/problem+json", res.Code, res.Header().Get("Content-Type"), http.StatusUnprocessableEntity)
+	}
+	var problem Problem
+	if err := json.Unmarshal(res.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("body: %v", err)
+	}
+	if problem.Type != "/problems/validation_failed" || problem.Instance != "/vehicles" ||
+		len(problem.Errors) != 1 || problem.Errors[0].Pointer != "/max_speed" {
+		t.Fatalf("problem = %+v, want the violation of /max_speed", problem)
+	}
+
+	// - the causes are logged on the server instead of shown to the client
+	var logged bytes.Buffer
+	log.SetOutput(&logged)
+	defer log.SetOutput(os.Stderr)
+	causes := []struct {
+		err    error
+		status int
+	}{
+		{err: errors.New("disk on fire"), status: http.StatusInternalServerError},
+		{err: ErrBadRequest.Wrap(errors.New("disk on fire")), status: http.StatusBadRequest},
+	}
+	for _, c := range causes {
+		logged.Reset()
+		problem = Problem{}
+		res = httptest.NewRecorder()
+		rs.Error(res, httptest.NewRequest(http.MethodGet, "/vehicles", nil), c.err)
+		if res.Code != c.status || json.Unmarshal(res.Body.Bytes(), &problem) != nil || problem.Detail != "" {
+			t.Fatalf("status = %d, body = %s, want %d without detail", res.Code, res.Body, c.status)
+		}
+		if !strings.Contains(logged.String(), "GET /vehicles") || !strings.Contains(logged.String(), "disk on fire") {
+			t.Fatalf("log = %q, want the request and the cause", logged.String())
+		}
+	}
+}
+
+func TestResponder_Error_Localized(t *testing.T) {
+	rs := NewResponder()
+	err := internal.NewRepositoryError(internal.ErrVehicleNotFound, "id", 7)
+
+	req := httptest.NewRequest(http.MethodGet, "/vehicles/7", nil)
+	req = req.WithContext(i18n.NewContext(req.Context(), i18n.NewLocalizer(nil, "").Printer("en")))
+	res := httptest.NewRecorder()
+	rs.Error(res, req, err)
+
+	var problem Problem
+	if err := json.Unmarshal(res.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("body: %v", err)
+	}
+	if res.Code != http.StatusNotFound || problem.Title != "Vehicle not found." || problem.Detail != "there is no vehicle with id=7" {
+		t.Fatalf("status = %d, problem = %+v, want the English messages", res.Code, problem)
+	}
+}
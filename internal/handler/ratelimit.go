@@ -0,0 +1,191 @@
+package handler
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrRateLimited is an error for a client that exceeded its rate of requests
+	ErrRateLimited = &APIError{Status: http.StatusTooManyRequests, Code: "rate_limited"}
+	// ErrTooManyConcurrent is an error for a client that exceeded its concurrent requests
+	ErrTooManyConcurrent = &APIError{Status: http.StatusTooManyRequests, Code: "concurrency_limited"}
+)
+
+// idleClient is the time after which the state of a client without requests is discarded
+const idleClient = 10 * time.Minute
+
+// RateLimit is a struct that represents a rate of requests: a token bucket refilled at Rate tokens per second
+// up to Burst tokens, every request takes one. A Rate of 0 means no limit
+type RateLimit struct {
+	// Rate is the amount of requests per second
+	Rate float64
+	// Burst is the amount of requests that can be made at once
+	Burst int
+}
+
+// ConfigRateLimiter is a struct that represents the configuration for RateLimiter
+type ConfigRateLimiter struct {
+	// Reads is the rate of the requests that read, GET, HEAD and OPTIONS
+	Reads RateLimit
+	// Writes is the rate of the rest of requests
+	Writes RateLimit
+	// MaxConcurrent is the amount of requests of a client processed at the same time, 0 means no limit
+	MaxConcurrent int
+}
+
+// NewRateLimiter is a function that returns a new instance of RateLimiter
+func NewRateLimiter(cfg ConfigRateLimiter) *RateLimiter {
+	// a rate without burst allows the requests of one second at once
+	for _, l := range []*RateLimit{&cfg.Reads, &cfg.Writes} {
+		if l.Rate > 0 && l.Burst < 1 {
+			l.Burst = int(math.Ceil(l.Rate))
+		}
+	}
+	return &RateLimiter{cfg: cfg, clients: make(map[string]*rateClient), now: time.Now}
+}
+
+// RateLimiter is a struct that limits the requests of each client: by its API key, or by its IP if it has none
+type RateLimiter struct {
+	// cfg are the limits of every client
+	cfg ConfigRateLimiter
+	// mu is the mutex that protects the clients
+	mu sync.Mutex
+	// clients are the states of the clients, by key
+	clients map[string]*rateClient
+	// swept is the last time the idle clients were discarded
+	swept time.Time
+	// now returns the current time
+	now func() time.Time
+}
+
+// rateClient is a struct that represents the state of the limits of a client
+type rateClient struct {
+	// reads are the tokens of the requests that read
+	reads bucket
+	// writes are the tokens of the rest of requests
+	writes bucket
+	// active is the amount of requests being processed
+	active int
+	// seen is the time of the last request
+	seen time.Time
+}
+
+// bucket is a struct that represents a token bucket
+type bucket struct {
+	// tokens are the tokens left
+	tokens float64
+	// last is the time the tokens were refilled
+	last time.Time
+}
+
+// take is a method that refills the bucket and takes a token, or returns the time until there is one
+func (b *bucket) take(l RateLimit, now time.Time) (wait time.Duration, ok bool) {
+	if b.last.IsZero() {
+		b.tokens = float64(l.Burst)
+	} else {
+		b.tokens = math.Min(float64(l.Burst), b.tokens+now.Sub(b.last).Seconds()*l.Rate)
+	}
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0, true
+	}
+	return time.Duration((1 - b.tokens) / l.Rate * float64(time.Second)), false
+}
+
+// Middleware is a method that returns a middleware that rejects with 429 Too Many Requests, and a Retry-After header,
+// the requests of a client that exceed its rate or its concurrent requests
+func (rl *RateLimiter) Middleware(next http.Handler) http.Handler {
+	rs := NewResponder()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := rateKey(r)
+		read := r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodOptions
+
+		if wait, err := rl.acquire(key, read); err != nil {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			rs.Error(w, r, err)
+			return
+		}
+		defer rl.release(key)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// acquire is a method that takes a token of the client and counts the request as active,
+// or returns the time until the client can retry
+func (rl *RateLimiter) acquire(key string, read bool) (wait time.Duration, err error) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	now := rl.now()
+	rl.sweep(now)
+	c, ok := rl.clients[key]
+	if !ok {
+		c = &rateClient{}
+		rl.clients[key] = c
+	}
+	c.seen = now
+
+	// - concurrent requests
+	if rl.cfg.MaxConcurrent > 0 && c.active >= rl.cfg.MaxConcurrent {
+		wait = time.Second
+		err = ErrTooManyConcurrent.Wrap(fmt.Errorf("at most %d concurrent requests", rl.cfg.MaxConcurrent))
+		return
+	}
+
+	// - rate
+	limit, b := rl.cfg.Writes, &c.writes
+	if read {
+		limit, b = rl.cfg.Reads, &c.reads
+	}
+	if limit.Rate > 0 {
+		var ok bool
+		if wait, ok = b.take(limit, now); !ok {
+			err = ErrRateLimited.Wrap(fmt.Errorf("at most %g requests per second, with bursts of %d", limit.Rate, limit.Burst))
+			return
+		}
+	}
+	c.active++
+	return
+}
+
+// release is a method that counts a request of the client as finished
+func (rl *RateLimiter) release(key string) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	if c, ok := rl.clients[key]; ok {
+		c.active--
+	}
+}
+
+// sweep is a method that discards the idle clients, at most once per idle period. The mutex must be locked
+func (rl *RateLimiter) sweep(now time.Time) {
+	if now.Sub(rl.swept) < idleClient {
+		return
+	}
+	rl.swept = now
+	for key, c := range rl.clients {
+		if c.active == 0 && now.Sub(c.seen) >= idleClient {
+			delete(rl.clients, key)
+		}
+	}
+}
+
+// rateKey is a function that returns the key of the client of a request: its API key or signing key, or else its IP
+func rateKey(r *http.Request) string {
+	if p, ok := PrincipalFrom(r.Context()); ok && p.KeyID != "" {
+		return "key:" + p.KeyID + ":" + p.Subject
+	}
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+	return "ip:" + ip
+}
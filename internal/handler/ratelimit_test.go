@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// clock is a struct that represents a time that only changes when it is advanced
+type clock struct {
+	// mu is the mutex that protects the time
+	mu sync.Mutex
+	// t is the current time
+	t time.Time
+}
+
+// now is a method that returns the current time
+func (c *clock) now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.t
+}
+
+// advance is a method that moves the time forward
+func (c *clock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.t = c.t.Add(d)
+}
+
+// newLimited is a function that returns a handler limited by a rate limiter whose time is the clock
+func newLimited(cfg ConfigRateLimiter, next http.Handler) (h http.Handler, c *clock) {
+	rl := NewRateLimiter(cfg)
+	c = &clock{t: time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)}
+	rl.now = c.now
+	h = rl.Middleware(next)
+	return
+}
+
+// request is a function that serves a request of a client by its IP
+func request(h http.Handler, method, ip string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/vehicles", nil)
+	req.RemoteAddr = ip + ":1234"
+	res := httptest.NewRecorder()
+	h.ServeHTTP(res, req)
+	return res
+}
+
+// okHandler is a handler that answers every request with 200
+var okHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+func TestRateLimiter_Rate(t *testing.T) {
+	h, c := newLimited(ConfigRateLimiter{Reads: RateLimit{Rate: 2, Burst: 3}, Writes: RateLimit{Rate: 1}}, okHandler)
+
+	// - the burst is allowed at once, then the client waits for a token
+	for i := 0; i < 3; i++ {
+		if res := request(h, http.MethodGet, "10.0.0.1"); res.Code != http.StatusOK {
+			t.Fatalf("read %d: status = %d, want %d", i, res.Code, http.StatusOK)
+		}
+	}
+	res := request(h, http.MethodGet, "10.0.0.1")
+	if res.Code != http.StatusTooManyRequests || res.Header().Get("Retry-After") != "1" {
+		t.Fatalf("read beyond the burst: status = %d, Retry-After = %q, want %d and 1", res.Code, res.Header().Get("Retry-After"), http.StatusTooManyRequests)
+	}
+
+	// - the writes and the other clients have their own tokens
+	if res := request(h, http.MethodPost, "10.0.0.1"); res.Code != http.StatusOK {
+		t.Fatalf("write: status = %d, want %d", res.Code, http.StatusOK)
+	}
+	if res := request(h, http.MethodPost, "10.0.0.1"); res.Code != http.StatusTooManyRequests {
+		t.Fatalf("write beyond the default burst of 1: status = %d, want %d", res.Code, http.StatusTooManyRequests)
+	}
+	if res := request(h, http.MethodGet, "10.0.0.2"); res.Code != http.StatusOK {
+		t.Fatalf("read of another client: status = %d, want %d", res.Code, http.StatusOK)
+	}
+
+	// - the tokens are refilled at the rate
+	c.advance(500 * time.Millisecond)
+	if res := request(h, http.MethodGet, "10.0.0.1"); res.Code != http.StatusOK {
+		t.Fatalf("read after the refill: status = %d, want %d", res.Code, http.StatusOK)
+	}
+	if res := request(h, http.MethodGet, "10.0.0.1"); res.Code != http.StatusTooManyRequests {
+		t.Fatalf("read after the refilled token: status = %d, want %d", res.Code, http.StatusTooManyRequests)
+	}
+}
+
+func TestRateLimiter_Unlimited(t *testing.T) {
+	h, _ := newLimited(ConfigRateLimiter{}, okHandler)
+
+	for i := 0; i < 100; i++ {
+		if res := request(h, http.MethodPost, "10.0.0.1"); res.Code != http.StatusOK {
+			t.Fatalf("write %d: status = %d, want %d", i, res.Code, http.StatusOK)
+		}
+	}
+}
+
+func TestRateLimiter_Concurrent(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	h, _ := newLimited(ConfigRateLimiter{MaxConcurrent: 1}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			close(started)
+			<-release
+		}
+	}))
+
+	done := make(chan struct{})
+	go func() {
+		request(h, http.MethodPost, "10.0.0.1")
+		close(done)
+	}()
+	<-started
+
+	// - a second request of the client is rejected while the first one is processed, the ones of other clients are not
+	res := request(h, http.MethodGet, "10.0.0.1")
+	if res.Code != http.StatusTooManyRequests || problemOf(t, res).Code != "concurrency_limited" {
+		t.Fatalf("concurrent: status = %d, want %d concurrency_limited: %s", res.Code, http.StatusTooManyRequests, res.Body)
+	}
+	if res := request(h, http.MethodGet, "10.0.0.2"); res.Code != http.StatusOK {
+		t.Fatalf("another client: status = %d, want %d", res.Code, http.StatusOK)
+	}
+	close(release)
+	<-done
+	if res := request(h, http.MethodGet, "10.0.0.1"); res.Code != http.StatusOK {
+		t.Fatalf("after the first one: status = %d, want %d", res.Code, http.StatusOK)
+	}
+}
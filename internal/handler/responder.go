@@ -0,0 +1,272 @@
+package handler
+
+import (
+	"app/internal/i18n"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bootcamp-go/web/response"
+)
+
+// NewResponder is a function that returns a new instance of Responder
+func NewResponder() *Responder {
+	return &Responder{
+		formats: []string{"json", "csv", "xml", "ndjson"},
+	}
+}
+
+// Responder is a struct that writes responses in the format negotiated with the Accept header of the request
+type Responder struct {
+	// formats are the supported formats, in order of preference when the client accepts several of them equally
+	formats []string
+}
+
+// Negotiate is a method that returns the format preferred by the Accept header of the request, false if none is supported
+func (rs *Responder) Negotiate(r *http.Request) (format string, ok bool) {
+	accept := r.Header.Get("Accept")
+	if accept == "" {
+		return rs.formats[0], true
+	}
+
+	bestQ := 0.0
+	for _, item := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(item))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if value, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(value, 64); err != nil {
+				continue
+			}
+		}
+		if q <= bestQ {
+			continue
+		}
+		for _, f := range rs.formats {
+			if matchMediaType(mediaType, encodings[f]) {
+				format, bestQ = f, q
+				break
+			}
+		}
+	}
+	ok = format != ""
+	return
+}
+
+// matchMediaType is a function that returns true if the accepted media type, which can have wildcards, matches the content type
+func matchMediaType(accepted, contentType string) bool {
+	if accepted == "*/*" || accepted == contentType {
+		return true
+	}
+	group, sub, _ := strings.Cut(accepted, "/")
+	return sub == "*" && strings.HasPrefix(contentType, group+"/")
+}
+
+// Respond is a method that writes the body in the negotiated format, or 406 Not Acceptable if there is none.
+// The body is rendered through its JSON representation, so it keeps the JSON field names in every format
+func (rs *Responder) Respond(w http.ResponseWriter, r *http.Request, code int, body any) {
+	w.Header().Add("Vary", "Accept")
+	format, ok := rs.Negotiate(r)
+	if !ok {
+		rs.notAcceptable(w, r)
+		return
+	}
+	if format == "json" || body == nil {
+		response.JSON(w, code, body)
+		return
+	}
+
+	// generic value, as it would be seen in JSON
+	bytes, err := json.Marshal(body)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	var value any
+	json.Unmarshal(bytes, &value)
+
+	rs.header(w, format, code)
+	switch format {
+	case "ndjson":
+		w.Write(append(bytes, '\n'))
+	case "xml":
+		enc := xml.NewEncoder(w)
+		enc.EncodeToken(xml.ProcInst{Target: "xml", Inst: []byte(`version="1.0" encoding="UTF-8"`)})
+		encodeXMLValue(enc, "response", value)
+		enc.Flush()
+	case "csv":
+		// one column per top level field, nested values are written as JSON
+		fields, ok := value.(map[string]any)
+		if !ok {
+			fields = map[string]any{"value": value}
+		}
+		names := make([]string, 0, len(fields))
+		for name := range fields {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		values := make([]string, 0, len(names))
+		for _, name := range names {
+			values = append(values, formatGeneric(fields[name]))
+		}
+		cw := csv.NewWriter(w)
+		cw.Write(names)
+		cw.Write(values)
+		cw.Flush()
+	}
+}
+
+// Vehicles is a method that writes the vehicles in the negotiated format, or 406 Not Acceptable if there is none.
+// In JSON they are wrapped with a message as the rest of responses, in the other formats they are written as a table.
+// They are presented to the client: in the unit system of the request, with the fields hidden from its role masked
+func (rs *Responder) Vehicles(w http.ResponseWriter, r *http.Request, code int, data map[int]VehicleJSON) {
+	w.Header().Add("Vary", "Accept")
+	format, ok := rs.Negotiate(r)
+	if !ok {
+		rs.notAcceptable(w, r)
+		return
+	}
+	// as presented to the client
+	u := UnitsFrom(r.Context())
+	presented := make(map[int]VehicleJSON, len(data))
+	for id, v := range data {
+		presented[id] = rs.present(r, v)
+	}
+	data = presented
+
+	if format == "json" {
+		response.JSON(w, code, map[string]any{
+			"message": rs.Message(r, "success"),
+			"units":   UnitsOf(u),
+			"data":    data,
+		})
+		return
+	}
+
+	ids := make([]int, 0, len(data))
+	for id := range data {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	rs.header(w, format, code)
+	enc, _ := NewVehicleEncoder(format, w)
+	for _, id := range ids {
+		if err := enc.Encode(data[id]); err != nil {
+			return
+		}
+	}
+	enc.Close()
+}
+
+// Vehicle is a method that writes a vehicle in the negotiated format, or 406 Not Acceptable if there is none.
+// As with Vehicles, in JSON it is wrapped with a message and it is presented to the client
+func (rs *Responder) Vehicle(w http.ResponseWriter, r *http.Request, code int, v VehicleJSON) {
+	w.Header().Add("Vary", "Accept")
+	format, ok := rs.Negotiate(r)
+	if !ok {
+		rs.notAcceptable(w, r)
+		return
+	}
+	u := UnitsFrom(r.Context())
+	v = rs.present(r, v)
+
+	if format == "json" {
+		response.JSON(w, code, map[string]any{
+			"message": rs.Message(r, "success"),
+			"units":   UnitsOf(u),
+			"data":    v,
+		})
+		return
+	}
+
+	rs.header(w, format, code)
+	enc, _ := NewVehicleEncoder(format, w)
+	if err := enc.Encode(v); err != nil {
+		return
+	}
+	enc.Close()
+}
+
+// present is a method that returns a vehicle as it is shown to the client: its measures in the unit system of the request,
+// and the fields hidden from its role masked
+func (rs *Responder) present(r *http.Request, v VehicleJSON) VehicleJSON {
+	return FieldRulesFrom(r.Context()).mask(v.inUnits(UnitsFrom(r.Context())))
+}
+
+// Message is a method that returns the message of a key in the language of the client
+func (rs *Responder) Message(r *http.Request, key string, args ...any) string {
+	return i18n.FromContext(r.Context()).Sprintf(key, args...)
+}
+
+// header is a method that writes the content type of the format and the status code
+func (rs *Responder) header(w http.ResponseWriter, format string, code int) {
+	w.Header().Set("Content-Type", encodings[format])
+	w.WriteHeader(code)
+}
+
+// notAcceptable is a method that writes a 406 Not Acceptable response listing the supported content types
+func (rs *Responder) notAcceptable(w http.ResponseWriter, r *http.Request) {
+	supported := make([]string, 0, len(rs.formats))
+	for _, f := range rs.formats {
+		supported = append(supported, encodings[f])
+	}
+	rs.Error(w, r, ErrNotAcceptable.Wrap(fmt.Errorf("supported: %s", strings.Join(supported, ", "))))
+}
+
+// encodeXMLValue is a function that encodes a generic JSON value as an XML element:
+// objects as child elements sorted by name, arrays as repeated <item> elements
+func encodeXMLValue(enc *xml.Encoder, name string, value any) {
+	start := xml.StartElement{Name: xml.Name{Local: xmlName(name)}}
+	enc.EncodeToken(start)
+	switch val := value.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(val))
+		for key := range val {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			encodeXMLValue(enc, key, val[key])
+		}
+	case []any:
+		for _, item := range val {
+			encodeXMLValue(enc, "item", item)
+		}
+	default:
+		enc.EncodeToken(xml.CharData(formatGeneric(val)))
+	}
+	enc.EncodeToken(start.End())
+}
+
+// xmlName is a function that returns a valid XML element name for a key, keys such as ids are prefixed
+func xmlName(key string) string {
+	if key == "" || !(key[0] == '_' || (key[0] >= 'a' && key[0] <= 'z') || (key[0] >= 'A' && key[0] <= 'Z')) {
+		return "_" + key
+	}
+	return key
+}
+
+// formatGeneric is a function that formats a generic JSON value as text, nested values as JSON
+func formatGeneric(value any) string {
+	switch val := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(val)
+	}
+	bytes, _ := json.Marshal(value)
+	return string(bytes)
+}
@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"encoding/csv"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestResponder_Negotiate(t *testing.T) {
+	cases := []struct {
+		accept string
+		format string
+		ok     bool
+	}{
+		{accept: "", format: "json", ok: true},
+		{accept: "text/csv", format: "csv", ok: true},
+		{accept: "application/xml;q=0.5, text/csv;q=0.9", format: "csv", ok: true},
+		{accept: "*/*;q=0.1, application/x-ndjson", format: "ndjson", ok: true},
+		{accept: "application/*", format: "json", ok: true},
+		{accept: "text/html", ok: false},
+		{accept: "text/csv;q=0", ok: false},
+	}
+	rs := NewResponder()
+	for _, c := range cases {
+		t.Run(c.accept, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/vehicles", nil)
+			req.Header.Set("Accept", c.accept)
+			format, ok := rs.Negotiate(req)
+			if format != c.format || ok != c.ok {
+				t.Fatalf("format = %q, ok = %t, want %q and %t", format, ok, c.format, c.ok)
+			}
+		})
+	}
+}
+
+func TestResponder_Vehicles(t *testing.T) {
+	rs := NewResponder()
+	data := map[int]VehicleJSON{2: {ID: 2, Brand: "Ford"}, 1: {ID: 1, Brand: "Hummer"}}
+
+	// - csv: a table with the JSON field names, in id order
+	req := httptest.NewRequest(http.MethodGet, "/vehicles", nil)
+	req.Header.Set("Accept", "text/csv")
+	res := httptest.NewRecorder()
+	rs.Vehicles(res, req, http.StatusOK, data)
+	if res.Code != http.StatusOK || res.Header().Get("Content-Type") != "text/csv" || res.Header().Get("Vary") != "Accept" {
+		t.Fatalf("status = %d, headers = %v, want %d in text/csv varying by Accept", res.Code, res.Header(), http.StatusOK)
+	}
+	rows, err := csv.NewReader(res.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("csv: %v", err)
+	}
+	if len(rows) != 3 || rows[0][1] != "brand" || rows[1][1] != "Hummer" || rows[2][1] != "Ford" {
+		t.Fatalf("rows = %v, want the header and Hummer before Ford", rows)
+	}
+
+	// - unsupported type
+	req.Header.Set("Accept", "text/html")
+	res = httptest.NewRecorder()
+	rs.Respond(res, req, http.StatusOK, map[string]string{"message": "success"})
+	if res.Code != http.StatusNotAcceptable {
+		t.Fatalf("html: status = %d, want %d", res.Code, http.StatusNotAcceptable)
+	}
+}
@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"app/internal/validation"
+	"net/http"
+)
+
+// RuleProvider is an interface that represents the source of the current validation rules
+type RuleProvider interface {
+	// Rules is a method that returns the current rules
+	Rules() (rules []validation.Rule)
+}
+
+// NewRulesDefault is a function that returns a new instance of RulesDefault
+func NewRulesDefault(rp RuleProvider) *RulesDefault {
+	return &RulesDefault{rp: rp, rs: NewResponder()}
+}
+
+// RulesDefault is a struct with methods that represent handlers for the validation rules
+type RulesDefault struct {
+	// rp is the provider of the rules
+	rp RuleProvider
+	// rs is the responder that writes the responses in the format accepted by the client
+	rs *Responder
+}
+
+// GetAll is a method that returns a handler for the route GET /vehicles/rules
+func (h *RulesDefault) GetAll() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// process
+		// - get the current rules
+		rules := h.rp.Rules()
+
+		// response
+		h.rs.Respond(w, r, http.StatusOK, map[string]any{
+			"message": h.rs.Message(r, "success"),
+			"data":    rules,
+		})
+	}
+}
@@ -0,0 +1,222 @@
+package handler
+
+import (
+	"app/internal"
+	"app/internal/schema"
+	"bytes"
+	"encoding/json"
+	"io"
+	"math"
+	"mime"
+	"net/http"
+	"reflect"
+	"time"
+)
+
+// schemaTypes are the JSON Schema types of the kinds of the VehicleJSON fields
+var schemaTypes = map[reflect.Kind]string{
+	reflect.Int:     "integer",
+	reflect.Float64: "number",
+	reflect.String:  "string",
+}
+
+// NewSchemaDefault is a function that returns a new instance of SchemaDefault
+func NewSchemaDefault(rp RuleProvider) *SchemaDefault {
+	return &SchemaDefault{rp: rp, rs: NewResponder()}
+}
+
+// SchemaDefault is a struct with methods that represent handlers for the JSON Schema of the vehicles,
+// built from the current validation rules
+type SchemaDefault struct {
+	// rp is the provider of the validation rules
+	rp RuleProvider
+	// rs is the responder that writes the responses in the format accepted by the client
+	rs *Responder
+}
+
+// VehicleSchema is a method that returns the JSON Schema of VehicleJSON for the current rules, with the bounds
+// of the measures in the unit system. Conditional rules are not part of it, they are checked by the validator of the vehicles
+func (h *SchemaDefault) VehicleSchema(u internal.UnitSystem) *schema.Schema {
+	closed := false
+	id := "/schemas/vehicle.json"
+	if u != internal.UnitsMetric {
+		id += "?units=" + string(u)
+	}
+	s := &schema.Schema{
+		Dialect:              schema.Draft,
+		ID:                   id,
+		Title:                "Vehicle",
+		Type:                 "object",
+		Properties:           make(map[string]*schema.Schema),
+		AdditionalProperties: &closed,
+	}
+	for _, name := range vehicleFieldNames {
+		// the provenance is set by the loaders, it is not received
+		if name == "source" {
+			continue
+		}
+		s.Properties[name] = &schema.Schema{Type: schemaTypes[vehicleFieldKinds[name]]}
+	}
+
+	required := make(map[string]bool)
+	for _, rule := range h.rp.Rules() {
+		prop, ok := s.Properties[rule.Field]
+		if !ok || rule.When != nil {
+			continue
+		}
+		if rule.Required && !required[rule.Field] {
+			required[rule.Field] = true
+			s.Required = append(s.Required, rule.Field)
+		}
+		if rule.Min != nil {
+			prop.Minimum = rule.Min
+		}
+		if rule.ExclusiveMin != nil {
+			if rule.Required {
+				prop.ExclusiveMinimum = rule.ExclusiveMin
+			} else {
+				// optional fields may have their zero value, which means not given
+				min := math.Min(0, *rule.ExclusiveMin)
+				prop.Minimum = &min
+			}
+		}
+		if rule.Max != nil {
+			prop.Maximum = rule.Max
+		}
+		if rule.MaxYearOffset != nil {
+			max := float64(time.Now().Year() + *rule.MaxYearOffset)
+			if prop.Maximum == nil || max < *prop.Maximum {
+				prop.Maximum = &max
+			}
+		}
+		if len(rule.Enum) > 0 {
+			prop.Enum = nil
+			for _, value := range rule.Enum {
+				prop.Enum = append(prop.Enum, value)
+			}
+		}
+		if rule.Pattern != "" {
+			prop.Pattern = rule.Pattern
+		}
+	}
+
+	// - the rules are in the canonical units, the bounds of the measures are converted to the unit system
+	for name, convert := range schemaMeasures(u) {
+		prop := s.Properties[name]
+		for _, bound := range []**float64{&prop.Minimum, &prop.ExclusiveMinimum, &prop.Maximum} {
+			if *bound != nil {
+				value := convert(**bound)
+				*bound = &value
+			}
+		}
+	}
+	return s
+}
+
+// schemaMeasures is a function that returns the conversions of the measures of a vehicle from the canonical units
+// to the unit system, by JSON field name
+func schemaMeasures(u internal.UnitSystem) map[string]func(float64) float64 {
+	return map[string]func(float64) float64{
+		"max_speed": u.Speed,
+		"weight":    u.Weight,
+		"height":    u.Length,
+		"length":    u.Length,
+		"width":     u.Length,
+	}
+}
+
+// GetVehicle is a method that returns a handler for the route GET /schemas/vehicle.json,
+// the schema of the vehicles in the unit system of the request
+func (h *SchemaDefault) GetVehicle() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// process
+		bytes, err := json.MarshalIndent(h.VehicleSchema(UnitsFrom(r.Context())), "", "  ")
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		// response
+		w.Header().Set("Content-Type", "application/schema+json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(bytes)
+	}
+}
+
+// SchemaMode is the way a body is checked against the vehicle schema
+type SchemaMode int
+
+const (
+	// SchemaCreate checks a whole vehicle
+	SchemaCreate SchemaMode = iota
+	// SchemaBatch checks an array of whole vehicles
+	SchemaBatch
+	// SchemaPatch checks some fields of a vehicle
+	SchemaPatch
+	// SchemaReplace checks a whole vehicle whose id may be omitted, as it is the one of the path
+	SchemaReplace
+	// SchemaMergePatch checks a JSON Merge Patch of a vehicle, whose fields may be null to remove them.
+	// A JSON Patch is not checked, its operations are checked by the handler as they are applied
+	SchemaMergePatch
+)
+
+// Enforce is a method that returns a middleware that rejects the bodies that violate the vehicle schema
+// in the unit system of the request, with the JSON pointers of all the violations
+func (h *SchemaDefault) Enforce(mode SchemaMode) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// a batch in partial mode is checked vehicle by vehicle by the handler, so the valid ones are created
+			if mode == SchemaBatch && r.URL.Query().Get("mode") == "partial" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mode == SchemaMergePatch && mediaType == mediaTypeJSONPatch {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// read into bytes, the body is restored for the handler
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				h.rs.Error(w, r, ErrBadRequest.Wrap(err))
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			var value any
+			if err = json.Unmarshal(body, &value); err != nil {
+				h.rs.Error(w, r, ErrBadRequest.Wrap(err))
+				return
+			}
+
+			// schema by mode
+			s := h.VehicleSchema(UnitsFrom(r.Context()))
+			switch mode {
+			case SchemaBatch:
+				s = &schema.Schema{Type: "array", Items: s}
+			case SchemaPatch:
+				s = s.Partial()
+			case SchemaReplace:
+				replace := s.Partial()
+				for _, name := range s.Required {
+					if name != "id" {
+						replace.Required = append(replace.Required, name)
+					}
+				}
+				s = replace
+			case SchemaMergePatch:
+				s = s.MergePatch()
+			}
+
+			if errs := s.Validate(value); len(errs) > 0 {
+				fields := make([]ProblemField, 0, len(errs))
+				for _, e := range errs {
+					fields = append(fields, ProblemField{Pointer: e.Pointer, Message: e.Message})
+				}
+				h.rs.Error(w, r, ErrValidation.WithFields(fields))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,211 @@
+package handler
+
+import (
+	"app/internal/schema"
+	"app/internal/validation"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestSchemaDefault_GetVehicle(t *testing.T) {
+	hd := NewSchemaDefault(validation.NewVehicleRules(nil))
+
+	res := httptest.NewRecorder()
+	hd.GetVehicle()(res, httptest.NewRequest(http.MethodGet, "/schemas/vehicle.json", nil))
+	if res.Code != http.StatusOK || res.Header().Get("Content-Type") != "application/schema+json" {
+		t.Fatalf("status = %d, content type = %q", res.Code, res.Header().Get("Content-Type"))
+	}
+	var s schema.Schema
+	if err := json.Unmarshal(res.Body.Bytes(), &s); err != nil {
+		t.Fatalf("body: %v", err)
+	}
+	if s.Properties["year"].Type != "integer" || s.Properties["year"].Minimum == nil || *s.Properties["year"].Minimum != 1886 {
+		t.Fatalf("year = %+v, want an integer from 1886", s.Properties["year"])
+	}
+	if s.Properties["registration"].Pattern == "" || len(s.Properties["fuel_type"].Enum) == 0 {
+		t.Fatalf("schema = %+v, want the pattern of registration and the fuel types", s.Properties)
+	}
+	if _, ok := s.Properties["source"]; ok {
+		t.Fatalf("schema has source, want only the received fields")
+	}
+}
+
+func TestSchemaDefault_Enforce(t *testing.T) {
+	hd := NewSchemaDefault(validation.NewVehicleRules(nil))
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	cases := []struct {
+		name   string
+		mode   SchemaMode
+		body   string
+		status int
+	}{
+		{name: "patch valid", mode: SchemaPatch, body: `{"max_speed": 120}`, status: http.StatusNoContent},
+		{name: "patch wrong type", mode: SchemaPatch, body: `{"max_speed": "fast"}`, status: http.StatusUnprocessableEntity},
+		{name: "create missing fields", mode: SchemaCreate, body: `{"id": 1}`, status: http.StatusUnprocessableEntity},
+		{name: "batch not an array", mode: SchemaBatch, body: `{"id": 1}`, status: http.StatusUnprocessableEntity},
+		{name: "malformed", mode: SchemaPatch, body: `{`, status: http.StatusBadRequest},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/vehicles", strings.NewReader(c.body))
+			res := httptest.NewRecorder()
+			hd.Enforce(c.mode)(next).ServeHTTP(res, req)
+			if res.Code != c.status {
+				t.Fatalf("status = %d, want %d: %s", res.Code, c.status, res.Body)
+			}
+		})
+	}
+
+	// - the violations are pointed
+	req := httptest.NewRequest(http.MethodPost, "/vehicles", strings.NewReader(`{"id": 1, "wheels": 4}`))
+	res := httptest.NewRecorder()
+	hd.Enforce(SchemaCreate)(next).ServeHTTP(res, req)
+	var body Problem
+	json.Unmarshal(res.Body.Bytes(), &body)
+	pointers := make(map[string]bool)
+	for _, err := range body.Errors {
+		pointers[err.Pointer] = true
+	}
+	if !pointers["/brand"] || !pointers["/wheels"] {
+		t.Fatalf("errors = %+v, want /brand and /wheels", body.Errors)
+	}
+}
+
+// newSchemaRouter is a function that returns a router with the schema routes for the rules of
+// docs/rules/electric_fleet.json, the vehicles that pass the schema are answered with 201
+func newSchemaRouter(t *testing.T) *chi.Mux {
+	rules, err := validation.LoadRulesFile("../../docs/rules/electric_fleet.json")
+	if err != nil {
+		t.Fatalf("rules: %v", err)
+	}
+	sc := NewSchemaDefault(validation.NewVehicleRules(rules))
+
+	rt := chi.NewRouter()
+	rt.Use(Units)
+	rt.Get("/schemas/vehicle.json", sc.GetVehicle())
+	rt.With(sc.Enforce(SchemaCreate)).Post("/vehicles", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	})
+	rt.With(sc.Enforce(SchemaMergePatch)).Patch("/vehicles/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	return rt
+}
+
+func TestSchemaDefault_Enforce_Units(t *testing.T) {
+	rt := newSchemaRouter(t)
+	// the fleet allows up to 155 mph, about 249 km/h, and 3500 kg
+	cases := []struct {
+		name     string
+		units    string
+		speed    float64
+		weight   float64
+		status   int
+		pointers []string
+	}{
+		{name: "metric within bounds", units: "metric", speed: 240, weight: 2000, status: http.StatusCreated},
+		{name: "metric out of bounds", units: "metric", speed: 260, weight: 5000, status: http.StatusUnprocessableEntity, pointers: []string{"/max_speed", "/weight"}},
+		{name: "imperial within bounds", units: "imperial", speed: 150, weight: 5000, status: http.StatusCreated},
+		{name: "imperial out of bounds", units: "imperial", speed: 240, weight: 8000, status: http.StatusUnprocessableEntity, pointers: []string{"/max_speed", "/weight"}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			body := fmt.Sprintf(`{"id": 1, "brand": "Tesla", "model": "3", "registration": "ABC-123", "color": "red", "year": 2022,
+				"passengers": 5, "max_speed": %v, "fuel_type": "electric", "transmission": "automatic", "weight": %v, "height": 60, "width": 70}`,
+				c.speed, c.weight)
+			res := serve(rt, http.MethodPost, "/vehicles?units="+c.units, "application/json", body)
+
+			if res.Code != c.status {
+				t.Fatalf("status = %d, want %d: %s", res.Code, c.status, res.Body)
+			}
+			if c.pointers != nil {
+				checkPointers(t, res, c.pointers)
+			}
+		})
+	}
+}
+
+func TestSchemaDefault_GetVehicle_Units(t *testing.T) {
+	rt := newSchemaRouter(t)
+
+	res := serve(rt, http.MethodGet, "/schemas/vehicle.json?units=imperial", "", "")
+	if res.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", res.Code, http.StatusOK)
+	}
+	var s schema.Schema
+	if err := json.Unmarshal(res.Body.Bytes(), &s); err != nil {
+		t.Fatalf("schema: %v", err)
+	}
+	// - 155 mph, 3500 kg and 9 passengers
+	want := map[string]float64{"max_speed": 155, "weight": 7716.17, "passengers": 9}
+	for name, max := range want {
+		got := s.Properties[name].Maximum
+		if got == nil || math.Abs(*got-max) > 0.01 {
+			t.Fatalf("%s maximum = %v, want about %v", name, ptrValue(got), max)
+		}
+	}
+}
+
+// checkPointers is a function that fails the test if the problem of the response does not point to exactly the pointers
+func checkPointers(t *testing.T, res *httptest.ResponseRecorder, pointers []string) {
+	t.Helper()
+	var problem Problem
+	if err := json.Unmarshal(res.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("problem: %v", err)
+	}
+	if len(problem.Errors) != len(pointers) {
+		t.Fatalf("errors = %+v, want %v", problem.Errors, pointers)
+	}
+	for i, pointer := range pointers {
+		if problem.Errors[i].Pointer != pointer {
+			t.Fatalf("errors = %+v, want %v", problem.Errors, pointers)
+		}
+	}
+}
+
+// ptrValue is a function that returns the value of a pointer, nil if there is none
+func ptrValue(p *float64) any {
+	if p == nil {
+		return nil
+	}
+	return *p
+}
+
+func TestSchemaDefault_Enforce_MergePatch(t *testing.T) {
+	rt := newSchemaRouter(t)
+	cases := []struct {
+		name        string
+		contentType string
+		body        string
+		status      int
+		pointers    []string
+	}{
+		{name: "valid fields", contentType: mediaTypeMergePatch, body: `{"color": "blue", "max_speed": 200}`, status: http.StatusOK},
+		{name: "null removes a field", contentType: mediaTypeMergePatch, body: `{"length": null}`, status: http.StatusOK},
+		{name: "wrong type", contentType: mediaTypeMergePatch, body: `{"color": 5}`, status: http.StatusUnprocessableEntity, pointers: []string{"/color"}},
+		{name: "out of bounds and unknown", contentType: "application/json", body: `{"max_speed": 300, "wings": 2}`, status: http.StatusUnprocessableEntity, pointers: []string{"/max_speed", "/wings"}},
+		{name: "JSON Patch is left to the handler", contentType: mediaTypeJSONPatch, body: `[{"op": "replace", "path": "/color", "value": 5}]`, status: http.StatusOK},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			res := serve(rt, http.MethodPatch, "/vehicles/1", c.contentType, c.body)
+
+			if res.Code != c.status {
+				t.Fatalf("status = %d, want %d: %s", res.Code, c.status, res.Body)
+			}
+			if c.pointers != nil {
+				checkPointers(t, res, c.pointers)
+			}
+		})
+	}
+}
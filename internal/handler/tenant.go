@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"app/internal"
+	"context"
+	"fmt"
+	"net/http"
+)
+
+var (
+	// ErrTenantNotFound is an error for a tenant that does not exist
+	ErrTenantNotFound = &APIError{Status: http.StatusNotFound, Code: "tenant_not_found"}
+	// ErrTenantForbidden is an error for a tenant other than the one the credentials are bound to
+	ErrTenantForbidden = &APIError{Status: http.StatusForbidden, Code: "tenant_forbidden"}
+	// ErrQuotaExceeded is an error for a change that exceeds the quota of the tenant
+	ErrQuotaExceeded = &APIError{Status: http.StatusForbidden, Code: "quota_exceeded"}
+)
+
+// tenantKey is the type of the key of the tenant in the context
+type tenantKey struct{}
+
+// tenant is a struct that represents the tenant of a request
+type tenant struct {
+	// id is the identifier of the tenant
+	id string
+	// sv is the service of the vehicles of the tenant
+	sv internal.VehicleService
+}
+
+// Tenants is a function that returns a middleware that adds to the context of the request its tenant, with the service
+// of its vehicles: the tenant the credentials are bound to, or else the X-Tenant-ID header, the default tenant if there is none.
+// A request for a tenant other than the one of its credentials is rejected. The tenant is echoed in the X-Tenant-ID header
+func Tenants(services map[string]internal.VehicleService) func(http.Handler) http.Handler {
+	rs := NewResponder()
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := r.Header.Get("X-Tenant-ID")
+			p, _ := PrincipalFrom(r.Context())
+			switch {
+			case p.Tenant != "" && id != "" && id != p.Tenant:
+				rs.Error(w, r, fmt.Errorf("%w: the credentials are bound to another tenant", internal.ErrTenantForbidden))
+				return
+			case p.Tenant != "":
+				id = p.Tenant
+			case id == "":
+				id = internal.DefaultTenant
+			}
+			sv, ok := services[id]
+			if !ok {
+				rs.Error(w, r, fmt.Errorf("%w: %s", internal.ErrTenantNotFound, id))
+				return
+			}
+
+			w.Header().Set("X-Tenant-ID", id)
+			w.Header().Add("Vary", "X-Tenant-ID")
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), tenantKey{}, tenant{id: id, sv: sv})))
+		})
+	}
+}
+
+// TenantFrom is a function that returns the tenant of the context, the default tenant if there is none
+func TenantFrom(ctx context.Context) string {
+	if t, ok := ctx.Value(tenantKey{}).(tenant); ok {
+		return t.id
+	}
+	return internal.DefaultTenant
+}
+
+// serviceFrom is a function that returns the service of the vehicles of the tenant of the context, or else the fallback
+func serviceFrom(ctx context.Context, fallback internal.VehicleService) internal.VehicleService {
+	if t, ok := ctx.Value(tenantKey{}).(tenant); ok {
+		return t.sv
+	}
+	return fallback
+}
@@ -0,0 +1,332 @@
+package handler
+
+import (
+	"app/internal"
+	"app/internal/audit"
+	"app/internal/auth"
+	"app/internal/i18n"
+	"app/internal/job"
+	"app/internal/repository"
+	"app/internal/service"
+	"app/internal/validation"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// tenantServer is a struct that represents a server with the tenants acme and globex, routed as the application does
+type tenantServer struct {
+	// rt is the router
+	rt http.Handler
+	// repos are the repositories of the tenants, by tenant id
+	repos map[string]*repository.VehicleMap
+}
+
+// newTenantServer is a function that returns a server with the tenants acme, with a quota of limit vehicles and the vehicle 1,
+// and globex. The key admin-key may act on any tenant, acme-key is bound to acme
+func newTenantServer(t *testing.T, limit int) *tenantServer {
+	t.Helper()
+	// - keys
+	apiKey := func(key, id, tenant string) auth.APIKey {
+		sum := sha256.Sum256([]byte(key))
+		return auth.APIKey{ID: id, SHA256: hex.EncodeToString(sum[:]), Subject: id, Scopes: []internal.Scope{internal.ScopeAdmin}, Tenant: tenant}
+	}
+	keys := auth.NewKeys(auth.KeysFile{APIKeys: []auth.APIKey{apiKey("admin-key", "admin", ""), apiKey("acme-key", "acme", "acme")}})
+
+	// - tenants
+	s := &tenantServer{repos: map[string]*repository.VehicleMap{
+		internal.DefaultTenant: repository.NewVehicleMap(nil),
+		"acme":                 repository.NewVehicleMap(map[int]internal.Vehicle{1: testVehicle(1)}),
+		"globex":               repository.NewVehicleMap(nil),
+	}}
+	s.repos["acme"].SetLimit(limit)
+	services := make(map[string]internal.VehicleService)
+	for id, rp := range s.repos {
+		services[id] = service.NewVehicleDefault(rp)
+	}
+
+	// - handlers
+	vl := validation.NewVehicleRules(nil)
+	al, err := audit.NewFile(filepath.Join(t.TempDir(), "audit.log"))
+	if err != nil {
+		t.Fatalf("audit: %v", err)
+	}
+	t.Cleanup(func() { al.Close() })
+	au := NewAudit(al)
+	rn := job.NewRunner(nil)
+	rn.Start()
+	t.Cleanup(rn.Stop)
+	hd := NewVehicleDefault(services[internal.DefaultTenant], vl)
+	jb := NewJobDefault(rn, services[internal.DefaultTenant], vl)
+
+	// - router
+	rt := chi.NewRouter()
+	rt.Use(i18n.NewLocalizer(i18n.Catalogs, "en").Middleware)
+	rt.Use(Units)
+	rt.Use(Authenticate(keys))
+	rt.Use(au.Middleware)
+	rt.Use(Tenants(services))
+	rt.Use(Permissions(DefaultFieldPolicy))
+	rt.Post("/vehicles", hd.Create())
+	rt.Post("/vehicles/batch", hd.CreateBatch())
+	rt.Post("/vehicles/import", hd.Import())
+	rt.Get("/vehicles/{id}", hd.GetById())
+	rt.Put("/vehicles/{id}", hd.Replace())
+	rt.Post("/jobs/import", jb.Import())
+	rt.Get("/jobs/{id}", jb.GetById())
+	rt.Get("/audit", au.GetAll())
+	s.rt = rt
+	return s
+}
+
+// do is a method that serves a request with the API key, and the X-Tenant-ID header if tenant is not empty
+func (s *tenantServer) do(method, target, key, tenant string, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-API-Key", key)
+	if tenant != "" {
+		req.Header.Set("X-Tenant-ID", tenant)
+	}
+	res := httptest.NewRecorder()
+	s.rt.ServeHTTP(res, req)
+	return res
+}
+
+// upload is a method that serves a request with an NDJSON file of vehicles in a multipart form
+func (s *tenantServer) upload(target, key, tenant string, ids ...int) *httptest.ResponseRecorder {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	part, _ := mw.CreateFormFile("file", "vehicles.ndjson")
+	for _, id := range ids {
+		part.Write([]byte(testVehicleJSON(id) + "\n"))
+	}
+	mw.Close()
+
+	req := httptest.NewRequest(http.MethodPost, target, &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	req.Header.Set("X-API-Key", key)
+	if tenant != "" {
+		req.Header.Set("X-Tenant-ID", tenant)
+	}
+	res := httptest.NewRecorder()
+	s.rt.ServeHTTP(res, req)
+	return res
+}
+
+// testVehicle is a function that returns a valid vehicle with an id
+func testVehicle(id int) internal.Vehicle {
+	v := internal.Vehicle{Id: id}
+	v.Brand, v.Model, v.Registration, v.Color = "Ford", "Focus", fmt.Sprintf("REG-%d", id), "red"
+	v.FabricationYear, v.Capacity, v.MaxSpeed = 2020, 5, 200
+	v.FuelType, v.Transmission, v.Weight = "gasoline", "manual", 1300
+	v.Height, v.Width = 150, 180
+	return v
+}
+
+// testVehicleJSON is a function that returns a valid vehicle with an id as JSON
+func testVehicleJSON(id int) string {
+	b, _ := json.Marshal(VehicleToVehicleJSON(testVehicle(id)))
+	return string(b)
+}
+
+// problemOf is a function that decodes the problem of a response
+func problemOf(t *testing.T, res *httptest.ResponseRecorder) (problem Problem) {
+	t.Helper()
+	if err := json.Unmarshal(res.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("problem: %v: %s", err, res.Body)
+	}
+	return
+}
+
+func TestTenants_Isolation(t *testing.T) {
+	s := newTenantServer(t, 0)
+
+	// - a vehicle of acme is not found in globex
+	if res := s.do(http.MethodPost, "/vehicles", "admin-key", "acme", testVehicleJSON(50)); res.Code != http.StatusCreated {
+		t.Fatalf("create: status = %d, want %d: %s", res.Code, http.StatusCreated, res.Body)
+	}
+	if res := s.do(http.MethodGet, "/vehicles/50", "admin-key", "globex", ""); res.Code != http.StatusNotFound {
+		t.Fatalf("get from globex: status = %d, want %d", res.Code, http.StatusNotFound)
+	}
+	if res := s.do(http.MethodGet, "/vehicles/50", "admin-key", "acme", ""); res.Code != http.StatusOK {
+		t.Fatalf("get from acme: status = %d, want %d", res.Code, http.StatusOK)
+	}
+
+	// - the credentials bound to acme act on acme, and can not act on another tenant
+	res := s.do(http.MethodGet, "/vehicles/50", "acme-key", "", "")
+	if res.Code != http.StatusOK || res.Header().Get("X-Tenant-ID") != "acme" {
+		t.Fatalf("bound get: status = %d, tenant = %q, want %d and acme", res.Code, res.Header().Get("X-Tenant-ID"), http.StatusOK)
+	}
+	res = s.do(http.MethodGet, "/vehicles/50", "acme-key", "globex", "")
+	if res.Code != http.StatusForbidden || problemOf(t, res).Code != "tenant_forbidden" {
+		t.Fatalf("bound get from globex: status = %d, want %d tenant_forbidden: %s", res.Code, http.StatusForbidden, res.Body)
+	}
+	if res := s.do(http.MethodGet, "/vehicles/50", "admin-key", "initech", ""); res.Code != http.StatusNotFound {
+		t.Fatalf("unknown tenant: status = %d, want %d", res.Code, http.StatusNotFound)
+	}
+}
+
+func TestTenants_Jobs(t *testing.T) {
+	s := newTenantServer(t, 0)
+
+	res := s.upload("/jobs/import", "acme-key", "", 60)
+	if res.Code != http.StatusAccepted {
+		t.Fatalf("submit: status = %d, want %d: %s", res.Code, http.StatusAccepted, res.Body)
+	}
+	var body struct {
+		Data internal.Job `json:"data"`
+	}
+	if err := json.Unmarshal(res.Body.Bytes(), &body); err != nil {
+		t.Fatalf("job: %v", err)
+	}
+
+	// - the job is only seen by its tenant
+	if res := s.do(http.MethodGet, "/jobs/"+body.Data.ID, "admin-key", "globex", ""); res.Code != http.StatusNotFound {
+		t.Fatalf("get from globex: status = %d, want %d", res.Code, http.StatusNotFound)
+	}
+	if res := s.do(http.MethodGet, "/jobs/"+body.Data.ID, "admin-key", "acme", ""); res.Code != http.StatusOK {
+		t.Fatalf("get from acme: status = %d, want %d", res.Code, http.StatusOK)
+	}
+}
+
+func TestTenants_Audit(t *testing.T) {
+	s := newTenantServer(t, 0)
+	s.do(http.MethodPost, "/vehicles", "acme-key", "", testVehicleJSON(70))
+	s.do(http.MethodPost, "/vehicles", "admin-key", "globex", testVehicleJSON(71))
+
+	// records is a function that returns the tenants and vehicles of the records of GET /audit
+	records := func(target, key, tenant string) (res *httptest.ResponseRecorder, got []string) {
+		res = s.do(http.MethodGet, target, key, tenant, "")
+		var body struct {
+			Data []internal.AuditRecord `json:"data"`
+		}
+		json.Unmarshal(res.Body.Bytes(), &body)
+		for _, r := range body.Data {
+			got = append(got, fmt.Sprintf("%s:%v", r.Tenant, r.VehicleIDs))
+		}
+		return
+	}
+
+	// - the credentials bound to acme only read the records of acme
+	if _, got := records("/audit", "acme-key", ""); len(got) != 1 || got[0] != "acme:[70]" {
+		t.Fatalf("bound records = %v, want only acme:[70]", got)
+	}
+	if res, _ := records("/audit?tenant=globex", "acme-key", ""); res.Code != http.StatusForbidden {
+		t.Fatalf("bound records of globex: status = %d, want %d", res.Code, http.StatusForbidden)
+	}
+	if _, got := records("/audit?tenant=globex", "admin-key", ""); len(got) != 1 || got[0] != "globex:[71]" {
+		t.Fatalf("globex records = %v, want only globex:[71]", got)
+	}
+}
+
+func TestTenants_Quota(t *testing.T) {
+	// acme has a quota of 2 vehicles and has the vehicle 1
+	cases := []struct {
+		name   string
+		send   func(s *tenantServer) *httptest.ResponseRecorder
+		status int
+		count  int
+	}{
+		{name: "create within", status: http.StatusCreated, count: 2, send: func(s *tenantServer) *httptest.ResponseRecorder {
+			return s.do(http.MethodPost, "/vehicles", "acme-key", "", testVehicleJSON(2))
+		}},
+		{name: "save of a new vehicle", status: http.StatusCreated, count: 2, send: func(s *tenantServer) *httptest.ResponseRecorder {
+			return s.do(http.MethodPut, "/vehicles/2", "acme-key", "", testVehicleJSON(2))
+		}},
+		{name: "batch beyond", status: http.StatusForbidden, count: 1, send: func(s *tenantServer) *httptest.ResponseRecorder {
+			return s.do(http.MethodPost, "/vehicles/batch", "acme-key", "", "["+testVehicleJSON(2)+","+testVehicleJSON(3)+"]")
+		}},
+		{name: "partial batch beyond", status: http.StatusMultiStatus, count: 2, send: func(s *tenantServer) *httptest.ResponseRecorder {
+			return s.do(http.MethodPost, "/vehicles/batch?mode=partial", "acme-key", "", "["+testVehicleJSON(2)+","+testVehicleJSON(3)+"]")
+		}},
+		{name: "import beyond", status: http.StatusForbidden, count: 1, send: func(s *tenantServer) *httptest.ResponseRecorder {
+			return s.upload("/vehicles/import", "acme-key", "", 2, 3)
+		}},
+		{name: "import with a replaced vehicle", status: http.StatusOK, count: 2, send: func(s *tenantServer) *httptest.ResponseRecorder {
+			return s.upload("/vehicles/import?on_conflict=overwrite", "acme-key", "", 1, 2)
+		}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s := newTenantServer(t, 2)
+
+			res := c.send(s)
+			if res.Code != c.status {
+				t.Fatalf("status = %d, want %d: %s", res.Code, c.status, res.Body)
+			}
+			if v, _ := s.repos["acme"].FindAll(); len(v) != c.count {
+				t.Fatalf("acme has %d vehicles, want %d", len(v), c.count)
+			}
+		})
+	}
+
+	t.Run("full", func(t *testing.T) {
+		s := newTenantServer(t, 2)
+		s.do(http.MethodPost, "/vehicles", "acme-key", "", testVehicleJSON(2))
+
+		// - creations are rejected, replacements are not
+		for _, res := range []*httptest.ResponseRecorder{
+			s.do(http.MethodPost, "/vehicles", "acme-key", "", testVehicleJSON(3)),
+			s.do(http.MethodPut, "/vehicles/3", "acme-key", "", testVehicleJSON(3)),
+		} {
+			if res.Code != http.StatusForbidden || problemOf(t, res).Code != "quota_exceeded" {
+				t.Fatalf("status = %d, want %d quota_exceeded: %s", res.Code, http.StatusForbidden, res.Body)
+			}
+		}
+		if res := s.do(http.MethodPut, "/vehicles/1", "acme-key", "", testVehicleJSON(1)); res.Code != http.StatusOK {
+			t.Fatalf("replace: status = %d, want %d: %s", res.Code, http.StatusOK, res.Body)
+		}
+		// - the jobs fail on the quota too
+		res := s.upload("/jobs/import", "acme-key", "", 3)
+		var body struct {
+			Data internal.Job `json:"data"`
+		}
+		json.Unmarshal(res.Body.Bytes(), &body)
+		for deadline := time.Now().Add(2 * time.Second); !body.Data.State.Finished() && time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+			json.Unmarshal(s.do(http.MethodGet, "/jobs/"+body.Data.ID, "acme-key", "", "").Body.Bytes(), &body)
+		}
+		if body.Data.State != internal.JobFailed {
+			t.Fatalf("job = %+v, want failed", body.Data)
+		}
+	})
+}
+
+func TestTenants_Quota_Partial(t *testing.T) {
+	s := newTenantServer(t, 2)
+
+	// - the vehicles up to the quota are created, the rest are reported with a localized detail
+	req := httptest.NewRequest(http.MethodPost, "/vehicles/batch?mode=partial", strings.NewReader("["+testVehicleJSON(2)+","+testVehicleJSON(3)+","+testVehicleJSON(4)+"]"))
+	req.Header.Set("X-API-Key", "acme-key")
+	req.Header.Set("Accept-Language", "es")
+	res := httptest.NewRecorder()
+	s.rt.ServeHTTP(res, req)
+
+	var body struct {
+		Data struct {
+			Summary map[string]int `json:"summary"`
+			Items   []BatchItem    `json:"items"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(res.Body.Bytes(), &body); err != nil {
+		t.Fatalf("body: %v", err)
+	}
+	if body.Data.Summary["created"] != 1 || body.Data.Summary["failed"] != 2 {
+		t.Fatalf("summary = %v, want 1 created and 2 failed", body.Data.Summary)
+	}
+	for _, item := range body.Data.Items[1:] {
+		if item.Status != http.StatusForbidden || item.Problem.Code != "quota_exceeded" || item.Problem.Detail != "el inquilino no puede tener más vehículos que limit=2" {
+			t.Fatalf("item = %+v, problem = %+v, want quota_exceeded in Spanish", item, item.Problem)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"app/internal"
+	"context"
+	"math"
+	"net/http"
+)
+
+// ErrBadUnits is an error for an unsupported unit system
+var ErrBadUnits = &APIError{Status: http.StatusBadRequest, Code: "unsupported_units"}
+
+// unitsKey is the type of the key of the unit system in the context
+type unitsKey struct{}
+
+// Units is a middleware that adds to the context of the request the unit system of its measures, in the input and in the output:
+// the units query parameter, or the X-Units header, metric by default. The unit system is echoed in the X-Units header
+func Units(next http.Handler) http.Handler {
+	rs := NewResponder()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := r.URL.Query().Get("units")
+		if name == "" {
+			name = r.Header.Get("X-Units")
+		}
+		u, err := internal.ParseUnitSystem(name)
+		if err != nil {
+			rs.Error(w, r, ErrBadUnits.Wrap(err))
+			return
+		}
+
+		w.Header().Set("X-Units", string(u))
+		w.Header().Add("Vary", "X-Units")
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), unitsKey{}, u)))
+	})
+}
+
+// UnitsFrom is a function that returns the unit system of the context, metric if there is none
+func UnitsFrom(ctx context.Context) internal.UnitSystem {
+	if u, ok := ctx.Value(unitsKey{}).(internal.UnitSystem); ok {
+		return u
+	}
+	return internal.UnitsMetric
+}
+
+// UnitsOf is a function that returns the units of the measures of a vehicle in a unit system, by JSON field name
+func UnitsOf(u internal.UnitSystem) map[string]string {
+	return map[string]string{
+		"max_speed": u.SpeedUnit(),
+		"weight":    u.WeightUnit(),
+		"height":    u.LengthUnit(),
+		"length":    u.LengthUnit(),
+		"width":     u.LengthUnit(),
+	}
+}
+
+// inUnits is a method that returns the vehicle with its measures converted from the canonical units to the unit system,
+// the converted measures are rounded to two decimals
+func (v VehicleJSON) inUnits(u internal.UnitSystem) VehicleJSON {
+	v.MaxSpeed = round(u.Speed(v.MaxSpeed))
+	v.Weight = round(u.Weight(v.Weight))
+	v.Height = round(u.Length(v.Height))
+	v.Length = round(u.Length(v.Length))
+	v.Width = round(u.Length(v.Width))
+	return v
+}
+
+// round is a function that rounds a measure to two decimals
+func round(value float64) float64 {
+	return math.Round(value*100) / 100
+}
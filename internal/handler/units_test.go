@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"app/internal"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestUnits(t *testing.T) {
+	cases := []struct {
+		name   string
+		target string
+		header string
+		status int
+		units  internal.UnitSystem
+	}{
+		{name: "default", target: "/vehicles", status: http.StatusOK, units: internal.UnitsMetric},
+		{name: "query", target: "/vehicles?units=imperial", status: http.StatusOK, units: internal.UnitsImperial},
+		{name: "header", target: "/vehicles", header: "imperial", status: http.StatusOK, units: internal.UnitsImperial},
+		{name: "query over header", target: "/vehicles?units=metric", header: "imperial", status: http.StatusOK, units: internal.UnitsMetric},
+		{name: "unknown", target: "/vehicles?units=nautical", status: http.StatusBadRequest},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var units internal.UnitSystem
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				units = UnitsFrom(r.Context())
+			})
+			req := httptest.NewRequest(http.MethodGet, c.target, nil)
+			if c.header != "" {
+				req.Header.Set("X-Units", c.header)
+			}
+			res := httptest.NewRecorder()
+			Units(next).ServeHTTP(res, req)
+
+			if res.Code != c.status || units != c.units {
+				t.Fatalf("status = %d, units = %q, want %d and %q", res.Code, units, c.status, c.units)
+			}
+			if c.status == http.StatusOK && res.Header().Get("X-Units") != string(c.units) {
+				t.Fatalf("X-Units = %q, want %q", res.Header().Get("X-Units"), c.units)
+			}
+		})
+	}
+}
+
+func TestVehicleJSON_inUnits(t *testing.T) {
+	v := VehicleJSON{ID: 1, MaxSpeed: 100, Weight: 1000, Height: 150, Width: 180}
+
+	if got := v.inUnits(internal.UnitsMetric); got.MaxSpeed != 160.93 || got.Weight != v.Weight || got.Height != v.Height {
+		t.Fatalf("metric = %+v, want the speed in km/h rounded to two decimals", got)
+	}
+	got := v.inUnits(internal.UnitsImperial)
+	if got.Height != 59.06 || got.Width != 70.87 || got.Weight != 2204.62 {
+		t.Fatalf("imperial = %+v, want the measures rounded to two decimals", got)
+	}
+}
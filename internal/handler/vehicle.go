@@ -2,33 +2,19 @@ package handler
 
 import (
 	"app/internal"
-	"app/internal/tools"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"mime"
 	"net/http"
+	"path/filepath"
 	"strconv"
 	"strings"
 
-	"github.com/bootcamp-go/web/response"
 	"github.com/go-chi/chi/v5"
 )
 
-var (
-	// ErrBadRequest is an error that represents a bad request
-	ErrBadRequest = "Datos del vehículo mal formados o incompletos."
-	// ErrDuplicatedId is an error that represents a duplicated id
-	ErrDuplicatedId = "Identificador del vehículo ya existente."
-	//ErrBadCriteria is an error for bad parameters
-	ErrBadCriteria = "No se encontraron vehículos con esos criterios."
-	//ErrBadSpeed is an error for bad speed
-	ErrBadSpeed = "Velocidad mal formada o fuera de rango."
-	//ErrBadFuelType is an error for bad fuel type
-	ErrBadFuelType = "Tipo de combustible mal formado o no admitido."
-	//ErrNotFound is an error for not found
-	ErrNotFound = "No se encontró el vehículo."
-)
-
 // VehicleJSON is a struct that represents a vehicle in JSON format
 type VehicleJSON struct {
 	ID              int     `json:"id"`
@@ -45,31 +31,37 @@ type VehicleJSON struct {
 	Height          float64 `json:"height"`
 	Length          float64 `json:"length"`
 	Width           float64 `json:"width"`
+	Source          string  `json:"source,omitempty"`
 }
 
 func DataMap(v *map[int]internal.Vehicle) map[int]VehicleJSON {
 	data := make(map[int]VehicleJSON)
 	for key, value := range *v {
-		data[key] = VehicleJSON{
-			ID:              value.Id,
-			Brand:           value.Brand,
-			Model:           value.Model,
-			Registration:    value.Registration,
-			Color:           value.Color,
-			FabricationYear: value.FabricationYear,
-			Capacity:        value.Capacity,
-			MaxSpeed:        value.MaxSpeed,
-			FuelType:        value.FuelType,
-			Transmission:    value.Transmission,
-			Weight:          value.Weight,
-			Height:          value.Height,
-			Length:          value.Length,
-			Width:           value.Width,
-		}
+		data[key] = VehicleToVehicleJSON(value)
 	}
 	return data
 }
 
+func VehicleToVehicleJSON(v internal.Vehicle) VehicleJSON {
+	return VehicleJSON{
+		ID:              v.Id,
+		Brand:           v.Brand,
+		Model:           v.Model,
+		Registration:    v.Registration,
+		Color:           v.Color,
+		FabricationYear: v.FabricationYear,
+		Capacity:        v.Capacity,
+		MaxSpeed:        v.MaxSpeed,
+		FuelType:        v.FuelType,
+		Transmission:    v.Transmission,
+		Weight:          v.Weight,
+		Height:          v.Height,
+		Length:          v.Length,
+		Width:           v.Width,
+		Source:          v.Source,
+	}
+}
+
 func VehicleJSONToVehicle(v VehicleJSON) internal.Vehicle {
 	newVehicle := internal.Vehicle{
 		Id: v.ID,
@@ -95,14 +87,24 @@ func VehicleJSONToVehicle(v VehicleJSON) internal.Vehicle {
 }
 
 // NewVehicleDefault is a function that returns a new instance of VehicleDefault
-func NewVehicleDefault(sv internal.VehicleService) *VehicleDefault {
-	return &VehicleDefault{sv: sv}
+func NewVehicleDefault(sv internal.VehicleService, vl internal.VehicleValidator) *VehicleDefault {
+	return &VehicleDefault{sv: sv, vl: vl, rs: NewResponder()}
 }
 
 // VehicleDefault is a struct with methods that represent handlers for vehicles
 type VehicleDefault struct {
-	// sv is the service that will be used by the handler
+	// sv is the service that will be used by the handler for the requests without a tenant
 	sv internal.VehicleService
+	// vl is the validator of the vehicles received by the handler
+	vl internal.VehicleValidator
+	// rs is the responder that writes the responses in the format accepted by the client
+	rs *Responder
+}
+
+// service is a method that returns the service of the vehicles for a request: the one of its tenant,
+// which rejects the changes of the fields that the role of the client may not modify, and records the vehicles it changes for the audit log
+func (h *VehicleDefault) service(r *http.Request) internal.VehicleService {
+	return newVehicleAudited(NewVehicleGuarded(serviceFrom(r.Context(), h.sv), FieldRulesFrom(r.Context())), auditFrom(r.Context()))
 }
 
 // GetAll is a method that returns a handler for the route GET /vehicles
@@ -113,19 +115,16 @@ func (h *VehicleDefault) GetAll() http.HandlerFunc {
 
 		// process
 		// - get all vehicles
-		v, err := h.sv.FindAll()
+		v, err := h.service(r).FindAll()
 		if err != nil {
-			response.JSON(w, http.StatusInternalServerError, nil)
+			h.rs.Error(w, r, err)
 			return
 		}
 
 		// response
 		data := DataMap(&v)
 
-		response.JSON(w, http.StatusOK, map[string]any{
-			"message": "success",
-			"data":    data,
-		})
+		h.rs.Vehicles(w, r, http.StatusOK, data)
 	}
 }
 
@@ -135,47 +134,34 @@ func (h *VehicleDefault) Create() http.HandlerFunc {
 		//read into bytes
 		bytes, err := io.ReadAll(r.Body)
 		if err != nil {
-			response.JSON(w, http.StatusBadRequest, map[string]string{
-				"message": ErrBadRequest,
-			})
-			return
-		}
-		bodyMap := map[string]any{}
-		if err = json.Unmarshal(bytes, &bodyMap); err != nil {
-			response.JSON(w, http.StatusBadRequest, map[string]string{
-				"message": ErrBadRequest,
-			})
-			return
-		}
-		//check if all fields have a value
-		err = tools.CheckFieldExistance(bodyMap)
-		if err != nil {
-			response.JSON(w, http.StatusBadRequest, map[string]string{
-				"message": ErrBadRequest + " " + err.Error(),
-			})
+			h.rs.Error(w, r, ErrBadRequest)
 			return
 		}
 
 		vehicle := VehicleJSON{}
 		err = json.Unmarshal(bytes, &vehicle)
 		if err != nil {
-			response.JSON(w, http.StatusBadRequest, map[string]string{
-				"message": ErrBadRequest,
-			})
+			h.rs.Error(w, r, ErrBadRequest)
 			return
 		}
 
-		newVehicle := VehicleJSONToVehicle(vehicle)
+		// measures in the canonical units
+		newVehicle := UnitsFrom(r.Context()).CanonicalVehicle(VehicleJSONToVehicle(vehicle))
 
-		err = h.sv.Create(newVehicle)
+		//check the validation rules
+		err = h.vl.Validate(newVehicle)
 		if err != nil {
-			response.JSON(w, http.StatusConflict, map[string]string{
-				"message": ErrDuplicatedId + " " + err.Error(),
-			})
+			h.rs.Error(w, r, err)
 			return
 		}
-		response.JSON(w, http.StatusCreated, map[string]string{
-			"message": "Vehículo creado exitosamente.",
+
+		err = h.service(r).Create(newVehicle)
+		if err != nil {
+			h.rs.Error(w, r, err)
+			return
+		}
+		h.rs.Respond(w, r, http.StatusCreated, map[string]string{
+			"message": h.rs.Message(r, "vehicle_created"),
 		})
 	}
 }
@@ -188,27 +174,22 @@ func (h *VehicleDefault) GetByColorYear() http.HandlerFunc {
 		year := chi.URLParam(r, "year")
 		yearValue, err := strconv.Atoi(year)
 		if err != nil {
-			response.JSON(w, http.StatusBadRequest, map[string]string{
-				"message": ErrBadCriteria,
-			})
+			h.rs.Error(w, r, ErrBadFilter)
 			return
 		}
 
 		// process
 		// - get vehicles by color and year
-		v, err := h.sv.FindByColorYear(color, yearValue)
+		v, err := h.service(r).FindByColorYear(color, yearValue)
 		if err != nil {
-			response.JSON(w, http.StatusBadRequest, err.Error())
+			h.rs.Error(w, r, err)
 			return
 		}
 
 		data := DataMap(&v)
 
 		// response
-		response.JSON(w, http.StatusOK, map[string]any{
-			"message": "success",
-			"data":    data,
-		})
+		h.rs.Vehicles(w, r, http.StatusOK, data)
 	}
 }
 
@@ -221,34 +202,27 @@ func (h *VehicleDefault) GetByBrandRange() http.HandlerFunc {
 		yearEnd := chi.URLParam(r, "end_year")
 		yearStartValue, err := strconv.Atoi(yearStart)
 		if err != nil {
-			response.JSON(w, http.StatusBadRequest, map[string]string{
-				"message": ErrBadCriteria,
-			})
+			h.rs.Error(w, r, ErrBadFilter)
 			return
 		}
 		yearEndValue, err := strconv.Atoi(yearEnd)
 		if err != nil {
-			response.JSON(w, http.StatusBadRequest, map[string]string{
-				"message": ErrBadCriteria,
-			})
+			h.rs.Error(w, r, ErrBadFilter)
 			return
 		}
 
 		// process
 		// - get vehicles by color and year
-		v, err := h.sv.FindByBrandRange(brand, yearStartValue, yearEndValue)
+		v, err := h.service(r).FindByBrandRange(brand, yearStartValue, yearEndValue)
 		if err != nil {
-			response.JSON(w, http.StatusBadRequest, ErrBadCriteria+" - "+err.Error())
+			h.rs.Error(w, r, err)
 			return
 		}
 
 		data := DataMap(&v)
 
 		// response
-		response.JSON(w, http.StatusOK, map[string]any{
-			"message": "success",
-			"data":    data,
-		})
+		h.rs.Vehicles(w, r, http.StatusOK, data)
 	}
 }
 
@@ -260,119 +234,198 @@ func (h *VehicleDefault) GetAverageSpeed() http.HandlerFunc {
 
 		// process
 		// - get vehicles by color and year
-		avg, err := h.sv.AverageSpeed(brand)
+		avg, err := h.service(r).AverageSpeed(brand)
 		if err != nil {
-			response.JSON(w, http.StatusNotFound, err.Error())
+			h.rs.Error(w, r, err)
 			return
 		}
 
-		data := fmt.Sprintf("%s - average speed is: %.2f mph", brand, avg)
+		u := UnitsFrom(r.Context())
+		data := h.rs.Message(r, "average_speed", brand, u.Speed(avg), u.SpeedUnit())
 
 		// response
-		response.JSON(w, http.StatusOK, map[string]any{
-			"message": "success",
+		h.rs.Respond(w, r, http.StatusOK, map[string]any{
+			"message": h.rs.Message(r, "success"),
 			"data":    data,
 		})
 	}
 }
 
-// CreateBatch is a method that returns a handler for the route POST /vehicles/batch
+// BatchItem is a struct that represents the result of a vehicle of a batch created in partial mode
+type BatchItem struct {
+	// Index is the index of the vehicle in the batch
+	Index int `json:"index"`
+	// ID is the id of the vehicle, if it could be decoded
+	ID int `json:"id,omitempty"`
+	// Status is the HTTP status code of the vehicle: 201 Created, or the status of its problem
+	Status int `json:"status"`
+	// Problem is the problem of a vehicle that was not created
+	Problem *Problem `json:"problem,omitempty"`
+}
+
+// CreateBatch is a method that returns a handler for the route POST /vehicles/batch.
+// By default the batch is atomic and all the failing vehicles are reported, with mode=partial the valid vehicles
+// are created and the result of every vehicle is reported with 207 Multi-Status
 func (h *VehicleDefault) CreateBatch() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		// read into bytes
+		// request
+		// - mode
+		partial := false
+		switch mode := r.URL.Query().Get("mode"); mode {
+		case "", "atomic":
+		case "partial":
+			partial = true
+		default:
+			h.rs.Error(w, r, ErrBadRequest.Wrap(fmt.Errorf("unknown batch mode %q", mode)))
+			return
+		}
+		// - read into bytes
 		bytes, err := io.ReadAll(r.Body)
 		if err != nil {
-			response.JSON(w, http.StatusBadRequest, map[string]string{
-				"message": ErrBadRequest,
-			})
+			h.rs.Error(w, r, ErrBadRequest)
 			return
 		}
-		bodyMap := []map[string]any{}
-		if err = json.Unmarshal(bytes, &bodyMap); err != nil {
-			response.JSON(w, http.StatusBadRequest, map[string]string{
-				"message": ErrBadRequest,
-			})
+		items := []json.RawMessage{}
+		err = json.Unmarshal(bytes, &items)
+		if err != nil {
+			h.rs.Error(w, r, ErrBadRequest)
 			return
 		}
-		// check if all fields have a value
-		for _, vehicle := range bodyMap {
-			err = tools.CheckFieldExistance(vehicle)
-			if err != nil {
-				response.JSON(w, http.StatusBadRequest, map[string]string{
-					"message": ErrBadRequest + " " + err.Error(),
-				})
-				return
+
+		// - decode and check the validation rules of every vehicle, so all the failing ones are reported
+		u := UnitsFrom(r.Context())
+		failed := internal.BatchError{}
+		ids := make([]int, len(items))
+		valid := []internal.Vehicle{}
+		validIndexes := []int{}
+		for i, item := range items {
+			vehicle := VehicleJSON{}
+			if err = json.Unmarshal(item, &vehicle); err != nil {
+				failed[i] = ErrBadRequest.Wrap(err)
+				continue
+			}
+			ids[i] = vehicle.ID
+			newVehicle := u.CanonicalVehicle(VehicleJSONToVehicle(vehicle))
+			if err = h.vl.Validate(newVehicle); err != nil {
+				failed[i] = err
+				continue
 			}
+			valid = append(valid, newVehicle)
+			validIndexes = append(validIndexes, i)
 		}
-		vehicles := []VehicleJSON{}
-		err = json.Unmarshal(bytes, &vehicles)
-		if err != nil {
-			response.JSON(w, http.StatusBadRequest, map[string]string{
-				"message": ErrBadRequest,
-			})
+
+		// process
+		// - create the valid vehicles, in atomic mode only if none failed, but the conflicts are reported anyway
+		err = h.service(r).CreateBatch(valid, partial, !partial && len(failed) > 0)
+		var conflicts internal.BatchError
+		switch {
+		case errors.As(err, &conflicts):
+			for j, conflict := range conflicts {
+				failed[validIndexes[j]] = conflict
+			}
+		case err != nil:
+			h.rs.Error(w, r, err)
 			return
 		}
-		newVehicles := []internal.Vehicle{}
-		for _, vehicle := range vehicles {
-			newVehicle := VehicleJSONToVehicle(vehicle)
-			newVehicles = append(newVehicles, newVehicle)
-		}
-		err = h.sv.CreateBatch(newVehicles)
-		if err != nil {
-			response.JSON(w, http.StatusConflict, map[string]string{
-				"message": ErrDuplicatedId + " " + err.Error(),
+
+		// response
+		if !partial {
+			if len(failed) > 0 {
+				h.rs.Error(w, r, batchFailure(failed))
+				return
+			}
+			h.rs.Respond(w, r, http.StatusCreated, map[string]string{
+				"message": h.rs.Message(r, "vehicles_created"),
 			})
 			return
 		}
-		response.JSON(w, http.StatusCreated, map[string]string{
-			"message": "Vehículo creado exitosamente.",
+
+		results := make([]BatchItem, len(items))
+		summary := map[string]int{"created": 0, "failed": 0}
+		for i := range items {
+			results[i] = BatchItem{Index: i, ID: ids[i], Status: http.StatusCreated}
+			if itemErr, ok := failed[i]; ok {
+				problem := h.rs.Problem(r, itemErr)
+				results[i].Status = problem.Status
+				results[i].Problem = &problem
+				summary["failed"]++
+				continue
+			}
+			summary["created"]++
+		}
+		h.rs.Respond(w, r, http.StatusMultiStatus, map[string]any{
+			"message": h.rs.Message(r, "batch_partial"),
+			"data": map[string]any{
+				"summary": summary,
+				"items":   results,
+			},
 		})
 	}
 }
 
+// batchFailure is a function that returns the error of an atomic batch with failing vehicles, with the field-level
+// details of all of them. Its status is the one of the most basic failure: malformed, invalid or in conflict
+func batchFailure(failed internal.BatchError) *APIError {
+	precedence := []*APIError{ErrBadRequest, ErrValidation, ErrDuplicatedId}
+	rank := len(precedence) - 1
+
+	var fields []ProblemField
+	for _, i := range failed.Indexes() {
+		prefix := fmt.Sprintf("/%d", i)
+		itemErr := ToAPIError(failed[i])
+		for j, apiErr := range precedence {
+			if itemErr.Code == apiErr.Code && j < rank {
+				rank = j
+			}
+		}
+
+		switch {
+		case len(itemErr.Fields) > 0:
+			for _, field := range itemErr.Fields {
+				fields = append(fields, ProblemField{Pointer: prefix + field.Pointer, Message: field.Message})
+			}
+		case errors.Is(failed[i], internal.ErrVehicleAlreadyExists):
+			fields = append(fields, ProblemField{Pointer: prefix + "/id", Message: "already exists"})
+		default:
+			fields = append(fields, ProblemField{Pointer: prefix, Message: itemErr.Error()})
+		}
+	}
+	return precedence[rank].WithFields(fields)
+}
+
 // UpdateSpeed is a method that returns a handler for the route PUT /vehicles/:id/speed
 func (h *VehicleDefault) UpdateSpeed() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		id, err := strconv.Atoi(chi.URLParam(r, "id"))
 		if err != nil {
-			response.JSON(w, http.StatusBadRequest, map[string]string{
-				"message": ErrBadRequest,
-			})
+			h.rs.Error(w, r, ErrBadRequest)
 			return
 		}
 
 		// read into bytes
 		bytes, err := io.ReadAll(r.Body)
 		if err != nil {
-			response.JSON(w, http.StatusBadRequest, map[string]string{
-				"message": ErrBadRequest,
-			})
+			h.rs.Error(w, r, ErrBadRequest)
 			return
 		}
 		bodyMap := map[string]any{}
 		if err = json.Unmarshal(bytes, &bodyMap); err != nil {
-			response.JSON(w, http.StatusBadRequest, map[string]string{
-				"message": ErrBadRequest,
-			})
+			h.rs.Error(w, r, ErrBadRequest)
 			return
 		}
 		newSpeedValue, ok := bodyMap["max_speed"].(float64)
-		if !ok || newSpeedValue <= 0 {
-			response.JSON(w, http.StatusBadRequest, map[string]string{
-				"message": ErrBadSpeed,
-			})
+		if !ok {
+			h.rs.Error(w, r, ErrBadSpeed)
 			return
 		}
+		newSpeedValue = UnitsFrom(r.Context()).CanonicalSpeed(newSpeedValue)
 
-		err = h.sv.UpdateSpeed(id, newSpeedValue)
-		if err != nil {
-			response.JSON(w, http.StatusNotFound, map[string]string{
-				"message": ErrNotFound + " " + err.Error(),
-			})
+		if err = h.updateSpeed(h.service(r), id, newSpeedValue); err != nil {
+			h.rs.Error(w, r, err)
 			return
 		}
-		response.JSON(w, http.StatusOK, map[string]string{
-			"message": "Velocidad del vehículo actualizada exitosamente.",
+		h.rs.Respond(w, r, http.StatusOK, map[string]string{
+			"message": h.rs.Message(r, "speed_updated"),
 		})
 	}
 }
@@ -385,19 +438,16 @@ func (h *VehicleDefault) GetByFuelType() http.HandlerFunc {
 
 		// process
 		// - get vehicles by color and year
-		v, err := h.sv.FindByFuelType(fuelType)
+		v, err := h.service(r).FindByFuelType(fuelType)
 		if err != nil {
-			response.JSON(w, http.StatusNotFound, err.Error())
+			h.rs.Error(w, r, err)
 			return
 		}
 
 		data := DataMap(&v)
 
 		// response
-		response.JSON(w, http.StatusOK, map[string]any{
-			"message": "success",
-			"data":    data,
-		})
+		h.rs.Vehicles(w, r, http.StatusOK, data)
 	}
 }
 
@@ -406,21 +456,17 @@ func (h *VehicleDefault) Delete() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		id, err := strconv.Atoi(chi.URLParam(r, "id"))
 		if err != nil {
-			response.JSON(w, http.StatusBadRequest, map[string]string{
-				"message": ErrBadRequest,
-			})
+			h.rs.Error(w, r, ErrBadRequest)
 			return
 		}
 
-		err = h.sv.Delete(id)
+		err = h.service(r).Delete(id)
 		if err != nil {
-			response.JSON(w, http.StatusNotFound, map[string]string{
-				"message": ErrNotFound + " " + err.Error(),
-			})
+			h.rs.Error(w, r, err)
 			return
 		}
-		response.JSON(w, http.StatusOK, map[string]string{
-			"message": "Vehículo eliminado exitosamente.",
+		h.rs.Respond(w, r, http.StatusOK, map[string]string{
+			"message": h.rs.Message(r, "vehicle_deleted"),
 		})
 	}
 }
@@ -430,45 +476,251 @@ func (h *VehicleDefault) UpdateFuelType() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		id, err := strconv.Atoi(chi.URLParam(r, "id"))
 		if err != nil {
-			response.JSON(w, http.StatusBadRequest, map[string]string{
-				"message": ErrBadRequest,
-			})
+			h.rs.Error(w, r, ErrBadRequest)
 			return
 		}
 
 		// read into bytes
 		bytes, err := io.ReadAll(r.Body)
 		if err != nil {
-			response.JSON(w, http.StatusBadRequest, map[string]string{
-				"message": ErrBadRequest,
-			})
+			h.rs.Error(w, r, ErrBadRequest)
 			return
 		}
 		bodyMap := map[string]any{}
 		if err = json.Unmarshal(bytes, &bodyMap); err != nil {
-			response.JSON(w, http.StatusBadRequest, map[string]string{
-				"message": ErrBadRequest,
-			})
+			h.rs.Error(w, r, ErrBadRequest)
 			return
 		}
 		newFuelTypeValue, ok := bodyMap["fuel_type"].(string)
 		if !ok || newFuelTypeValue == "" {
-			response.JSON(w, http.StatusBadRequest, map[string]string{
-				"message": ErrBadFuelType,
-			})
+			h.rs.Error(w, r, ErrBadFuelType)
+			return
+		}
+
+		if err = h.updateFuelType(h.service(r), id, newFuelTypeValue); err != nil {
+			h.rs.Error(w, r, err)
+			return
+		}
+		h.rs.Respond(w, r, http.StatusOK, map[string]string{
+			"message": h.rs.Message(r, "fuel_type_updated"),
+		})
+	}
+}
+
+// updateSpeed is a method that updates the max speed of a vehicle, in the canonical unit, with a service.
+// The update and the validation of the whole vehicle are atomic, the rules may depend on its other fields
+func (h *VehicleDefault) updateSpeed(sv internal.VehicleService, id int, speed float64) (err error) {
+	_, err = sv.Update(id, func(current internal.Vehicle) (updated internal.Vehicle, err error) {
+		updated = current
+		updated.MaxSpeed = speed
+		if err = h.vl.Validate(updated); err != nil {
+			err = ErrBadSpeed.Wrap(err)
+		}
+		return
+	})
+	return
+}
+
+// updateFuelType is a method that updates the fuel type of a vehicle with a service.
+// The update and the validation of the whole vehicle are atomic, the rules may depend on its other fields
+func (h *VehicleDefault) updateFuelType(sv internal.VehicleService, id int, fuelType string) (err error) {
+	_, err = sv.Update(id, func(current internal.Vehicle) (updated internal.Vehicle, err error) {
+		updated = current
+		updated.FuelType = fuelType
+		if err = h.vl.Validate(updated); err != nil {
+			err = ErrBadFuelType.Wrap(err)
+		}
+		return
+	})
+	return
+}
+
+// GetById is a method that returns a handler for the route GET /vehicles/{id}
+func (h *VehicleDefault) GetById() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			h.rs.Error(w, r, ErrBadRequest)
 			return
 		}
 
-		err = h.sv.UpdateFuelType(id, newFuelTypeValue)
+		// process
+		// - get vehicle by id
+		v, err := h.service(r).FindById(id)
 		if err != nil {
-			response.JSON(w, http.StatusNotFound, map[string]string{
-				"message": ErrNotFound + " " + err.Error(),
-			})
+			h.rs.Error(w, r, err)
 			return
 		}
-		response.JSON(w, http.StatusOK, map[string]string{
-			"message": "Tipo del combustible del vehículo actualizado exitosamente.",
+
+		// response
+		w.Header().Set("Accept-Patch", mediaTypeMergePatch+", "+mediaTypeJSONPatch)
+		h.rs.Vehicle(w, r, http.StatusOK, VehicleToVehicleJSON(v))
+	}
+}
+
+// Replace is a method that returns a handler for the route PUT /vehicles/{id}, that replaces the vehicle or creates it if it does not exist
+func (h *VehicleDefault) Replace() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			h.rs.Error(w, r, ErrBadRequest)
+			return
+		}
+		bytes, err := io.ReadAll(r.Body)
+		if err != nil {
+			h.rs.Error(w, r, ErrBadRequest)
+			return
+		}
+		vehicle := VehicleJSON{}
+		if err = json.Unmarshal(bytes, &vehicle); err != nil {
+			h.rs.Error(w, r, ErrBadRequest)
+			return
+		}
+		// - the id of the body may be omitted, it is the one of the path
+		switch vehicle.ID {
+		case 0:
+			vehicle.ID = id
+		case id:
+		default:
+			h.rs.Error(w, r, ErrBadRequest.Wrap(fmt.Errorf("id %d of the body does not match id %d of the path", vehicle.ID, id)))
+			return
+		}
+
+		// process
+		// - measures in the canonical units
+		newVehicle := UnitsFrom(r.Context()).CanonicalVehicle(VehicleJSONToVehicle(vehicle))
+		// - check the validation rules
+		if err = h.vl.Validate(newVehicle); err != nil {
+			h.rs.Error(w, r, err)
+			return
+		}
+		created, err := h.service(r).Save(newVehicle)
+		if err != nil {
+			h.rs.Error(w, r, err)
+			return
+		}
+
+		// response
+		code := http.StatusOK
+		if created {
+			code = http.StatusCreated
+		}
+		h.rs.Vehicle(w, r, code, VehicleToVehicleJSON(newVehicle))
+	}
+}
+
+// Patch is a method that returns a handler for the route PATCH /vehicles/{id}, that applies a JSON Merge Patch
+// or a JSON Patch, by the content type of the request, to the mutable fields of the vehicle
+func (h *VehicleDefault) Patch() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		id, err := strconv.Atoi(chi.URLParam(r, "id"))
+		if err != nil {
+			h.rs.Error(w, r, ErrBadRequest)
+			return
+		}
+		bytes, err := io.ReadAll(r.Body)
+		if err != nil {
+			h.rs.Error(w, r, ErrBadRequest)
+			return
+		}
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+
+		// process
+		// - patch and validate the current vehicle atomically
+		v, err := h.service(r).Update(id, func(current internal.Vehicle) (patched internal.Vehicle, err error) {
+			patched, err = patchVehicle(current, mediaType, bytes, UnitsFrom(r.Context()))
+			if err != nil {
+				return
+			}
+			err = h.vl.Validate(patched)
+			return
 		})
+		if err != nil {
+			h.rs.Error(w, r, err)
+			return
+		}
+
+		// response
+		h.rs.Vehicle(w, r, http.StatusOK, VehicleToVehicleJSON(v))
+	}
+}
+
+// PatchByFilter is a method that returns a handler for the route PATCH /vehicles, that applies a JSON Merge Patch
+// or a JSON Patch to every vehicle that matches the filter of the query, atomically
+func (h *VehicleDefault) PatchByFilter() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		filter, dryRun, err := bulkFromQuery(r)
+		if err != nil {
+			h.rs.Error(w, r, ErrBadFilter.Wrap(err))
+			return
+		}
+		bytes, err := io.ReadAll(r.Body)
+		if err != nil {
+			h.rs.Error(w, r, ErrBadRequest)
+			return
+		}
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+
+		// process
+		// - patch and validate the matching vehicles, all or none
+		u := UnitsFrom(r.Context())
+		ids, err := h.service(r).UpdateByFilter(filter, func(current internal.Vehicle) (patched internal.Vehicle, err error) {
+			patched, err = patchVehicle(current, mediaType, bytes, u)
+			if err != nil {
+				return
+			}
+			err = h.vl.Validate(patched)
+			return
+		}, dryRun)
+		if err != nil {
+			h.rs.Error(w, r, err)
+			return
+		}
+
+		// response
+		h.rs.Respond(w, r, http.StatusOK, h.bulkResult(r, "vehicles_updated", ids, dryRun))
+	}
+}
+
+// DeleteByFilter is a method that returns a handler for the route DELETE /vehicles, that deletes every vehicle
+// that matches the filter of the query, atomically
+func (h *VehicleDefault) DeleteByFilter() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		filter, dryRun, err := bulkFromQuery(r)
+		if err != nil {
+			h.rs.Error(w, r, ErrBadFilter.Wrap(err))
+			return
+		}
+
+		// process
+		ids, err := h.service(r).DeleteByFilter(filter, dryRun)
+		if err != nil {
+			h.rs.Error(w, r, err)
+			return
+		}
+
+		// response
+		h.rs.Respond(w, r, http.StatusOK, h.bulkResult(r, "vehicles_deleted", ids, dryRun))
+	}
+}
+
+// bulkResult is a method that returns the body of the response of a bulk operation, with the affected ids
+func (h *VehicleDefault) bulkResult(r *http.Request, message string, ids []int, dryRun bool) map[string]any {
+	if dryRun {
+		message = "bulk_simulated"
+	}
+	return map[string]any{
+		"message": h.rs.Message(r, message),
+		"data": map[string]any{
+			"dry_run": dryRun,
+			"count":   len(ids),
+			"ids":     ids,
+		},
 	}
 }
 
@@ -478,51 +730,254 @@ func (h *VehicleDefault) GetByDimensions() http.HandlerFunc {
 		// request
 		length := r.URL.Query().Get("length")
 		width := r.URL.Query().Get("width")
+		if length == "" || width == "" {
+			h.rs.Error(w, r, ErrBadFilter.Wrap(errors.New("length and width are required")))
+			return
+		}
 
-		lengths := strings.Split(length, "-")
-		widths := strings.Split(width, "-")
+		minlength, maxlength, err := parseRange(length)
+		if err != nil {
+			h.rs.Error(w, r, ErrBadFilter.Wrap(err))
+			return
+		}
+		minwidth, maxwidth, err := parseRange(width)
+		if err != nil {
+			h.rs.Error(w, r, ErrBadFilter.Wrap(err))
+			return
+		}
 
-		minlength, err := strconv.ParseFloat(lengths[0], 64)
+		u := UnitsFrom(r.Context())
+		v, err := h.service(r).FindByDimensions(u.CanonicalLength(minlength), u.CanonicalLength(maxlength), u.CanonicalLength(minwidth), u.CanonicalLength(maxwidth))
 		if err != nil {
-			response.JSON(w, http.StatusBadRequest, map[string]string{
-				"message": ErrBadRequest,
-			})
+			h.rs.Error(w, r, err)
+			return
+		}
+
+		data := DataMap(&v)
+
+		h.rs.Vehicles(w, r, http.StatusOK, data)
+
+	}
+}
+
+// exportPageSize is the amount of vehicles read from the repository at once by an export
+const exportPageSize = 500
+
+// Export is a method that returns a handler for the route GET /vehicles/export
+func (h *VehicleDefault) Export() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		format := r.URL.Query().Get("format")
+		if format == "" {
+			// no explicit format, the one accepted by the client
+			format, _ = h.rs.Negotiate(r)
+		}
+		contentType, ok := encodings[format]
+		if !ok {
+			h.rs.Error(w, r, ErrBadFormat)
 			return
 		}
-		maxlength, err := strconv.ParseFloat(lengths[1], 64)
+		filter, err := FilterFromQuery(r, "format")
 		if err != nil {
-			response.JSON(w, http.StatusBadRequest, map[string]string{
-				"message": ErrBadRequest,
-			})
+			h.rs.Error(w, r, ErrBadFilter.Wrap(err))
 			return
 		}
-		minwidth, err := strconv.ParseFloat(widths[0], 64)
+
+		// process
+		// - first page of vehicles, so a failure is still answered with a problem
+		sv := h.service(r)
+		page, err := sv.FindPage(filter, 0, exportPageSize)
 		if err != nil {
-			response.JSON(w, http.StatusBadRequest, map[string]string{
-				"message": ErrBadRequest,
-			})
+			h.rs.Error(w, r, err)
 			return
 		}
-		maxwidth, err := strconv.ParseFloat(widths[1], 64)
+
+		// response: streamed page by page in id order, each page is flushed so it is sent chunked.
+		// Only one page is held in memory, and the repository is not locked while the client reads
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"vehicles.%s\"", format))
+		w.WriteHeader(http.StatusOK)
+
+		enc, _ := NewVehicleEncoder(format, w)
+		flusher, _ := w.(http.Flusher)
+		for len(page) > 0 {
+			for _, v := range page {
+				if err = enc.Encode(h.rs.present(r, VehicleToVehicleJSON(v))); err != nil {
+					// the status is already sent, the client sees a truncated body
+					return
+				}
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+			if len(page) < exportPageSize {
+				break
+			}
+			if page, err = sv.FindPage(filter, page[len(page)-1].Id, exportPageSize); err != nil {
+				return
+			}
+		}
+		enc.Close()
+	}
+}
+
+// ImportRow is a struct that represents the result of a row of an import
+type ImportRow struct {
+	// Row is the number of the record in the file, starting at 1
+	Row int `json:"row"`
+	// ID is the id of the vehicle, if the record could be decoded
+	ID int `json:"id,omitempty"`
+	// Status is the outcome of the row: created, updated, skipped or invalid
+	Status string `json:"status"`
+	// Reason is the reason of an invalid row
+	Reason string `json:"reason,omitempty"`
+}
+
+// Import is a method that returns a handler for the route POST /vehicles/import
+func (h *VehicleDefault) Import() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		// - options and file
+		dec, policy, dryRun, err := importRequest(r)
 		if err != nil {
-			response.JSON(w, http.StatusBadRequest, map[string]string{
-				"message": ErrBadRequest,
-			})
+			h.rs.Error(w, r, err)
 			return
 		}
 
-		v, err := h.sv.FindByDimensions(minlength, maxlength, minwidth, maxwidth)
+		// - records: the invalid ones are reported, the valid ones are imported
+		u := UnitsFrom(r.Context())
+		rows := []ImportRow{}
+		valid := []internal.Vehicle{}
+		validRows := []int{}
+		for row := 1; ; row++ {
+			record, err := dec.Decode()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if errors.Is(err, ErrInvalidRecord) {
+				rows = append(rows, ImportRow{Row: row, Status: "invalid", Reason: err.Error()})
+				continue
+			}
+			if err != nil {
+				h.rs.Error(w, r, ErrBadRequest.Wrap(err))
+				return
+			}
+
+			vehicle, newVehicle, err := importRecord(record, u, h.vl)
+			if err != nil {
+				rows = append(rows, ImportRow{Row: row, ID: vehicle.ID, Status: "invalid", Reason: err.Error()})
+				continue
+			}
+
+			valid = append(valid, newVehicle)
+			validRows = append(validRows, len(rows))
+			rows = append(rows, ImportRow{Row: row, ID: vehicle.ID})
+		}
+
+		// process
+		// - import the valid vehicles
+		outcomes, err := h.service(r).Import(valid, policy, dryRun)
 		if err != nil {
-			response.JSON(w, http.StatusNotFound, err.Error())
+			h.rs.Error(w, r, err)
 			return
 		}
 
-		data := DataMap(&v)
+		// response
+		summary := map[string]int{"created": 0, "updated": 0, "skipped": 0, "invalid": 0}
+		for i, outcome := range outcomes {
+			rows[validRows[i]].Status = string(outcome)
+		}
+		for _, row := range rows {
+			summary[row.Status]++
+		}
 
-		response.JSON(w, http.StatusOK, map[string]any{
-			"message": "success",
-			"data":    data,
+		message := h.rs.Message(r, "import_completed")
+		if dryRun {
+			message = h.rs.Message(r, "import_simulated")
+		}
+		h.rs.Respond(w, r, http.StatusOK, map[string]any{
+			"message": message,
+			"data": map[string]any{
+				"dry_run": dryRun,
+				"summary": summary,
+				"rows":    rows,
+			},
 		})
+	}
+}
+
+// importRequest is a function that reads the options of an import from the query parameters of the request,
+// dry_run and on_conflict, and returns the decoder of its file: the multipart part named "file", in csv or ndjson format
+func importRequest(r *http.Request) (dec VehicleDecoder, policy internal.ImportPolicy, dryRun bool, err error) {
+	// - options
+	if value := r.URL.Query().Get("dry_run"); value != "" {
+		if dryRun, err = strconv.ParseBool(value); err != nil {
+			err = ErrBadRequest.Wrap(fmt.Errorf("dry_run: %w", err))
+			return
+		}
+	}
+	policy = internal.ImportPolicy(r.URL.Query().Get("on_conflict"))
+	switch policy {
+	case "":
+		policy = internal.ImportFail
+	case internal.ImportSkip, internal.ImportOverwrite, internal.ImportFail:
+	default:
+		err = ErrBadPolicy
+		return
+	}
 
+	// - file
+	mr, err := r.MultipartReader()
+	if err != nil {
+		err = ErrBadRequest.Wrap(err)
+		return
+	}
+	for dec == nil {
+		part, errPart := mr.NextPart()
+		if errPart != nil {
+			err = ErrBadRequest.Wrap(errors.New("the request has no file part"))
+			return
+		}
+		if part.FormName() != "file" {
+			continue
+		}
+
+		dec, err = NewVehicleDecoder(importFormat(r.URL.Query().Get("format"), part.FileName(), part.Header.Get("Content-Type")), part)
+		if err != nil {
+			err = ErrBadFormat
+			return
+		}
+	}
+	return
+}
+
+// importRecord is a function that returns the vehicle of an imported record, with its measures in the canonical units,
+// or the reason the record is invalid
+func importRecord(record []byte, u internal.UnitSystem, vl internal.VehicleValidator) (vehicle VehicleJSON, v internal.Vehicle, err error) {
+	if err = json.Unmarshal(record, &vehicle); err != nil {
+		return
+	}
+	v = u.CanonicalVehicle(VehicleJSONToVehicle(vehicle))
+	err = vl.Validate(v)
+	return
+}
+
+// importFormat is a function that returns the format of an imported file:
+// the explicit format if any, otherwise the one of its extension or its content type
+func importFormat(format, fileName, contentType string) string {
+	if format != "" {
+		return format
+	}
+	switch filepath.Ext(fileName) {
+	case ".csv":
+		return "csv"
+	case ".ndjson", ".jsonl":
+		return "ndjson"
+	}
+	for name, ct := range encodings {
+		if strings.HasPrefix(contentType, ct) {
+			return name
+		}
 	}
+	return ""
 }
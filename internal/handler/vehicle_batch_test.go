@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"app/internal"
+	"app/internal/repository"
+	"app/internal/service"
+	"app/internal/validation"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// batchItem is a function that returns the JSON of a vehicle of a batch, invalid if it has no passengers
+func batchItem(id, passengers int) string {
+	return fmt.Sprintf(`{"id": %d, "brand": "Ford", "model": "Focus", "registration": "REG-%d", "color": "red", "year": 2020,
+		"passengers": %d, "max_speed": 200, "fuel_type": "gasoline", "transmission": "manual", "weight": 1300, "height": 150, "width": 180}`,
+		id, id, passengers)
+}
+
+// newBatchHandler is a function that returns a handler and the repository with the vehicle 1
+func newBatchHandler() (hd *VehicleDefault, rp *repository.VehicleMap) {
+	rp = repository.NewVehicleMap(map[int]internal.Vehicle{1: {Id: 1}})
+	hd = NewVehicleDefault(service.NewVehicleDefault(rp), validation.NewVehicleRules(nil))
+	return
+}
+
+func TestVehicleDefault_CreateBatch_Atomic(t *testing.T) {
+	cases := []struct {
+		name     string
+		items    []string
+		status   int
+		pointers []string
+		count    int
+	}{
+		{name: "valid", items: []string{batchItem(2, 5), batchItem(3, 5)}, status: http.StatusCreated, count: 3},
+		{name: "every failure reported", items: []string{batchItem(2, 5), batchItem(3, 0), batchItem(1, 5), `"car"`},
+			status: http.StatusBadRequest, pointers: []string{"/1/passengers", "/2/id", "/3"}, count: 1},
+		{name: "conflict", items: []string{batchItem(1, 5)}, status: http.StatusConflict, pointers: []string{"/0/id"}, count: 1},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			hd, rp := newBatchHandler()
+
+			body := "[" + strings.Join(c.items, ",") + "]"
+			res := httptest.NewRecorder()
+			hd.CreateBatch()(res, httptest.NewRequest(http.MethodPost, "/vehicles/batch", strings.NewReader(body)))
+			if res.Code != c.status {
+				t.Fatalf("status = %d, want %d: %s", res.Code, c.status, res.Body)
+			}
+			if c.pointers != nil {
+				var problem Problem
+				if err := json.Unmarshal(res.Body.Bytes(), &problem); err != nil {
+					t.Fatalf("problem: %v", err)
+				}
+				if len(problem.Errors) != len(c.pointers) {
+					t.Fatalf("errors = %+v, want %v", problem.Errors, c.pointers)
+				}
+				for i, pointer := range c.pointers {
+					if problem.Errors[i].Pointer != pointer {
+						t.Fatalf("errors = %+v, want %v", problem.Errors, c.pointers)
+					}
+				}
+			}
+			if v, _ := rp.FindAll(); len(v) != c.count {
+				t.Fatalf("repository has %d vehicles, want %d", len(v), c.count)
+			}
+		})
+	}
+}
+
+func TestVehicleDefault_CreateBatch_Partial(t *testing.T) {
+	hd, rp := newBatchHandler()
+
+	body := "[" + strings.Join([]string{batchItem(2, 5), batchItem(3, 0), batchItem(1, 5)}, ",") + "]"
+	res := httptest.NewRecorder()
+	hd.CreateBatch()(res, httptest.NewRequest(http.MethodPost, "/vehicles/batch?mode=partial", strings.NewReader(body)))
+	if res.Code != http.StatusMultiStatus {
+		t.Fatalf("status = %d, want %d: %s", res.Code, http.StatusMultiStatus, res.Body)
+	}
+	var result struct {
+		Data struct {
+			Summary map[string]int `json:"summary"`
+			Items   []BatchItem    `json:"items"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(res.Body.Bytes(), &result); err != nil {
+		t.Fatalf("body: %v", err)
+	}
+	statuses := []int{http.StatusCreated, http.StatusUnprocessableEntity, http.StatusConflict}
+	for i, status := range statuses {
+		if item := result.Data.Items[i]; item.Status != status || (status != http.StatusCreated) != (item.Problem != nil) {
+			t.Fatalf("item %d = %+v, want the status %d", i, item, status)
+		}
+	}
+	if result.Data.Summary["created"] != 1 || result.Data.Summary["failed"] != 2 {
+		t.Fatalf("summary = %v, want 1 created and 2 failed", result.Data.Summary)
+	}
+	if v, _ := rp.FindAll(); len(v) != 2 {
+		t.Fatalf("repository has %d vehicles, want 2", len(v))
+	}
+
+	// - unknown mode
+	res = httptest.NewRecorder()
+	hd.CreateBatch()(res, httptest.NewRequest(http.MethodPost, "/vehicles/batch?mode=best_effort", strings.NewReader("[]")))
+	if res.Code != http.StatusBadRequest {
+		t.Fatalf("unknown mode: status = %d, want %d", res.Code, http.StatusBadRequest)
+	}
+}
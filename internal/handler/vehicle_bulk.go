@@ -0,0 +1,247 @@
+package handler
+
+import (
+	"app/internal"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// BulkOperation is a struct that represents an operation of a bulk request
+type BulkOperation struct {
+	// Op is the operation: create, update, replace, delete, speed or fuel_type
+	Op string `json:"op"`
+	// ID is the id of the vehicle of update, replace, delete, speed and fuel_type
+	ID int `json:"id,omitempty"`
+	// Vehicle is the vehicle of create and replace
+	Vehicle *VehicleJSON `json:"vehicle,omitempty"`
+	// Patch is the JSON Merge Patch of update
+	Patch json.RawMessage `json:"patch,omitempty"`
+	// MaxSpeed is the max speed of speed, in the unit system of the request
+	MaxSpeed *float64 `json:"max_speed,omitempty"`
+	// FuelType is the fuel type of fuel_type
+	FuelType string `json:"fuel_type,omitempty"`
+}
+
+// BulkResult is a struct that represents the result of an operation of a bulk request
+type BulkResult struct {
+	// Index is the index of the operation in the request
+	Index int `json:"index"`
+	// Op is the operation
+	Op string `json:"op"`
+	// ID is the id of the vehicle of the operation
+	ID int `json:"id,omitempty"`
+	// Status is the HTTP status code of the operation: the one of the equivalent single request, or the status of its problem
+	Status int `json:"status"`
+	// Problem is the problem of a failed operation
+	Problem *Problem `json:"problem,omitempty"`
+}
+
+// Bulk is a method that returns a handler for the route POST /vehicles/bulk, that runs an ordered list of operations.
+// By default they run in a transaction, that is applied only if all of them succeed; with mode=partial every operation
+// runs on its own and the result of each one is reported with 207 Multi-Status
+func (h *VehicleDefault) Bulk() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// request
+		// - mode
+		partial := false
+		switch mode := r.URL.Query().Get("mode"); mode {
+		case "", "atomic":
+		case "partial":
+			partial = true
+		default:
+			h.rs.Error(w, r, ErrBadRequest.Wrap(fmt.Errorf("unknown bulk mode %q", mode)))
+			return
+		}
+		// - operations
+		bytes, err := io.ReadAll(r.Body)
+		if err != nil {
+			h.rs.Error(w, r, ErrBadRequest)
+			return
+		}
+		ops := []BulkOperation{}
+		if err = json.Unmarshal(bytes, &ops); err != nil {
+			h.rs.Error(w, r, ErrBadRequest.Wrap(err))
+			return
+		}
+		// - deletions require the scope of the route DELETE /vehicles/{id}
+		for _, op := range ops {
+			if op.Op != "delete" {
+				continue
+			}
+			if err = checkScope(r.Context(), internal.ScopeAdmin); err != nil {
+				h.rs.Error(w, r, err)
+				return
+			}
+			break
+		}
+
+		// process
+		u := UnitsFrom(r.Context())
+		results := make([]BulkResult, len(ops))
+		for i, op := range ops {
+			results[i] = BulkResult{Index: i, Op: op.Op, ID: op.ID}
+		}
+
+		if partial {
+			// - every operation on its own
+			summary := map[string]int{"succeeded": 0, "failed": 0}
+			for i, op := range ops {
+				results[i].ID, results[i].Status, err = h.runOperation(h.service(r), op, u)
+				if err != nil {
+					problem := h.rs.Problem(r, err)
+					results[i].Status = problem.Status
+					results[i].Problem = &problem
+					summary["failed"]++
+					continue
+				}
+				summary["succeeded"]++
+			}
+
+			// response
+			h.rs.Respond(w, r, http.StatusMultiStatus, map[string]any{
+				"message": h.rs.Message(r, "bulk_partial"),
+				"data": map[string]any{
+					"summary": summary,
+					"results": results,
+				},
+			})
+			return
+		}
+
+		// - all the operations in a transaction, stopping at the first failure
+		failed := -1
+		err = h.service(r).Transaction(func(sv internal.VehicleService) (err error) {
+			for i, op := range ops {
+				results[i].ID, results[i].Status, err = h.runOperation(sv, op, u)
+				if err != nil {
+					failed = i
+					return
+				}
+			}
+			return
+		})
+		if err != nil {
+			if failed < 0 {
+				h.rs.Error(w, r, err)
+				return
+			}
+			// the problem of the failed operation, with its pointers relative to the operation
+			problem := h.rs.Problem(r, err)
+			prefix := fmt.Sprintf("/%d", failed)
+			for j := range problem.Errors {
+				problem.Errors[j].Pointer = prefix + problem.Errors[j].Pointer
+			}
+			if len(problem.Errors) == 0 {
+				message := problem.Detail
+				if message == "" {
+					message = problem.Title
+				}
+				problem.Errors = []ProblemField{{Pointer: prefix, Message: message}}
+			}
+			h.rs.WriteProblem(w, problem)
+			return
+		}
+
+		// response
+		h.rs.Respond(w, r, http.StatusOK, map[string]any{
+			"message": h.rs.Message(r, "bulk_completed"),
+			"data": map[string]any{
+				"results": results,
+			},
+		})
+	}
+}
+
+// runOperation is a method that runs an operation of a bulk request with a service, as its single request does,
+// returning the id of the vehicle and the status code of the equivalent single request.
+// speed and fuel_type are the ones of PATCH /vehicles/{id}/update_speed and PATCH /vehicles/{id}/update_fuel
+func (h *VehicleDefault) runOperation(sv internal.VehicleService, op BulkOperation, u internal.UnitSystem) (id, status int, err error) {
+	id = op.ID
+	switch op.Op {
+	case "create", "replace":
+		if op.Vehicle == nil {
+			err = ErrBadRequest.Wrap(fmt.Errorf("%s requires a vehicle", op.Op))
+			return
+		}
+		vehicle := *op.Vehicle
+		if op.Op == "replace" {
+			// the id of the vehicle may be omitted, it is the one of the operation
+			switch vehicle.ID {
+			case 0:
+				vehicle.ID = op.ID
+			case op.ID:
+			default:
+				err = ErrBadRequest.Wrap(fmt.Errorf("id %d of the vehicle does not match id %d of the operation", vehicle.ID, op.ID))
+				return
+			}
+		}
+		id = vehicle.ID
+
+		// - measures in the canonical units
+		newVehicle := u.CanonicalVehicle(VehicleJSONToVehicle(vehicle))
+		if err = h.vl.Validate(newVehicle); err != nil {
+			return
+		}
+		if op.Op == "create" {
+			if err = sv.Create(newVehicle); err != nil {
+				return
+			}
+			status = http.StatusCreated
+			return
+		}
+		created, errSave := sv.Save(newVehicle)
+		if err = errSave; err != nil {
+			return
+		}
+		status = http.StatusOK
+		if created {
+			status = http.StatusCreated
+		}
+	case "update":
+		if len(op.Patch) == 0 {
+			err = ErrBadRequest.Wrap(errors.New("update requires a patch"))
+			return
+		}
+		_, err = sv.Update(op.ID, func(current internal.Vehicle) (patched internal.Vehicle, err error) {
+			patched, err = patchVehicle(current, mediaTypeMergePatch, op.Patch, u)
+			if err != nil {
+				return
+			}
+			err = h.vl.Validate(patched)
+			return
+		})
+		if err != nil {
+			return
+		}
+		status = http.StatusOK
+	case "delete":
+		if err = sv.Delete(op.ID); err != nil {
+			return
+		}
+		status = http.StatusNoContent
+	case "speed":
+		if op.MaxSpeed == nil {
+			err = ErrBadSpeed.Wrap(errors.New("speed requires a max_speed"))
+			return
+		}
+		if err = h.updateSpeed(sv, op.ID, u.CanonicalSpeed(*op.MaxSpeed)); err != nil {
+			return
+		}
+		status = http.StatusOK
+	case "fuel_type":
+		if op.FuelType == "" {
+			err = ErrBadFuelType.Wrap(errors.New("fuel_type requires a fuel_type"))
+			return
+		}
+		if err = h.updateFuelType(sv, op.ID, op.FuelType); err != nil {
+			return
+		}
+		status = http.StatusOK
+	default:
+		err = ErrBadRequest.Wrap(fmt.Errorf("unknown operation %q", op.Op))
+	}
+	return
+}
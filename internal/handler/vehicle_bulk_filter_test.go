@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"app/internal"
+	"app/internal/repository"
+	"app/internal/service"
+	"app/internal/validation"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newBulkFleet is a function that returns a handler and the repository with the vehicles 1 to 4,
+// the odd ones are red Fords and the even ones blue Fiats
+func newBulkFleet() (hd *VehicleDefault, rp *repository.VehicleMap) {
+	db := make(map[int]internal.Vehicle)
+	for id := 1; id <= 4; id++ {
+		v := internal.Vehicle{Id: id}
+		v.Brand, v.Model, v.Registration, v.Color = "Ford", "Focus", "REG-1", "red"
+		if id%2 == 0 {
+			v.Brand, v.Model, v.Color = "Fiat", "Punto", "blue"
+		}
+		v.FabricationYear, v.Capacity, v.MaxSpeed = 2015+id, 5, 180
+		v.FuelType, v.Transmission = "gasoline", "manual"
+		v.Weight, v.Height, v.Width = 1200, 150, 170
+		db[id] = v
+	}
+	rp = repository.NewVehicleMap(db)
+	hd = NewVehicleDefault(service.NewVehicleDefault(rp), validation.NewVehicleRules(nil))
+	return
+}
+
+// bulkIds is a function that returns the ids of the body of a bulk operation
+func bulkIds(t *testing.T, res *httptest.ResponseRecorder) []int {
+	t.Helper()
+	var body struct {
+		Data struct {
+			Ids []int `json:"ids"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(res.Body.Bytes(), &body); err != nil {
+		t.Fatalf("body: %v", err)
+	}
+	return body.Data.Ids
+}
+
+func TestFilterFromQuery(t *testing.T) {
+	cases := []struct {
+		query string
+		want  internal.VehicleFilter
+		ok    bool
+	}{
+		{query: "brand=Ford&year=2020", want: internal.VehicleFilter{Brand: "Ford", Year: 2020}, ok: true},
+		{query: "start_year=2010&end_year=2015", want: internal.VehicleFilter{StartYear: 2010, EndYear: 2015}, ok: true},
+		{query: "length=100-200&width=50-60", want: internal.VehicleFilter{MinLength: 100, MaxLength: 200, MinWidth: 50, MaxWidth: 60}, ok: true},
+		{query: "year=new"},
+		{query: "length=100"},
+		{query: "width=a-b"},
+		{query: "units=imperial&brand=Ford", want: internal.VehicleFilter{Brand: "Ford"}, ok: true},
+		{query: "brnd=Ford"},
+		{query: "dry_run=true&brand=Ford"},
+	}
+	for _, c := range cases {
+		t.Run(c.query, func(t *testing.T) {
+			f, err := FilterFromQuery(httptest.NewRequest(http.MethodGet, "/vehicles?"+c.query, nil))
+			if (err == nil) != c.ok || (c.ok && f != c.want) {
+				t.Fatalf("filter = %+v, err = %v, want %+v", f, err, c.want)
+			}
+		})
+	}
+}
+
+func TestVehicleDefault_PatchByFilter(t *testing.T) {
+	cases := []struct {
+		name   string
+		target string
+		body   string
+		status int
+		ids    []int
+		color  string
+	}{
+		{name: "patch", target: "/vehicles?brand=Ford", body: `{"color": "green"}`, status: http.StatusOK, ids: []int{1, 3}, color: "green"},
+		{name: "dry run", target: "/vehicles?brand=Ford&dry_run=true", body: `{"color": "green"}`, status: http.StatusOK, ids: []int{1, 3}, color: "red"},
+		{name: "all or none", target: "/vehicles?brand=Ford", body: `{"passengers": 0}`, status: http.StatusUnprocessableEntity, color: "red"},
+		{name: "empty filter", target: "/vehicles", body: `{"color": "green"}`, status: http.StatusBadRequest, color: "red"},
+		{name: "unknown parameter", target: "/vehicles?brand=Ford&colour=red", body: `{"color": "green"}`, status: http.StatusBadRequest, color: "red"},
+		{name: "malformed all", target: "/vehicles?all=yes", body: `{"color": "green"}`, status: http.StatusBadRequest, color: "red"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			hd, rp := newBulkFleet()
+
+			req := httptest.NewRequest(http.MethodPatch, c.target, strings.NewReader(c.body))
+			req.Header.Set("Content-Type", mediaTypeMergePatch)
+			res := httptest.NewRecorder()
+			hd.PatchByFilter()(res, req)
+			if res.Code != c.status {
+				t.Fatalf("status = %d, want %d: %s", res.Code, c.status, res.Body)
+			}
+			if c.ids != nil {
+				if ids := bulkIds(t, res); len(ids) != len(c.ids) || ids[0] != c.ids[0] || ids[1] != c.ids[1] {
+					t.Fatalf("ids = %v, want %v", ids, c.ids)
+				}
+			}
+			v, _ := rp.FindAll()
+			if v[1].Color != c.color || v[3].Color != c.color || v[2].Color != "blue" {
+				t.Fatalf("colors = %s %s %s, want %s for the Fords only", v[1].Color, v[2].Color, v[3].Color, c.color)
+			}
+		})
+	}
+}
+
+func TestVehicleDefault_DeleteByFilter(t *testing.T) {
+	hd, rp := newBulkFleet()
+
+	// - dry run
+	res := httptest.NewRecorder()
+	hd.DeleteByFilter()(res, httptest.NewRequest(http.MethodDelete, "/vehicles?color=blue&dry_run=true", nil))
+	if v, _ := rp.FindAll(); res.Code != http.StatusOK || len(bulkIds(t, res)) != 2 || len(v) != 4 {
+		t.Fatalf("dry run: status = %d, %d vehicles left, want %d and 4", res.Code, len(v), http.StatusOK)
+	}
+
+	// - delete
+	res = httptest.NewRecorder()
+	hd.DeleteByFilter()(res, httptest.NewRequest(http.MethodDelete, "/vehicles?color=blue", nil))
+	if v, _ := rp.FindAll(); res.Code != http.StatusOK || len(v) != 2 || v[1].Brand != "Ford" || v[3].Brand != "Ford" {
+		t.Fatalf("delete: status = %d, vehicles = %v, want only the Fords", res.Code, v)
+	}
+
+	// - empty filter, or a criterion that is not known, such as a typo, would delete every vehicle
+	for _, target := range []string{"/vehicles", "/vehicles?all=false", "/vehicles?colour=red"} {
+		res = httptest.NewRecorder()
+		hd.DeleteByFilter()(res, httptest.NewRequest(http.MethodDelete, target, nil))
+		if v, _ := rp.FindAll(); res.Code != http.StatusBadRequest || len(v) != 2 {
+			t.Fatalf("%s: status = %d, %d vehicles left, want %d and 2", target, res.Code, len(v), http.StatusBadRequest)
+		}
+	}
+
+	// - every vehicle, explicitly
+	res = httptest.NewRecorder()
+	hd.DeleteByFilter()(res, httptest.NewRequest(http.MethodDelete, "/vehicles?all=true", nil))
+	if v, _ := rp.FindAll(); res.Code != http.StatusOK || len(v) != 0 {
+		t.Fatalf("all: status = %d, %d vehicles left, want %d and none", res.Code, len(v), http.StatusOK)
+	}
+}
@@ -0,0 +1,181 @@
+package handler
+
+import (
+	"app/internal"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestVehicleDefault_Bulk_Atomic(t *testing.T) {
+	cases := []struct {
+		name     string
+		ops      string
+		status   int
+		statuses []int
+		pointer  string
+		count    int
+		color    string
+	}{
+		{name: "all succeed", ops: `[
+			{"op": "create", "vehicle": ` + batchItem(5, 5) + `},
+			{"op": "update", "id": 1, "patch": {"color": "green"}},
+			{"op": "replace", "id": 2, "vehicle": ` + batchItem(2, 4) + `},
+			{"op": "delete", "id": 3}]`,
+			status: http.StatusOK, statuses: []int{http.StatusCreated, http.StatusOK, http.StatusOK, http.StatusNoContent}, count: 4, color: "green"},
+		{name: "rolled back", ops: `[
+			{"op": "update", "id": 1, "patch": {"color": "green"}},
+			{"op": "delete", "id": 3},
+			{"op": "update", "id": 2, "patch": {"passengers": 0}}]`,
+			status: http.StatusUnprocessableEntity, pointer: "/2/passengers", count: 4, color: "red"},
+		{name: "not found", ops: `[{"op": "delete", "id": 3}, {"op": "delete", "id": 9}]`,
+			status: http.StatusNotFound, pointer: "/1", count: 4, color: "red"},
+		{name: "unknown operation", ops: `[{"op": "upsert", "id": 1}]`, status: http.StatusBadRequest, pointer: "/0", count: 4, color: "red"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			hd, rp := newBulkFleet()
+
+			res := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/vehicles/bulk", strings.NewReader(c.ops))
+			hd.Bulk()(res, withPrincipal(req, internal.ScopeAdmin))
+			if res.Code != c.status {
+				t.Fatalf("status = %d, want %d: %s", res.Code, c.status, res.Body)
+			}
+			if c.statuses != nil {
+				var body struct {
+					Data struct {
+						Results []BulkResult `json:"results"`
+					} `json:"data"`
+				}
+				if err := json.Unmarshal(res.Body.Bytes(), &body); err != nil {
+					t.Fatalf("body: %v", err)
+				}
+				for i, status := range c.statuses {
+					if body.Data.Results[i].Status != status {
+						t.Fatalf("results = %+v, want the statuses %v", body.Data.Results, c.statuses)
+					}
+				}
+			}
+			if c.pointer != "" {
+				var problem Problem
+				if err := json.Unmarshal(res.Body.Bytes(), &problem); err != nil {
+					t.Fatalf("problem: %v", err)
+				}
+				if len(problem.Errors) != 1 || problem.Errors[0].Pointer != c.pointer {
+					t.Fatalf("errors = %+v, want %s", problem.Errors, c.pointer)
+				}
+			}
+			v, _ := rp.FindAll()
+			if len(v) != c.count || v[1].Color != c.color {
+				t.Fatalf("repository has %d vehicles and the vehicle 1 %s, want %d and %s", len(v), v[1].Color, c.count, c.color)
+			}
+		})
+	}
+}
+
+func TestVehicleDefault_Bulk_Partial(t *testing.T) {
+	hd, rp := newBulkFleet()
+
+	ops := `[
+		{"op": "update", "id": 1, "patch": {"color": "green"}},
+		{"op": "update", "id": 2, "patch": {"passengers": 0}},
+		{"op": "create", "vehicle": ` + batchItem(3, 5) + `},
+		{"op": "delete", "id": 4}]`
+	res := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/vehicles/bulk?mode=partial", strings.NewReader(ops))
+	hd.Bulk()(res, withPrincipal(req, internal.ScopeAdmin))
+	if res.Code != http.StatusMultiStatus {
+		t.Fatalf("status = %d, want %d: %s", res.Code, http.StatusMultiStatus, res.Body)
+	}
+	var body struct {
+		Data struct {
+			Summary map[string]int `json:"summary"`
+			Results []BulkResult   `json:"results"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(res.Body.Bytes(), &body); err != nil {
+		t.Fatalf("body: %v", err)
+	}
+	statuses := []int{http.StatusOK, http.StatusUnprocessableEntity, http.StatusConflict, http.StatusNoContent}
+	for i, status := range statuses {
+		if result := body.Data.Results[i]; result.Status != status || (result.Problem != nil) != (status >= 400) {
+			t.Fatalf("result %d = %+v, want the status %d", i, result, status)
+		}
+	}
+	if body.Data.Summary["succeeded"] != 2 || body.Data.Summary["failed"] != 2 {
+		t.Fatalf("summary = %v, want 2 succeeded and 2 failed", body.Data.Summary)
+	}
+	if v, _ := rp.FindAll(); len(v) != 3 || v[1].Color != "green" || v[2].Capacity != 5 {
+		t.Fatalf("vehicles = %+v, want the independent operations applied", v)
+	}
+}
+
+func TestVehicleDefault_Bulk_Scope(t *testing.T) {
+	hd, rp := newBulkFleet()
+
+	// - deletions require the admin scope, even among other operations
+	ops := `[{"op": "update", "id": 1, "patch": {"color": "green"}}, {"op": "delete", "id": 3}]`
+	req := httptest.NewRequest(http.MethodPost, "/vehicles/bulk", strings.NewReader(ops))
+	res := httptest.NewRecorder()
+	hd.Bulk()(res, withPrincipal(req, internal.ScopeWrite))
+	if res.Code != http.StatusForbidden {
+		t.Fatalf("status = %d, want %d: %s", res.Code, http.StatusForbidden, res.Body)
+	}
+	if v, _ := rp.FindAll(); len(v) != 4 || v[1].Color != "red" {
+		t.Fatalf("vehicles = %+v, want none changed", v)
+	}
+}
+
+func TestVehicleDefault_Bulk_SpeedAndFuelType(t *testing.T) {
+	hd, rp := newBulkFleet()
+
+	// - the same validation of PATCH /vehicles/{id}/update_speed and PATCH /vehicles/{id}/update_fuel
+	ops := `[
+		{"op": "speed", "id": 1, "max_speed": 160.9344},
+		{"op": "speed", "id": 2, "max_speed": -5},
+		{"op": "speed", "id": 2},
+		{"op": "fuel_type", "id": 3, "fuel_type": "electric"},
+		{"op": "fuel_type", "id": 4, "fuel_type": "steam"},
+		{"op": "speed", "id": 9, "max_speed": 100}]`
+	res := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/vehicles/bulk?mode=partial", strings.NewReader(ops))
+	hd.Bulk()(res, withPrincipal(req, internal.ScopeWrite))
+	if res.Code != http.StatusMultiStatus {
+		t.Fatalf("status = %d, want %d: %s", res.Code, http.StatusMultiStatus, res.Body)
+	}
+	var body struct {
+		Data struct {
+			Results []BulkResult `json:"results"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(res.Body.Bytes(), &body); err != nil {
+		t.Fatalf("body: %v", err)
+	}
+	want := []struct {
+		status int
+		code   string
+	}{
+		{status: http.StatusOK},
+		{status: http.StatusBadRequest, code: "invalid_speed"},
+		{status: http.StatusBadRequest, code: "invalid_speed"},
+		{status: http.StatusOK},
+		{status: http.StatusBadRequest, code: "invalid_fuel_type"},
+		{status: http.StatusNotFound, code: "vehicle_not_found"},
+	}
+	for i, w := range want {
+		result := body.Data.Results[i]
+		if result.Status != w.status || (w.code != "" && (result.Problem == nil || result.Problem.Code != w.code)) {
+			t.Fatalf("result %d = %+v, want %d %s", i, result, w.status, w.code)
+		}
+	}
+
+	// - the speed is received in the unit system of the request, 160.9344 km/h are 100 mph
+	v, _ := rp.FindAll()
+	if math.Abs(v[1].MaxSpeed-100) > 1e-9 || v[2].MaxSpeed != 180 || v[3].FuelType != "electric" || v[4].FuelType != "gasoline" {
+		t.Fatalf("vehicles = %+v, want only the valid operations applied", v)
+	}
+}
@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"app/internal/service"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestVehicleDefault_GetByDimensions(t *testing.T) {
+	// the vehicle 1 is 150 cm high and 180 cm wide, without length
+	_, rp := newVehicleRouter(t, false)
+	hd := NewVehicleDefault(service.NewVehicleDefault(rp), nil)
+
+	cases := []struct {
+		query  string
+		status int
+	}{
+		{query: "length=0-100&width=150-200", status: http.StatusOK},
+		{query: "length=1&width=150-200", status: http.StatusBadRequest},
+		{query: "length=0-100&width=150", status: http.StatusBadRequest},
+		{query: "length=a-b&width=150-200", status: http.StatusBadRequest},
+		{query: "length=0-100&width=150-", status: http.StatusBadRequest},
+		{query: "width=150-200", status: http.StatusBadRequest},
+		{query: "", status: http.StatusBadRequest},
+	}
+	for _, c := range cases {
+		t.Run(c.query, func(t *testing.T) {
+			res := httptest.NewRecorder()
+			hd.GetByDimensions()(res, httptest.NewRequest(http.MethodGet, "/vehicles/dimensions?"+c.query, nil))
+			if res.Code != c.status {
+				t.Fatalf("status = %d, want %d: %s", res.Code, c.status, res.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"app/internal"
+	"app/internal/repository"
+	"app/internal/service"
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestVehicleDefault_Export_Formats(t *testing.T) {
+	// the vehicles 1 to 6, the odd ones are of the brand Ford
+	db := make(map[int]internal.Vehicle)
+	for id := 1; id <= 6; id++ {
+		v := internal.Vehicle{Id: id}
+		v.Brand = "Fiat"
+		if id%2 == 1 {
+			v.Brand = "Ford"
+		}
+		db[id] = v
+	}
+	hd := NewVehicleDefault(service.NewVehicleDefault(repository.NewVehicleMap(db)), nil)
+
+	// decoders read the ids of the exported vehicles of each format
+	decoders := map[string]func(body io.Reader) ([]int, error){
+		"json": func(body io.Reader) (ids []int, err error) {
+			var v []VehicleJSON
+			err = json.NewDecoder(body).Decode(&v)
+			for _, vh := range v {
+				ids = append(ids, vh.ID)
+			}
+			return
+		},
+		"ndjson": func(body io.Reader) (ids []int, err error) {
+			sc := bufio.NewScanner(body)
+			for sc.Scan() {
+				var vh VehicleJSON
+				if err = json.Unmarshal(sc.Bytes(), &vh); err != nil {
+					return
+				}
+				ids = append(ids, vh.ID)
+			}
+			return
+		},
+		"csv": func(body io.Reader) (ids []int, err error) {
+			rows, err := csv.NewReader(body).ReadAll()
+			if err != nil || len(rows) == 0 || rows[0][0] != "id" {
+				return
+			}
+			for _, row := range rows[1:] {
+				id, _ := strconv.Atoi(row[0])
+				ids = append(ids, id)
+			}
+			return
+		},
+		"xml": func(body io.Reader) (ids []int, err error) {
+			var v struct {
+				Vehicles []struct {
+					ID int `xml:"id"`
+				} `xml:"vehicle"`
+			}
+			err = xml.NewDecoder(body).Decode(&v)
+			for _, vh := range v.Vehicles {
+				ids = append(ids, vh.ID)
+			}
+			return
+		},
+	}
+	for format, decode := range decoders {
+		t.Run(format, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/vehicles/export?format="+format+"&brand=Ford", nil)
+			res := httptest.NewRecorder()
+			hd.Export()(res, req)
+
+			if res.Code != http.StatusOK || res.Header().Get("Content-Type") != encodings[format] {
+				t.Fatalf("status = %d, content type = %q, want %d and %q", res.Code, res.Header().Get("Content-Type"), http.StatusOK, encodings[format])
+			}
+			ids, err := decode(res.Body)
+			if err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if len(ids) != 3 || ids[0] != 1 || ids[1] != 3 || ids[2] != 5 {
+				t.Fatalf("ids = %v, want the vehicles of Ford in id order", ids)
+			}
+		})
+	}
+
+	// - unknown format
+	res := httptest.NewRecorder()
+	hd.Export()(res, httptest.NewRequest(http.MethodGet, "/vehicles/export?format=yaml", nil))
+	if res.Code != http.StatusBadRequest {
+		t.Fatalf("yaml: status = %d, want %d", res.Code, http.StatusBadRequest)
+	}
+}
+
+func TestVehicleDefault_Export(t *testing.T) {
+	// - more vehicles than a page, so the export reads several pages
+	n := 2*exportPageSize + 7
+	db := make(map[int]internal.Vehicle, n)
+	for id := 1; id <= n; id++ {
+		v := internal.Vehicle{Id: id}
+		v.Brand = "Ford"
+		db[id] = v
+	}
+	hd := NewVehicleDefault(service.NewVehicleDefault(repository.NewVehicleMap(db)), nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/vehicles/export?format=ndjson&brand=Ford", nil)
+	res := httptest.NewRecorder()
+	hd.Export()(res, req)
+
+	if res.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", res.Code, http.StatusOK)
+	}
+	sc := bufio.NewScanner(res.Body)
+	last := 0
+	for sc.Scan() {
+		var v VehicleJSON
+		if err := json.Unmarshal(sc.Bytes(), &v); err != nil {
+			t.Fatalf("line %d: %v", last+1, err)
+		}
+		if v.ID != last+1 {
+			t.Fatalf("id = %d after %d, want the vehicles in id order", v.ID, last)
+		}
+		last = v.ID
+	}
+	if last != n {
+		t.Fatalf("exported %d vehicles, want %d", last, n)
+	}
+}
@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"app/internal"
+	"app/internal/repository"
+	"app/internal/service"
+	"app/internal/validation"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// prius is the body of a valid vehicle 1, without its id
+const prius = `"brand": "Toyota", "model": "Prius", "registration": "ABC-123", "color": "red", "year": 2020, "passengers": 5,
+	"max_speed": 180, "fuel_type": "hybrid", "transmission": "automatic", "weight": 1400, "height": 150, "width": 180`
+
+// newVehicleRouter is a function that returns a router with the routes of /vehicles/{id},
+// and the repository with the vehicle 1 if it is not empty
+func newVehicleRouter(t *testing.T, empty bool) (rt *chi.Mux, rp *repository.VehicleMap) {
+	t.Helper()
+	db := make(map[int]internal.Vehicle)
+	if !empty {
+		v := internal.Vehicle{Id: 1}
+		v.Brand, v.Model, v.Registration, v.Color = "Toyota", "Prius", "ABC-123", "red"
+		v.FabricationYear, v.Capacity, v.MaxSpeed = 2020, 5, 180
+		v.FuelType, v.Transmission = "hybrid", "automatic"
+		v.Weight, v.Height, v.Width = 1400, 150, 180
+		db[1] = v
+	}
+	rp = repository.NewVehicleMap(db)
+	hd := NewVehicleDefault(service.NewVehicleDefault(rp), validation.NewVehicleRules(nil))
+
+	rt = chi.NewRouter()
+	rt.Get("/vehicles/{id}", hd.GetById())
+	rt.Put("/vehicles/{id}", hd.Replace())
+	rt.Patch("/vehicles/{id}", hd.Patch())
+	return
+}
+
+func TestVehicleDefault_GetById(t *testing.T) {
+	rt, _ := newVehicleRouter(t, false)
+	cases := []struct {
+		target string
+		status int
+	}{
+		{target: "/vehicles/1", status: http.StatusOK},
+		{target: "/vehicles/2", status: http.StatusNotFound},
+		{target: "/vehicles/one", status: http.StatusBadRequest},
+	}
+	for _, c := range cases {
+		t.Run(c.target, func(t *testing.T) {
+			res := httptest.NewRecorder()
+			rt.ServeHTTP(res, httptest.NewRequest(http.MethodGet, c.target, nil))
+			if res.Code != c.status {
+				t.Fatalf("status = %d, want %d: %s", res.Code, c.status, res.Body)
+			}
+			if c.status == http.StatusOK && !strings.Contains(res.Header().Get("Accept-Patch"), mediaTypeJSONPatch) {
+				t.Fatalf("Accept-Patch = %q, want the patch media types", res.Header().Get("Accept-Patch"))
+			}
+		})
+	}
+}
+
+func TestVehicleDefault_Replace(t *testing.T) {
+	cases := []struct {
+		name   string
+		empty  bool
+		body   string
+		status int
+		color  string
+	}{
+		{name: "create", empty: true, body: `{` + prius + `}`, status: http.StatusCreated, color: "red"},
+		{name: "replace", body: `{"id": 1, ` + strings.Replace(prius, `"red"`, `"blue"`, 1) + `}`, status: http.StatusOK, color: "blue"},
+		{name: "id mismatch", body: `{"id": 2, ` + prius + `}`, status: http.StatusBadRequest, color: "red"},
+		{name: "invalid vehicle", body: `{"id": 1, "brand": "Toyota"}`, status: http.StatusUnprocessableEntity, color: "red"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			rt, rp := newVehicleRouter(t, c.empty)
+
+			res := httptest.NewRecorder()
+			rt.ServeHTTP(res, httptest.NewRequest(http.MethodPut, "/vehicles/1", strings.NewReader(c.body)))
+			if res.Code != c.status {
+				t.Fatalf("status = %d, want %d: %s", res.Code, c.status, res.Body)
+			}
+			if v, err := rp.FindById(1); err != nil || v.Color != c.color {
+				t.Fatalf("vehicle = %+v, err = %v, want the color %s", v, err, c.color)
+			}
+		})
+	}
+}
+
+func TestVehicleDefault_Patch(t *testing.T) {
+	cases := []struct {
+		name        string
+		contentType string
+		body        string
+		status      int
+		color       string
+	}{
+		{name: "merge patch", contentType: mediaTypeMergePatch, body: `{"color": "blue"}`, status: http.StatusOK, color: "blue"},
+		{name: "json patch", contentType: mediaTypeJSONPatch, body: `[{"op": "replace", "path": "/color", "value": "green"}]`, status: http.StatusOK, color: "green"},
+		{name: "immutable field", contentType: mediaTypeMergePatch, body: `{"brand": "Ford"}`, status: http.StatusUnprocessableEntity, color: "red"},
+		{name: "invalid vehicle", contentType: mediaTypeMergePatch, body: `{"passengers": 0}`, status: http.StatusUnprocessableEntity, color: "red"},
+		{name: "malformed", contentType: mediaTypeJSONPatch, body: `{"op": "replace"}`, status: http.StatusBadRequest, color: "red"},
+		{name: "unsupported media type", contentType: "text/plain", body: `color=blue`, status: http.StatusUnsupportedMediaType, color: "red"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			rt, rp := newVehicleRouter(t, false)
+
+			req := httptest.NewRequest(http.MethodPatch, "/vehicles/1", strings.NewReader(c.body))
+			req.Header.Set("Content-Type", c.contentType)
+			res := httptest.NewRecorder()
+			rt.ServeHTTP(res, req)
+			if res.Code != c.status {
+				t.Fatalf("status = %d, want %d: %s", res.Code, c.status, res.Body)
+			}
+			if v, _ := rp.FindById(1); v.Color != c.color {
+				t.Fatalf("color = %q, want %q", v.Color, c.color)
+			}
+		})
+	}
+
+	// - not found
+	rt, _ := newVehicleRouter(t, true)
+	res := httptest.NewRecorder()
+	rt.ServeHTTP(res, httptest.NewRequest(http.MethodPatch, "/vehicles/1", strings.NewReader(`{"color": "blue"}`)))
+	if res.Code != http.StatusNotFound {
+		t.Fatalf("not found: status = %d, want %d", res.Code, http.StatusNotFound)
+	}
+}
@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"app/internal"
+	"app/internal/repository"
+	"app/internal/service"
+	"app/internal/validation"
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// importCSV is a CSV file with a new vehicle, a row that can not be decoded and the existing vehicle 1
+const importCSV = `id,brand,model,registration,color,year,passengers,max_speed,fuel_type,transmission,weight,height,length,width
+2,Ford,Focus,REG-2,red,2020,5,200,gasoline,manual,1300,150,400,180
+3,Ford,Focus,REG-3,red,new,5,200,gasoline,manual,1300,150,400,180
+1,Fiat,Punto,REG-1,blue,2018,5,170,diesel,manual,1100,140,380,170
+`
+
+// newImportRequest is a function that returns a request that uploads a file in the multipart part "file"
+func newImportRequest(t *testing.T, target, fileName, content string) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	part, err := mw.CreateFormFile("file", fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	part.Write([]byte(content))
+	mw.Close()
+
+	req := httptest.NewRequest(http.MethodPost, target, &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req
+}
+
+func TestVehicleDefault_Import(t *testing.T) {
+	cases := []struct {
+		name    string
+		target  string
+		status  int
+		summary map[string]int
+		brand   string
+		count   int
+	}{
+		{name: "skip", target: "/vehicles/import?on_conflict=skip", status: http.StatusOK,
+			summary: map[string]int{"created": 1, "updated": 0, "skipped": 1, "invalid": 1}, brand: "Hummer", count: 2},
+		{name: "overwrite", target: "/vehicles/import?on_conflict=overwrite", status: http.StatusOK,
+			summary: map[string]int{"created": 1, "updated": 1, "skipped": 0, "invalid": 1}, brand: "Fiat", count: 2},
+		{name: "dry run", target: "/vehicles/import?on_conflict=overwrite&dry_run=true", status: http.StatusOK,
+			summary: map[string]int{"created": 1, "updated": 1, "skipped": 0, "invalid": 1}, brand: "Hummer", count: 1},
+		{name: "fail", target: "/vehicles/import", status: http.StatusConflict, brand: "Hummer", count: 1},
+		{name: "unknown policy", target: "/vehicles/import?on_conflict=merge", status: http.StatusBadRequest, brand: "Hummer", count: 1},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			existing := internal.Vehicle{Id: 1}
+			existing.Brand = "Hummer"
+			rp := repository.NewVehicleMap(map[int]internal.Vehicle{1: existing})
+			hd := NewVehicleDefault(service.NewVehicleDefault(rp), validation.NewVehicleRules(nil))
+
+			res := httptest.NewRecorder()
+			hd.Import()(res, newImportRequest(t, c.target, "vehicles.csv", importCSV))
+			if res.Code != c.status {
+				t.Fatalf("status = %d, want %d: %s", res.Code, c.status, res.Body)
+			}
+			if c.summary != nil {
+				var body struct {
+					Data struct {
+						Summary map[string]int `json:"summary"`
+						Rows    []ImportRow    `json:"rows"`
+					} `json:"data"`
+				}
+				if err := json.Unmarshal(res.Body.Bytes(), &body); err != nil {
+					t.Fatalf("body: %v", err)
+				}
+				for outcome, n := range c.summary {
+					if body.Data.Summary[outcome] != n {
+						t.Fatalf("summary = %v, want %v", body.Data.Summary, c.summary)
+					}
+				}
+				if len(body.Data.Rows) != 3 || body.Data.Rows[1].Row != 2 || body.Data.Rows[1].Status != "invalid" || body.Data.Rows[1].Reason == "" {
+					t.Fatalf("rows = %+v, want the row 2 invalid with its reason", body.Data.Rows)
+				}
+			}
+
+			v, _ := rp.FindAll()
+			if len(v) != c.count || v[1].Brand != c.brand {
+				t.Fatalf("repository has %d vehicles and the vehicle 1 of %s, want %d and %s", len(v), v[1].Brand, c.count, c.brand)
+			}
+		})
+	}
+}
+
+func TestVehicleDefault_Import_NDJSON(t *testing.T) {
+	rp := repository.NewVehicleMap(nil)
+	hd := NewVehicleDefault(service.NewVehicleDefault(rp), validation.NewVehicleRules(nil))
+	lines := `{"id": 1, "brand": "Ford", "model": "Focus", "registration": "REG-1", "color": "red", "year": 2020, "passengers": 5,` +
+		` "max_speed": 200, "fuel_type": "gasoline", "transmission": "manual", "weight": 1300, "height": 150, "width": 180}` +
+		"\n\n" + `{"id": 2, "brand": ""}` + "\n"
+
+	res := httptest.NewRecorder()
+	hd.Import()(res, newImportRequest(t, "/vehicles/import", "vehicles.ndjson", lines))
+	if res.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", res.Code, http.StatusOK, res.Body)
+	}
+	if v, _ := rp.FindAll(); len(v) != 1 || v[1].Brand != "Ford" {
+		t.Fatalf("vehicles = %+v, want only the vehicle 1", v)
+	}
+}
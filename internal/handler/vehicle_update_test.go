@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"app/internal"
+	"app/internal/repository"
+	"app/internal/service"
+	"app/internal/validation"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// newElectricFleet is a function that returns a router with the vehicle routes validated by the rules of
+// docs/rules/electric_fleet.json, and the repository with a hybrid vehicle with a manual transmission
+func newElectricFleet(t *testing.T) (rt *chi.Mux, rp *repository.VehicleMap) {
+	rules, err := validation.LoadRulesFile("../../docs/rules/electric_fleet.json")
+	if err != nil {
+		t.Fatalf("rules: %v", err)
+	}
+	v := internal.Vehicle{Id: 1}
+	v.Brand, v.Model, v.Registration, v.Color = "Toyota", "Prius", "ABC-123", "red"
+	v.FabricationYear, v.Capacity, v.MaxSpeed = 2020, 5, 110
+	v.FuelType, v.Transmission, v.Weight = "hybrid", "manual", 1400
+	v.Height, v.Width = 1.5, 1.8
+	rp = repository.NewVehicleMap(map[int]internal.Vehicle{1: v})
+	hd := NewVehicleDefault(service.NewVehicleDefault(rp), validation.NewVehicleRules(rules))
+
+	rt = chi.NewRouter()
+	rt.Patch("/vehicles/{id}", hd.Patch())
+	rt.Patch("/vehicles/{id}/update_speed", hd.UpdateSpeed())
+	rt.Patch("/vehicles/{id}/update_fuel", hd.UpdateFuelType())
+	return
+}
+
+// serve is a function that serves a request with a JSON body through the router
+func serve(rt http.Handler, method, target, contentType, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	res := httptest.NewRecorder()
+	rt.ServeHTTP(res, req)
+	return res
+}
+
+func TestVehicleDefault_UpdateFuelType_ValidatesVehicle(t *testing.T) {
+	rt, rp := newElectricFleet(t)
+
+	// - an electric vehicle must be automatic, so the manual hybrid can not become electric
+	res := serve(rt, http.MethodPatch, "/vehicles/1/update_fuel", "application/json", `{"fuel_type": "electric"}`)
+	if res.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d: %s", res.Code, http.StatusBadRequest, res.Body)
+	}
+	var problem Problem
+	if err := json.Unmarshal(res.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("problem: %v", err)
+	}
+	if problem.Code != "invalid_fuel_type" || len(problem.Errors) != 1 || problem.Errors[0].Pointer != "/transmission" {
+		t.Fatalf("problem = %+v, want invalid_fuel_type on /transmission", problem)
+	}
+	if v, _ := rp.FindById(1); v.FuelType != "hybrid" {
+		t.Fatalf("fuel type = %q, want it unchanged", v.FuelType)
+	}
+
+	// - the vehicle is still valid, so an unrelated merge patch is accepted
+	res = serve(rt, http.MethodPatch, "/vehicles/1", mediaTypeMergePatch, `{"color": "blue"}`)
+	if res.Code != http.StatusOK {
+		t.Fatalf("merge patch: status = %d, want %d: %s", res.Code, http.StatusOK, res.Body)
+	}
+}
+
+func TestVehicleDefault_UpdateSpeed_ValidatesVehicle(t *testing.T) {
+	rt, rp := newElectricFleet(t)
+
+	res := serve(rt, http.MethodPatch, "/vehicles/1/update_speed", "application/json", `{"max_speed": 300}`)
+	if res.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d: %s", res.Code, http.StatusBadRequest, res.Body)
+	}
+	res = serve(rt, http.MethodPatch, "/vehicles/1/update_speed", "application/json", `{"max_speed": 200}`)
+	if res.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", res.Code, http.StatusOK, res.Body)
+	}
+	// - the speed is received in km/h and stored in mph
+	if v, _ := rp.FindById(1); math.Abs(v.MaxSpeed-124.27) > 0.01 {
+		t.Fatalf("max speed = %v, want about 124.27 mph", v.MaxSpeed)
+	}
+}
@@ -0,0 +1,77 @@
+package i18n
+
+// en is the catalog of the messages in English
+var en = Catalog{
+	// errors
+	"bad_request":                 "Malformed or incomplete vehicle data.",
+	"vehicle_conflict":            "Vehicle id already exists.",
+	"no_vehicles_found":           "No vehicles found with those criteria.",
+	"invalid_filter":              "Malformed search criteria.",
+	"invalid_speed":               "Malformed or out of range speed.",
+	"invalid_fuel_type":           "Malformed or unsupported fuel type.",
+	"vehicle_not_found":           "Vehicle not found.",
+	"validation_failed":           "The vehicle breaks the validation rules.",
+	"unsupported_format":          "Unsupported format.",
+	"unsupported_conflict_policy": "Unsupported conflict policy.",
+	"not_acceptable":              "Unsupported response format.",
+	"internal_error":              "Internal server error.",
+	"unsupported_units":           "Unsupported unit system.",
+	"invalid_patch":               "Malformed patch or not applicable to the vehicle.",
+	"patch_test_failed":           "The vehicle does not pass the test of the patch.",
+	"immutable_field":             "The patch changes fields of the vehicle that are not mutable.",
+	"unsupported_media_type":      "Unsupported content type.",
+	"job_not_found":               "Job not found.",
+	"job_finished":                "The job already finished.",
+	"job_queue_full":              "Too many pending jobs, try again later.",
+	"invalid_idempotency_key":     "Malformed idempotency key.",
+	"idempotency_key_mismatch":    "The idempotency key was used by a different request.",
+	"idempotency_key_in_progress": "A request with the same idempotency key is being processed.",
+	"unauthenticated":             "Missing, unknown or expired credentials.",
+	"insufficient_scope":          "The credentials do not grant access to this operation.",
+	"field_forbidden":             "The change modifies fields of the vehicle that your role may not modify.",
+	"tenant_not_found":            "Tenant not found.",
+	"tenant_forbidden":            "The credentials do not grant access to this tenant.",
+	"quota_exceeded":              "The change exceeds the vehicle quota of the tenant.",
+	"rate_limited":                "Too many requests, try again later.",
+	"concurrency_limited":         "Too many requests at the same time, try again when some finish.",
+	"fleet_not_found":             "Fleet not found.",
+	"fleet_conflict":              "Fleet name already exists.",
+	"vehicle_assigned":            "The vehicle is assigned to another fleet.",
+	"vehicle_not_in_fleet":        "The vehicle is not assigned to the fleet.",
+
+	// repository errors, by the code of the error and the criteria that caused it
+	"vehicle_not_found.detail":    "there is no vehicle with %s",
+	"vehicle_conflict.detail":     "a vehicle with %s already exists",
+	"no_vehicles_found.detail":    "no vehicles found with %s",
+	"quota_exceeded.detail":       "the tenant can not have more vehicles than %s",
+	"fleet_not_found.detail":      "there is no fleet with %s",
+	"fleet_conflict.detail":       "a fleet with %s already exists",
+	"vehicle_assigned.detail":     "the vehicle is assigned to another fleet, %s",
+	"vehicle_not_in_fleet.detail": "the vehicle is not assigned to the fleet, %s",
+
+	// success
+	"success":           "success",
+	"vehicle_created":   "Vehicle created successfully.",
+	"vehicles_created":  "Vehicles created successfully.",
+	"batch_partial":     "Batch processed, check the result of each vehicle.",
+	"speed_updated":     "Vehicle speed updated successfully.",
+	"vehicle_deleted":   "Vehicle deleted successfully.",
+	"vehicles_updated":  "Vehicles updated successfully.",
+	"vehicles_deleted":  "Vehicles deleted successfully.",
+	"bulk_simulated":    "Simulation completed, no changes were saved.",
+	"bulk_completed":    "Operations completed successfully.",
+	"bulk_partial":      "Operations processed, check the result of each one.",
+	"fuel_type_updated": "Vehicle fuel type updated successfully.",
+	"import_completed":  "Import completed.",
+	"import_simulated":  "Import simulation completed, no changes were saved.",
+	"average_speed":     "%s - average speed is: %.2f %s",
+	"reload_failed":     "The last data reload failed, the previous data is kept.",
+	"job_accepted":      "Job accepted, check its progress at its location.",
+	"job_canceled":      "Job canceled.",
+	"job_canceling":     "Cancellation requested, the job stops after its current step.",
+	"fleet_created":     "Fleet created successfully.",
+	"fleet_updated":     "Fleet updated successfully.",
+	"fleet_deleted":     "Fleet deleted successfully.",
+	"vehicles_assigned": "Vehicles assigned to the fleet successfully.",
+	"vehicle_removed":   "Vehicle removed from the fleet successfully.",
+}
@@ -0,0 +1,77 @@
+package i18n
+
+// es is the catalog of the messages in Spanish
+var es = Catalog{
+	// errors
+	"bad_request":                 "Datos del vehículo mal formados o incompletos.",
+	"vehicle_conflict":            "Identificador del vehículo ya existente.",
+	"no_vehicles_found":           "No se encontraron vehículos con esos criterios.",
+	"invalid_filter":              "Criterios de búsqueda mal formados.",
+	"invalid_speed":               "Velocidad mal formada o fuera de rango.",
+	"invalid_fuel_type":           "Tipo de combustible mal formado o no admitido.",
+	"vehicle_not_found":           "No se encontró el vehículo.",
+	"validation_failed":           "El vehículo no cumple las reglas de validación.",
+	"unsupported_format":          "Formato no admitido.",
+	"unsupported_conflict_policy": "Política de conflictos no admitida.",
+	"not_acceptable":              "Formato de respuesta no admitido.",
+	"internal_error":              "Error interno del servidor.",
+	"unsupported_units":           "Sistema de unidades no admitido.",
+	"invalid_patch":               "Parche mal formado o no aplicable al vehículo.",
+	"patch_test_failed":           "El vehículo no cumple la prueba del parche.",
+	"immutable_field":             "El parche modifica campos no modificables del vehículo.",
+	"unsupported_media_type":      "Tipo de contenido no admitido.",
+	"job_not_found":               "Trabajo no encontrado.",
+	"job_finished":                "El trabajo ya finalizó.",
+	"job_queue_full":              "Demasiados trabajos pendientes, intente más tarde.",
+	"invalid_idempotency_key":     "Clave de idempotencia mal formada.",
+	"idempotency_key_mismatch":    "La clave de idempotencia fue usada por una petición distinta.",
+	"idempotency_key_in_progress": "Una petición con la misma clave de idempotencia se está procesando.",
+	"unauthenticated":             "Credenciales ausentes, desconocidas o expiradas.",
+	"insufficient_scope":          "Las credenciales no permiten esta operación.",
+	"field_forbidden":             "El cambio modifica campos del vehículo que su rol no puede modificar.",
+	"tenant_not_found":            "Inquilino no encontrado.",
+	"tenant_forbidden":            "Las credenciales no permiten acceder a este inquilino.",
+	"quota_exceeded":              "El cambio supera la cuota de vehículos del inquilino.",
+	"rate_limited":                "Demasiadas solicitudes, intente más tarde.",
+	"concurrency_limited":         "Demasiadas solicitudes simultáneas, intente de nuevo cuando terminen.",
+	"fleet_not_found":             "Flota no encontrada.",
+	"fleet_conflict":              "El nombre de la flota ya existe.",
+	"vehicle_assigned":            "El vehículo está asignado a otra flota.",
+	"vehicle_not_in_fleet":        "El vehículo no está asignado a la flota.",
+
+	// repository errors, by the code of the error and the criteria that caused it
+	"vehicle_not_found.detail":    "no existe un vehículo con %s",
+	"vehicle_conflict.detail":     "ya existe un vehículo con %s",
+	"no_vehicles_found.detail":    "no se encontraron vehículos con %s",
+	"quota_exceeded.detail":       "el inquilino no puede tener más vehículos que %s",
+	"fleet_not_found.detail":      "no existe una flota con %s",
+	"fleet_conflict.detail":       "ya existe una flota con %s",
+	"vehicle_assigned.detail":     "el vehículo está asignado a otra flota, %s",
+	"vehicle_not_in_fleet.detail": "el vehículo no está asignado a la flota, %s",
+
+	// success
+	"success":           "success",
+	"vehicle_created":   "Vehículo creado exitosamente.",
+	"vehicles_created":  "Vehículos creados exitosamente.",
+	"batch_partial":     "Lote procesado, revise el resultado de cada vehículo.",
+	"speed_updated":     "Velocidad del vehículo actualizada exitosamente.",
+	"vehicle_deleted":   "Vehículo eliminado exitosamente.",
+	"vehicles_updated":  "Vehículos actualizados exitosamente.",
+	"vehicles_deleted":  "Vehículos eliminados exitosamente.",
+	"bulk_simulated":    "Simulación completada, no se guardaron cambios.",
+	"bulk_completed":    "Operaciones completadas exitosamente.",
+	"bulk_partial":      "Operaciones procesadas, revise el resultado de cada una.",
+	"fuel_type_updated": "Tipo del combustible del vehículo actualizado exitosamente.",
+	"import_completed":  "Importación completada.",
+	"import_simulated":  "Simulación de importación completada, no se guardaron cambios.",
+	"average_speed":     "%s - la velocidad promedio es: %.2f %s",
+	"reload_failed":     "La última recarga de datos falló, se mantienen los datos anteriores.",
+	"job_accepted":      "Trabajo aceptado, consulte su progreso en su ubicación.",
+	"job_canceled":      "Trabajo cancelado.",
+	"job_canceling":     "Cancelación solicitada, el trabajo se detiene tras su paso actual.",
+	"fleet_created":     "Flota creada exitosamente.",
+	"fleet_updated":     "Flota actualizada exitosamente.",
+	"fleet_deleted":     "Flota eliminada exitosamente.",
+	"vehicles_assigned": "Vehículos asignados a la flota exitosamente.",
+	"vehicle_removed":   "Vehículo retirado de la flota exitosamente.",
+}
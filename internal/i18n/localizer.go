@@ -0,0 +1,163 @@
+package i18n
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrUnknownLanguage is an error for a language without a catalog
+	ErrUnknownLanguage = errors.New("i18n: unknown language")
+)
+
+// Catalog is a map of the messages of a language by key, the messages are fmt formats
+type Catalog map[string]string
+
+// Catalogs are the built-in catalogs by language
+var Catalogs = map[string]Catalog{
+	"es": es,
+	"en": en,
+}
+
+// NewLocalizer is a function that returns a new instance of Localizer
+func NewLocalizer(catalogs map[string]Catalog, fallback string) *Localizer {
+	// default catalogs
+	defaultCatalogs := Catalogs
+	if catalogs != nil {
+		defaultCatalogs = catalogs
+	}
+	// default language
+	defaultFallback := "es"
+	if fallback != "" {
+		defaultFallback = fallback
+	}
+	return &Localizer{catalogs: defaultCatalogs, fallback: defaultFallback}
+}
+
+// Localizer is a struct that selects the catalog of the language accepted by the client
+type Localizer struct {
+	// catalogs are the catalogs by language
+	catalogs map[string]Catalog
+	// fallback is the language used when none of the accepted ones has a catalog
+	fallback string
+}
+
+// Languages is a method that returns the languages with a catalog, sorted
+func (l *Localizer) Languages() (langs []string) {
+	for lang := range l.catalogs {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	return
+}
+
+// Match is a method that returns the language with a catalog preferred by an Accept-Language header,
+// or the fallback language if there is none. A region is ignored when there is no catalog for it (en-US matches en)
+func (l *Localizer) Match(acceptLanguage string) (lang string) {
+	type accepted struct {
+		tag string
+		q   float64
+	}
+	var tags []accepted
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			var err error
+			if q, err = strconv.ParseFloat(value, 64); err != nil {
+				continue
+			}
+		}
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || q <= 0 {
+			continue
+		}
+		tags = append(tags, accepted{tag: tag, q: q})
+	}
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+
+	for _, t := range tags {
+		if t.tag == "*" {
+			break
+		}
+		if _, ok := l.catalogs[t.tag]; ok {
+			return t.tag
+		}
+		primary, _, _ := strings.Cut(t.tag, "-")
+		if _, ok := l.catalogs[primary]; ok {
+			return primary
+		}
+	}
+	return l.fallback
+}
+
+// Printer is a method that returns the printer of a language
+func (l *Localizer) Printer(lang string) *Printer {
+	return &Printer{lang: lang, catalog: l.catalogs[lang], fallback: l.catalogs[l.fallback]}
+}
+
+// Middleware is a method that returns a middleware that adds to the context of the request the printer of
+// the language accepted by the client, and announces the language in the Content-Language header
+func (l *Localizer) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lang := l.Match(r.Header.Get("Accept-Language"))
+		w.Header().Set("Content-Language", lang)
+		w.Header().Add("Vary", "Accept-Language")
+		next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), l.Printer(lang))))
+	})
+}
+
+// Printer is a struct that formats the messages of a language
+type Printer struct {
+	// lang is the language of the messages
+	lang string
+	// catalog is the catalog of the language
+	catalog Catalog
+	// fallback is the catalog used for the messages missing in catalog
+	fallback Catalog
+}
+
+// Lang is a method that returns the language of the printer
+func (p *Printer) Lang() string {
+	return p.lang
+}
+
+// Sprintf is a method that formats the message of a key with the arguments.
+// A key without a message in the language, nor in the fallback language, is formatted as is
+func (p *Printer) Sprintf(key string, args ...any) string {
+	format, ok := p.catalog[key]
+	if !ok {
+		format, ok = p.fallback[key]
+	}
+	if !ok {
+		format = key
+	}
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// contextKey is the type of the key of the printer in the context
+type contextKey struct{}
+
+// defaultPrinter is the printer of the requests without one, in the fallback language of the built-in catalogs
+var defaultPrinter = NewLocalizer(nil, "").Printer("es")
+
+// NewContext is a function that returns a copy of the context with the printer
+func NewContext(ctx context.Context, p *Printer) context.Context {
+	return context.WithValue(ctx, contextKey{}, p)
+}
+
+// FromContext is a function that returns the printer of the context, or the default one if there is none
+func FromContext(ctx context.Context) *Printer {
+	if p, ok := ctx.Value(contextKey{}).(*Printer); ok {
+		return p
+	}
+	return defaultPrinter
+}
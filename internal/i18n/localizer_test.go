@@ -0,0 +1,81 @@
+package i18n
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLocalizer_Match(t *testing.T) {
+	cases := []struct {
+		accept string
+		lang   string
+	}{
+		{accept: "", lang: "es"},
+		{accept: "en", lang: "en"},
+		{accept: "en-US,en;q=0.9", lang: "en"},
+		{accept: "fr;q=1, en;q=0.5, es;q=0.8", lang: "es"},
+		{accept: "fr, de", lang: "es"},
+		{accept: "en;q=0", lang: "es"},
+		{accept: "*", lang: "es"},
+	}
+	l := NewLocalizer(nil, "")
+	for _, c := range cases {
+		t.Run(c.accept, func(t *testing.T) {
+			if lang := l.Match(c.accept); lang != c.lang {
+				t.Fatalf("lang = %q, want %q", lang, c.lang)
+			}
+		})
+	}
+}
+
+func TestPrinter_Sprintf(t *testing.T) {
+	l := NewLocalizer(map[string]Catalog{
+		"es": {"greeting": "hola %s", "farewell": "adiós"},
+		"en": {"greeting": "hello %s"},
+	}, "es")
+	p := l.Printer("en")
+
+	if msg := p.Sprintf("greeting", "Ana"); msg != "hello Ana" {
+		t.Fatalf("greeting = %q, want the English message", msg)
+	}
+	if msg := p.Sprintf("farewell"); msg != "adiós" {
+		t.Fatalf("farewell = %q, want the fallback message", msg)
+	}
+	if msg := p.Sprintf("unknown"); msg != "unknown" {
+		t.Fatalf("unknown = %q, want the key", msg)
+	}
+}
+
+func TestCatalogs_Keys(t *testing.T) {
+	// every message has a translation
+	for lang, catalog := range Catalogs {
+		for other, otherCatalog := range Catalogs {
+			for key := range catalog {
+				if _, ok := otherCatalog[key]; !ok {
+					t.Errorf("key %q of %s is missing in %s", key, lang, other)
+				}
+			}
+		}
+	}
+}
+
+func TestLocalizer_Middleware(t *testing.T) {
+	var lang string
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lang = FromContext(r.Context()).Lang()
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/vehicles", nil)
+	req.Header.Set("Accept-Language", "en-GB")
+	res := httptest.NewRecorder()
+	NewLocalizer(nil, "").Middleware(next).ServeHTTP(res, req)
+	if lang != "en" || res.Header().Get("Content-Language") != "en" || res.Header().Get("Vary") != "Accept-Language" {
+		t.Fatalf("lang = %q, headers = %v, want en", lang, res.Header())
+	}
+
+	// - requests without a printer use the fallback language
+	if p := FromContext(httptest.NewRequest(http.MethodGet, "/", nil).Context()); p.Lang() != "es" {
+		t.Fatalf("default lang = %q, want es", p.Lang())
+	}
+}
@@ -0,0 +1,97 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrJobNotFound is an error that represents a job that does not exist
+	ErrJobNotFound = errors.New("job not found")
+	// ErrJobFinished is an error that represents a job that cannot be canceled because it already finished
+	ErrJobFinished = errors.New("job already finished")
+	// ErrJobQueueFull is an error that represents a job that is not accepted because the queue is full
+	ErrJobQueueFull = errors.New("job queue full")
+)
+
+// JobState is the state of a job
+type JobState string
+
+const (
+	// JobQueued is the state of a job waiting for a worker
+	JobQueued JobState = "queued"
+	// JobRunning is the state of a job being processed by a worker
+	JobRunning JobState = "running"
+	// JobSucceeded is the state of a job that finished, even if some of its records failed
+	JobSucceeded JobState = "succeeded"
+	// JobFailed is the state of a job that could not finish
+	JobFailed JobState = "failed"
+	// JobCanceled is the state of a job canceled before it finished
+	JobCanceled JobState = "canceled"
+)
+
+// Finished is a method that returns true if the job is in a final state
+func (s JobState) Finished() bool {
+	return s == JobSucceeded || s == JobFailed || s == JobCanceled
+}
+
+// Job is a struct that represents a task processed in the background, such as an import
+type Job struct {
+	// ID is the identifier of the job
+	ID string `json:"id"`
+	// Tenant is the tenant that submitted the job, only its clients see it
+	Tenant string `json:"tenant"`
+	// Kind is the kind of task of the job, such as import
+	Kind string `json:"kind"`
+	// State is the state of the job
+	State JobState `json:"state"`
+	// Total is the amount of records of the job
+	Total int `json:"total"`
+	// Processed is the amount of records processed so far, including the failed ones
+	Processed int `json:"processed"`
+	// Errors are the records that failed
+	Errors []JobError `json:"errors"`
+	// Result is the result of the job, once it finished
+	Result any `json:"result,omitempty"`
+	// Error is the reason the job failed
+	Error string `json:"error,omitempty"`
+	// CreatedAt is the time the job was submitted
+	CreatedAt time.Time `json:"created_at"`
+	// StartedAt is the time a worker started the job
+	StartedAt *time.Time `json:"started_at,omitempty"`
+	// FinishedAt is the time the job finished
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// JobError is a struct that represents a record of a job that failed
+type JobError struct {
+	// Record is the number of the record, starting at 1
+	Record int `json:"record"`
+	// ID is the id of the vehicle of the record, if it could be decoded
+	ID int `json:"id,omitempty"`
+	// Error is the reason the record failed
+	Error string `json:"error"`
+}
+
+// JobTask is a function that processes the records of a job, reporting its progress.
+// It should stop when the context is canceled, returning its error
+type JobTask func(ctx context.Context, p JobProgress) (result any, err error)
+
+// JobProgress is an interface that reports the progress of a running job
+type JobProgress interface {
+	// Add is a method that adds n records to the processed ones
+	Add(n int)
+	// Fail is a method that reports a failed record, which is also processed
+	Fail(record int, id int, err error)
+}
+
+// JobRunner is an interface that represents a runner of jobs in the background
+type JobRunner interface {
+	// Submit is a method that queues a task of a tenant with a total of records, and returns its job
+	Submit(tenant, kind string, total int, task JobTask) (j Job, err error)
+	// Get is a method that returns the status of a job
+	Get(id string) (j Job, err error)
+	// Cancel is a method that cancels a job that has not finished
+	Cancel(id string) (j Job, err error)
+}
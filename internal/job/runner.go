@@ -0,0 +1,284 @@
+package job
+
+import (
+	"app/internal"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// ConfigRunner is a struct that represents the configuration for Runner
+type ConfigRunner struct {
+	// Workers is the amount of jobs processed at the same time
+	Workers int
+	// QueueSize is the amount of jobs that can wait for a worker, more are rejected
+	QueueSize int
+	// Retention is the time the status of a finished job is kept, then it is forgotten
+	Retention time.Duration
+}
+
+// NewRunner is a function that returns a new instance of Runner
+func NewRunner(cfg *ConfigRunner) *Runner {
+	// default values
+	defaultConfig := &ConfigRunner{
+		Workers:   2,
+		QueueSize: 16,
+		Retention: time.Hour,
+	}
+	if cfg != nil {
+		if cfg.Workers > 0 {
+			defaultConfig.Workers = cfg.Workers
+		}
+		if cfg.QueueSize > 0 {
+			defaultConfig.QueueSize = cfg.QueueSize
+		}
+		if cfg.Retention > 0 {
+			defaultConfig.Retention = cfg.Retention
+		}
+	}
+
+	return &Runner{
+		workers:   defaultConfig.Workers,
+		queue:     make(chan *entry, defaultConfig.QueueSize),
+		retention: defaultConfig.Retention,
+		jobs:      make(map[string]*entry),
+	}
+}
+
+// Runner is a struct that implements the JobRunner interface, it processes jobs in the background with a bounded pool of workers
+type Runner struct {
+	// workers is the amount of jobs processed at the same time
+	workers int
+	// queue are the jobs waiting for a worker
+	queue chan *entry
+	// retention is the time the status of a finished job is kept
+	retention time.Duration
+	// mu is the mutex that protects the jobs
+	mu sync.RWMutex
+	// jobs are the submitted jobs, by id
+	jobs map[string]*entry
+	// wg waits for the workers to stop
+	wg sync.WaitGroup
+	// stop stops the workers
+	stop context.CancelFunc
+}
+
+// entry is a struct that represents a submitted job with its task
+type entry struct {
+	// job is the status of the job
+	job internal.Job
+	// task is the task of the job
+	task internal.JobTask
+	// ctx is the context of the task, canceled to cancel the job
+	ctx context.Context
+	// cancel cancels the job
+	cancel context.CancelFunc
+}
+
+// Start is a method that starts the workers
+func (r *Runner) Start() {
+	ctx, stop := context.WithCancel(context.Background())
+	r.stop = stop
+	for i := 0; i < r.workers; i++ {
+		r.wg.Add(1)
+		go func() {
+			defer r.wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case e := <-r.queue:
+					r.run(e)
+				}
+			}
+		}()
+	}
+}
+
+// Stop is a method that cancels the running jobs and waits for the workers to stop
+func (r *Runner) Stop() {
+	r.mu.RLock()
+	for _, e := range r.jobs {
+		e.cancel()
+	}
+	r.mu.RUnlock()
+	if r.stop != nil {
+		r.stop()
+	}
+	r.wg.Wait()
+}
+
+// Submit is a method that queues a task of a tenant with a total of records, and returns its job
+func (r *Runner) Submit(tenant, kind string, total int, task internal.JobTask) (j internal.Job, err error) {
+	id, err := newID()
+	if err != nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	e := &entry{
+		job: internal.Job{
+			ID:        id,
+			Tenant:    tenant,
+			Kind:      kind,
+			State:     internal.JobQueued,
+			Total:     total,
+			Errors:    []internal.JobError{},
+			CreatedAt: time.Now(),
+		},
+		task:   task,
+		ctx:    ctx,
+		cancel: cancel,
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.prune(time.Now())
+	select {
+	case r.queue <- e:
+	default:
+		cancel()
+		err = internal.ErrJobQueueFull
+		return
+	}
+	r.jobs[id] = e
+	j = e.job
+	return
+}
+
+// Get is a method that returns the status of a job
+func (r *Runner) Get(id string) (j internal.Job, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	e, ok := r.jobs[id]
+	if !ok || r.expired(e, time.Now()) {
+		err = internal.ErrJobNotFound
+		return
+	}
+	j = e.job
+	j.Errors = append([]internal.JobError{}, e.job.Errors...)
+	return
+}
+
+// Cancel is a method that cancels a job: a queued job is not run, a running job is stopped by its task
+func (r *Runner) Cancel(id string) (j internal.Job, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	e, ok := r.jobs[id]
+	if !ok || r.expired(e, time.Now()) {
+		err = internal.ErrJobNotFound
+		return
+	}
+	if e.job.State.Finished() {
+		err = internal.ErrJobFinished
+		return
+	}
+	e.cancel()
+	if e.job.State == internal.JobQueued {
+		r.finish(e, nil, context.Canceled)
+	}
+	j = e.job
+	return
+}
+
+// run is a method that runs the task of a job, unless it was canceled while queued
+func (r *Runner) run(e *entry) {
+	r.mu.Lock()
+	if e.job.State != internal.JobQueued {
+		r.mu.Unlock()
+		return
+	}
+	now := time.Now()
+	e.job.State = internal.JobRunning
+	e.job.StartedAt = &now
+	r.mu.Unlock()
+
+	result, err := r.runTask(e)
+
+	r.mu.Lock()
+	r.finish(e, result, err)
+	r.mu.Unlock()
+}
+
+// runTask is a method that calls the task of a job, a panic of the task fails the job instead of stopping the server
+func (r *Runner) runTask(e *entry) (result any, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			log.Printf("job %s: panic: %v\n%s", e.job.ID, p, debug.Stack())
+			// the cause is logged, it is not shown to the client
+			result, err = nil, errors.New("internal error")
+		}
+	}()
+	result, err = e.task(e.ctx, &Progress{r: r, e: e})
+	return
+}
+
+// prune is a method that forgets the finished jobs whose retention expired, the mutex must be locked
+func (r *Runner) prune(now time.Time) {
+	for id, e := range r.jobs {
+		if r.expired(e, now) {
+			delete(r.jobs, id)
+		}
+	}
+}
+
+// expired is a method that returns true if a job finished longer than the retention ago, the mutex must be locked
+func (r *Runner) expired(e *entry, now time.Time) bool {
+	return e.job.FinishedAt != nil && now.Sub(*e.job.FinishedAt) > r.retention
+}
+
+// finish is a method that sets the final state of a job, the mutex must be locked
+func (r *Runner) finish(e *entry, result any, err error) {
+	now := time.Now()
+	e.job.FinishedAt = &now
+	e.job.Result = result
+	switch {
+	case err == nil:
+		e.job.State = internal.JobSucceeded
+	case errors.Is(err, context.Canceled):
+		e.job.State = internal.JobCanceled
+	default:
+		e.job.State = internal.JobFailed
+		e.job.Error = err.Error()
+	}
+	e.cancel()
+}
+
+// Progress is a struct that implements the JobProgress interface for a job of a Runner
+type Progress struct {
+	// r is the runner of the job
+	r *Runner
+	// e is the job
+	e *entry
+}
+
+// Add is a method that adds n records to the processed ones
+func (p *Progress) Add(n int) {
+	p.r.mu.Lock()
+	defer p.r.mu.Unlock()
+	p.e.job.Processed += n
+}
+
+// Fail is a method that reports a failed record, which is also processed
+func (p *Progress) Fail(record int, id int, err error) {
+	p.r.mu.Lock()
+	defer p.r.mu.Unlock()
+	p.e.job.Processed++
+	p.e.job.Errors = append(p.e.job.Errors, internal.JobError{Record: record, ID: id, Error: err.Error()})
+}
+
+// newID is a function that returns a random job id
+func newID() (id string, err error) {
+	b := make([]byte, 16)
+	if _, err = rand.Read(b); err != nil {
+		return
+	}
+	id = hex.EncodeToString(b)
+	return
+}
@@ -0,0 +1,140 @@
+package job
+
+import (
+	"app/internal"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// wait is a function that polls a job until it finishes
+func wait(t *testing.T, r *Runner, id string) (j internal.Job) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		j, err := r.Get(id)
+		if err != nil {
+			t.Fatalf("get: %v", err)
+		}
+		if j.State.Finished() {
+			return j
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("job %s did not finish", id)
+	return
+}
+
+func TestRunner_Run(t *testing.T) {
+	r := NewRunner(nil)
+	r.Start()
+	defer r.Stop()
+
+	j, err := r.Submit("default", "import", 3, func(ctx context.Context, p internal.JobProgress) (any, error) {
+		p.Add(2)
+		p.Fail(3, 7, errors.New("invalid"))
+		return "done", nil
+	})
+	if err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+
+	j = wait(t, r, j.ID)
+	if j.State != internal.JobSucceeded || j.Processed != 3 || len(j.Errors) != 1 || j.Result != "done" {
+		t.Fatalf("job = %+v, want succeeded with 3 processed and 1 error", j)
+	}
+}
+
+func TestRunner_Run_Panic(t *testing.T) {
+	r := NewRunner(nil)
+	r.Start()
+	defer r.Stop()
+
+	j, err := r.Submit("default", "import", 1, func(ctx context.Context, p internal.JobProgress) (any, error) {
+		panic("boom")
+	})
+	if err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+
+	// - the job fails and the worker keeps running the next jobs
+	if j = wait(t, r, j.ID); j.State != internal.JobFailed || j.Error == "" {
+		t.Fatalf("job = %+v, want failed", j)
+	}
+	j, err = r.Submit("default", "import", 0, func(ctx context.Context, p internal.JobProgress) (any, error) {
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	if j = wait(t, r, j.ID); j.State != internal.JobSucceeded {
+		t.Fatalf("job = %+v, want succeeded", j)
+	}
+}
+
+func TestRunner_Cancel(t *testing.T) {
+	r := NewRunner(&ConfigRunner{Workers: 1})
+	r.Start()
+	defer r.Stop()
+
+	started := make(chan struct{})
+	running, err := r.Submit("default", "import", 1, func(ctx context.Context, p internal.JobProgress) (any, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	if err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	<-started
+	queued, err := r.Submit("default", "import", 1, func(ctx context.Context, p internal.JobProgress) (any, error) {
+		t.Error("a canceled job must not run")
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+
+	// - a queued job is canceled at once, a running one by its task
+	if j, err := r.Cancel(queued.ID); err != nil || j.State != internal.JobCanceled {
+		t.Fatalf("cancel queued: job = %+v, err = %v, want canceled", j, err)
+	}
+	if _, err := r.Cancel(running.ID); err != nil {
+		t.Fatalf("cancel running: %v", err)
+	}
+	if j := wait(t, r, running.ID); j.State != internal.JobCanceled {
+		t.Fatalf("job = %+v, want canceled", j)
+	}
+	if _, err := r.Cancel(running.ID); !errors.Is(err, internal.ErrJobFinished) {
+		t.Fatalf("cancel finished: err = %v, want ErrJobFinished", err)
+	}
+}
+
+func TestRunner_Retention(t *testing.T) {
+	r := NewRunner(&ConfigRunner{Retention: 20 * time.Millisecond})
+	r.Start()
+	defer r.Stop()
+
+	noop := func(ctx context.Context, p internal.JobProgress) (any, error) { return nil, nil }
+	j, err := r.Submit("default", "import", 0, noop)
+	if err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	wait(t, r, j.ID)
+
+	// - the finished job is forgotten once its retention expires, and removed by the next submit
+	time.Sleep(40 * time.Millisecond)
+	if _, err := r.Get(j.ID); !errors.Is(err, internal.ErrJobNotFound) {
+		t.Fatalf("get: err = %v, want ErrJobNotFound", err)
+	}
+	if _, err := r.Submit("default", "import", 0, noop); err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	r.mu.RLock()
+	_, kept := r.jobs[j.ID]
+	r.mu.RUnlock()
+	if kept {
+		t.Fatal("the expired job is still kept")
+	}
+}
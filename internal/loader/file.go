@@ -0,0 +1,44 @@
+package loader
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"strings"
+)
+
+// gzipFile is a struct that closes both the gzip reader and the underlying file
+type gzipFile struct {
+	*gzip.Reader
+	// file is the compressed file
+	file *os.File
+}
+
+// Close is a method that closes the gzip reader and the file
+func (f *gzipFile) Close() (err error) {
+	err = f.Reader.Close()
+	if errFile := f.file.Close(); err == nil {
+		err = errFile
+	}
+	return
+}
+
+// openFile is a function that opens a file, decompressing it transparently if its extension is .gz
+func openFile(path string) (rc io.ReadCloser, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	if !strings.HasSuffix(path, ".gz") {
+		rc = file
+		return
+	}
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		file.Close()
+		return
+	}
+	rc = &gzipFile{Reader: gz, file: file}
+	return
+}
@@ -0,0 +1,124 @@
+package loader
+
+import (
+	"crypto/sha256"
+	"io"
+	"os"
+	"time"
+)
+
+// NewFileProbe is a function that returns a new instance of FileProbe
+func NewFileProbe(path string) *FileProbe {
+	return &FileProbe{
+		path: path,
+	}
+}
+
+// FileProbe is a struct that implements the Probe interface for a file or a directory,
+// using its modification time and the hash of its content
+type FileProbe struct {
+	// path is the path to the file or directory
+	path string
+	// modTime is the modification time of the loaded state
+	modTime time.Time
+	// hash is the hash of the content of the loaded state
+	hash [sha256.Size]byte
+	// seenModTime is the modification time seen by the last check
+	seenModTime time.Time
+	// seenHash is the hash seen by the last check
+	seenHash [sha256.Size]byte
+}
+
+// Changed is a method that returns true if the content of the path changed since the last commit
+func (p *FileProbe) Changed() (changed bool, err error) {
+	// - mtime: cheap check, most of the polls end here
+	modTime, err := lastModified(p.path)
+	if err != nil {
+		return
+	}
+	if modTime.Equal(p.modTime) {
+		p.seenModTime, p.seenHash = p.modTime, p.hash
+		return
+	}
+
+	// - hash: the path was touched, check if the content changed
+	p.seenModTime = modTime
+	p.seenHash, err = hashPath(p.path)
+	if err != nil {
+		return
+	}
+	if p.seenHash == p.hash {
+		// only touched, there is nothing to reload
+		p.modTime = modTime
+		return
+	}
+	changed = true
+	return
+}
+
+// Commit is a method that marks the state seen by the last call to Changed as loaded
+func (p *FileProbe) Commit() {
+	p.modTime, p.hash = p.seenModTime, p.seenHash
+}
+
+// hashPath is a function that returns the hash of the content of a file,
+// or of the names and contents of the vehicle files of a directory
+func hashPath(path string) (hash [sha256.Size]byte, err error) {
+	files := []string{path}
+	if info, errStat := os.Stat(path); errStat == nil && info.IsDir() {
+		files, err = vehicleFiles(path)
+		if err != nil {
+			return
+		}
+	}
+
+	h := sha256.New()
+	for _, file := range files {
+		io.WriteString(h, file)
+		if err = hashFile(h, file); err != nil {
+			return
+		}
+	}
+	copy(hash[:], h.Sum(nil))
+	return
+}
+
+// hashFile is a function that writes the content of a file into h
+func hashFile(h io.Writer, path string) (err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	_, err = io.Copy(h, file)
+	return
+}
+
+// lastModified is a function that returns the modification time of a file,
+// or the latest one of a directory and its vehicle files
+func lastModified(path string) (modTime time.Time, err error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return
+	}
+	modTime = info.ModTime()
+	if !info.IsDir() {
+		return
+	}
+
+	files, err := vehicleFiles(path)
+	if err != nil {
+		return
+	}
+	for _, file := range files {
+		info, err = os.Stat(file)
+		if err != nil {
+			return
+		}
+		if info.ModTime().After(modTime) {
+			modTime = info.ModTime()
+		}
+	}
+	return
+}
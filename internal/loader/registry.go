@@ -0,0 +1,96 @@
+package loader
+
+import (
+	"app/internal"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+var (
+	// ErrUnknownFormat is an error that represents a path whose format has no registered loader
+	ErrUnknownFormat = errors.New("unknown dataset format")
+)
+
+// Factory is a function that returns a loader for the given path
+type Factory func(path string) internal.VehicleLoader
+
+// NewRegistry is a function that returns a new instance of Registry with the built-in formats,
+// the loaders of http urls use the given configuration
+func NewRegistry(httpCfg *ConfigVehicleHTTP) *Registry {
+	r := &Registry{
+		schemes:    make(map[string]Factory),
+		extensions: make(map[string]Factory),
+		directory: func(path string) internal.VehicleLoader {
+			return NewVehicleDirectory(path)
+		},
+	}
+
+	// built-in formats
+	json := func(path string) internal.VehicleLoader { return NewVehicleJSONFile(path) }
+	ndjson := func(path string) internal.VehicleLoader { return NewVehicleNDJSONFile(path) }
+	r.Register(".json", json)
+	r.Register(".json.gz", json)
+	r.Register(".ndjson", ndjson)
+	r.Register(".ndjson.gz", ndjson)
+	url := func(path string) internal.VehicleLoader { return NewVehicleHTTP(path, httpCfg) }
+	r.RegisterScheme("http", url)
+	r.RegisterScheme("https", url)
+
+	return r
+}
+
+// Registry is a struct that chooses the loader of a dataset by the scheme of its url or the extension of its path
+type Registry struct {
+	// schemes are the factories of loaders by url scheme
+	schemes map[string]Factory
+	// extensions are the factories of loaders by file extension
+	extensions map[string]Factory
+	// directory is the factory of loaders for directories
+	directory Factory
+}
+
+// Register is a method that registers the factory of loaders for an extension, replacing any previous one
+func (r *Registry) Register(ext string, f Factory) {
+	r.extensions[ext] = f
+}
+
+// RegisterScheme is a method that registers the factory of loaders for an url scheme, replacing any previous one
+func (r *Registry) RegisterScheme(scheme string, f Factory) {
+	r.schemes[scheme] = f
+}
+
+// New is a method that returns the loader for the given path or url
+func (r *Registry) New(path string) (ld internal.VehicleLoader, err error) {
+	// - url
+	if scheme, _, ok := strings.Cut(path, "://"); ok {
+		f, ok := r.schemes[scheme]
+		if !ok {
+			err = fmt.Errorf("%w: %s", ErrUnknownFormat, path)
+			return
+		}
+		ld = f(path)
+		return
+	}
+
+	// - directory
+	if info, errStat := os.Stat(path); errStat == nil && info.IsDir() {
+		ld = r.directory(path)
+		return
+	}
+
+	// - file: the longest registered extension wins, so .json.gz is preferred over .gz
+	var match string
+	for ext := range r.extensions {
+		if strings.HasSuffix(path, ext) && len(ext) > len(match) {
+			match = ext
+		}
+	}
+	if match == "" {
+		err = fmt.Errorf("%w: %s", ErrUnknownFormat, path)
+		return
+	}
+	ld = r.extensions[match](path)
+	return
+}
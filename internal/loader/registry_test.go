@@ -0,0 +1,70 @@
+package loader
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeFile is a function that writes a file in dir, gzip compressed if its name ends with .gz, and returns its path
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	data := []byte(content)
+	if filepath.Ext(name) == ".gz" {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		gz.Write(data)
+		gz.Close()
+		data = buf.Bytes()
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+	return path
+}
+
+func TestRegistry_New(t *testing.T) {
+	dir := t.TempDir()
+	const array = `[{"id": 1, "brand": "Hummer"}, {"id": 2, "brand": "Ford"}]`
+	const lines = "{\"id\": 1, \"brand\": \"Hummer\"}\n\n{\"id\": 2, \"brand\": \"Ford\"}\n"
+	vehicles := filepath.Join(dir, "vehicles")
+	if err := os.Mkdir(vehicles, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, vehicles, "1.json", `{"id": 1, "brand": "Hummer"}`)
+	writeFile(t, vehicles, "2.json.gz", `{"id": 2, "brand": "Ford"}`)
+	writeFile(t, vehicles, "README.txt", "not a vehicle")
+
+	paths := map[string]string{
+		"json":      writeFile(t, dir, "vehicles.json", array),
+		"json.gz":   writeFile(t, dir, "vehicles.json.gz", array),
+		"ndjson":    writeFile(t, dir, "vehicles.ndjson", lines),
+		"ndjson.gz": writeFile(t, dir, "vehicles.ndjson.gz", lines),
+		"directory": vehicles,
+	}
+	rg := NewRegistry(nil)
+	for name, path := range paths {
+		t.Run(name, func(t *testing.T) {
+			ld, err := rg.New(path)
+			if err != nil {
+				t.Fatalf("new: %v", err)
+			}
+			v, err := ld.Load()
+			if err != nil {
+				t.Fatalf("load: %v", err)
+			}
+			if len(v) != 2 || v[1].Brand != "Hummer" || v[2].Brand != "Ford" {
+				t.Fatalf("vehicles = %+v, want Hummer and Ford", v)
+			}
+		})
+	}
+
+	// - unknown extension
+	if _, err := rg.New(filepath.Join(dir, "vehicles.csv")); !errors.Is(err, ErrUnknownFormat) {
+		t.Fatalf("csv: err = %v, want ErrUnknownFormat", err)
+	}
+}
@@ -0,0 +1,156 @@
+package loader
+
+import (
+	"app/internal"
+	"errors"
+	"fmt"
+)
+
+// ConflictRule is the rule applied when two sources contain a vehicle with the same id
+type ConflictRule string
+
+const (
+	// ConflictFirstWins keeps the vehicle of the first source that contains it
+	ConflictFirstWins ConflictRule = "first_wins"
+	// ConflictLastWins keeps the vehicle of the last source that contains it
+	ConflictLastWins ConflictRule = "last_wins"
+	// ConflictFail makes the load fail
+	ConflictFail ConflictRule = "fail"
+	// ConflictMerge merges the vehicles field by field, the non-zero fields of later sources win
+	ConflictMerge ConflictRule = "merge"
+)
+
+var (
+	// ErrUnknownConflictRule is an error that represents an unknown conflict rule
+	ErrUnknownConflictRule = errors.New("unknown conflict rule")
+	// ErrVehicleConflict is an error that represents a vehicle found in more than one source
+	ErrVehicleConflict = errors.New("vehicle found in more than one source")
+)
+
+// ParseConflictRule is a function that returns the conflict rule with the given name
+func ParseConflictRule(name string) (rule ConflictRule, err error) {
+	rule = ConflictRule(name)
+	switch rule {
+	case ConflictFirstWins, ConflictLastWins, ConflictFail, ConflictMerge:
+	default:
+		err = fmt.Errorf("%w: %s", ErrUnknownConflictRule, name)
+	}
+	return
+}
+
+// VehicleSource is a struct that represents a named loader of vehicles
+type VehicleSource struct {
+	// Name is the name of the source, recorded as the provenance of its vehicles
+	Name string
+	// Loader is the loader of the vehicles of the source
+	Loader internal.VehicleLoader
+}
+
+// NewVehicleComposite is a function that returns a new instance of VehicleComposite
+func NewVehicleComposite(sources []VehicleSource, rule ConflictRule) *VehicleComposite {
+	// default values
+	defaultRule := ConflictFail
+	if rule != "" {
+		defaultRule = rule
+	}
+
+	return &VehicleComposite{
+		sources: sources,
+		rule:    defaultRule,
+	}
+}
+
+// VehicleComposite is a struct that implements the LoaderVehicle interface combining other loaders in order
+type VehicleComposite struct {
+	// sources are the sources of vehicles, in order of precedence
+	sources []VehicleSource
+	// rule is the rule applied on id conflicts
+	rule ConflictRule
+}
+
+// Load is a method that loads the vehicles of every source
+func (l *VehicleComposite) Load() (v map[int]internal.Vehicle, err error) {
+	v = make(map[int]internal.Vehicle)
+
+	for _, src := range l.sources {
+		var vs map[int]internal.Vehicle
+		vs, err = src.Loader.Load()
+		if err != nil {
+			err = fmt.Errorf("source %s: %w", src.Name, err)
+			return
+		}
+
+		for id, vh := range vs {
+			vh.Source = src.Name
+
+			// check if id already exists
+			prev, ok := v[id]
+			if !ok {
+				v[id] = vh
+				continue
+			}
+
+			switch l.rule {
+			case ConflictFirstWins:
+			case ConflictLastWins:
+				v[id] = vh
+			case ConflictMerge:
+				v[id] = mergeVehicle(prev, vh)
+			case ConflictFail:
+				err = fmt.Errorf("%w: id %d in %s and %s", ErrVehicleConflict, id, prev.Source, src.Name)
+				return
+			default:
+				err = fmt.Errorf("%w: %s", ErrUnknownConflictRule, l.rule)
+				return
+			}
+		}
+	}
+
+	return
+}
+
+// mergeVehicle is a function that merges two vehicles, the non-zero fields of next override the ones of prev
+func mergeVehicle(prev, next internal.Vehicle) (v internal.Vehicle) {
+	v = prev
+	if next.Brand != "" {
+		v.Brand = next.Brand
+	}
+	if next.Model != "" {
+		v.Model = next.Model
+	}
+	if next.Registration != "" {
+		v.Registration = next.Registration
+	}
+	if next.Color != "" {
+		v.Color = next.Color
+	}
+	if next.FabricationYear != 0 {
+		v.FabricationYear = next.FabricationYear
+	}
+	if next.Capacity != 0 {
+		v.Capacity = next.Capacity
+	}
+	if next.MaxSpeed != 0 {
+		v.MaxSpeed = next.MaxSpeed
+	}
+	if next.FuelType != "" {
+		v.FuelType = next.FuelType
+	}
+	if next.Transmission != "" {
+		v.Transmission = next.Transmission
+	}
+	if next.Weight != 0 {
+		v.Weight = next.Weight
+	}
+	if next.Height != 0 {
+		v.Height = next.Height
+	}
+	if next.Length != 0 {
+		v.Length = next.Length
+	}
+	if next.Width != 0 {
+		v.Width = next.Width
+	}
+	v.Source = prev.Source + "," + next.Source
+	return
+}
@@ -0,0 +1,84 @@
+package loader
+
+import (
+	"app/internal"
+	"errors"
+	"testing"
+)
+
+// vehicleStub is a loader that returns fixed vehicles, or an error
+type vehicleStub struct {
+	// v are the vehicles returned by Load
+	v map[int]internal.Vehicle
+	// err is the error returned by Load
+	err error
+}
+
+// Load is a method that returns the vehicles of the stub
+func (l vehicleStub) Load() (map[int]internal.Vehicle, error) {
+	return l.v, l.err
+}
+
+// newSources is a function that returns the sources north and south, which both have the vehicle 1
+func newSources() []VehicleSource {
+	north := internal.Vehicle{Id: 1}
+	north.Brand, north.Color, north.MaxSpeed = "Ford", "red", 120
+	south := internal.Vehicle{Id: 1}
+	south.Brand, south.MaxSpeed = "Fiat", 150
+	other := internal.Vehicle{Id: 2}
+	other.Brand = "Hummer"
+
+	return []VehicleSource{
+		{Name: "north.json", Loader: vehicleStub{v: map[int]internal.Vehicle{1: north}}},
+		{Name: "south.json", Loader: vehicleStub{v: map[int]internal.Vehicle{1: south, 2: other}}},
+	}
+}
+
+func TestVehicleComposite_Load(t *testing.T) {
+	cases := []struct {
+		rule   ConflictRule
+		brand  string
+		color  string
+		speed  float64
+		source string
+	}{
+		{rule: ConflictFirstWins, brand: "Ford", color: "red", speed: 120, source: "north.json"},
+		{rule: ConflictLastWins, brand: "Fiat", color: "", speed: 150, source: "south.json"},
+		{rule: ConflictMerge, brand: "Fiat", color: "red", speed: 150, source: "north.json,south.json"},
+	}
+	for _, c := range cases {
+		t.Run(string(c.rule), func(t *testing.T) {
+			v, err := NewVehicleComposite(newSources(), c.rule).Load()
+			if err != nil {
+				t.Fatalf("load: %v", err)
+			}
+			if len(v) != 2 || v[2].Source != "south.json" {
+				t.Fatalf("vehicles = %+v, want 2 with the vehicle 2 from south.json", v)
+			}
+			vh := v[1]
+			if vh.Brand != c.brand || vh.Color != c.color || vh.MaxSpeed != c.speed || vh.Source != c.source {
+				t.Fatalf("vehicle 1 = %s %q %v from %s, want %s %q %v from %s",
+					vh.Brand, vh.Color, vh.MaxSpeed, vh.Source, c.brand, c.color, c.speed, c.source)
+			}
+		})
+	}
+}
+
+func TestVehicleComposite_Load_Errors(t *testing.T) {
+	// - conflict with the fail rule, the default one
+	if _, err := NewVehicleComposite(newSources(), "").Load(); !errors.Is(err, ErrVehicleConflict) {
+		t.Fatalf("conflict: err = %v, want ErrVehicleConflict", err)
+	}
+
+	// - failing source
+	errSource := errors.New("unreadable")
+	sources := append(newSources(), VehicleSource{Name: "east.json", Loader: vehicleStub{err: errSource}})
+	if _, err := NewVehicleComposite(sources, ConflictLastWins).Load(); !errors.Is(err, errSource) {
+		t.Fatalf("failing source: err = %v, want the error of the source", err)
+	}
+
+	// - unknown rule
+	if _, err := ParseConflictRule("random"); !errors.Is(err, ErrUnknownConflictRule) {
+		t.Fatalf("parse: err = %v, want ErrUnknownConflictRule", err)
+	}
+}
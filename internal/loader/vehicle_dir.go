@@ -0,0 +1,75 @@
+package loader
+
+import (
+	"app/internal"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// NewVehicleDirectory is a function that returns a new instance of VehicleDirectory
+func NewVehicleDirectory(path string) *VehicleDirectory {
+	return &VehicleDirectory{
+		path: path,
+	}
+}
+
+// VehicleDirectory is a struct that implements the LoaderVehicle interface for a directory with one JSON file per vehicle
+type VehicleDirectory struct {
+	// path is the path to the directory that contains the files
+	path string
+}
+
+// Load is a method that loads the vehicles
+func (l *VehicleDirectory) Load() (v map[int]internal.Vehicle, err error) {
+	// list files
+	files, err := vehicleFiles(l.path)
+	if err != nil {
+		return
+	}
+
+	// decode files
+	v = make(map[int]internal.Vehicle)
+	for _, path := range files {
+		var vh VehicleJSON
+		vh, err = decodeVehicleFile(path)
+		if err != nil {
+			err = fmt.Errorf("%s: %w", filepath.Base(path), err)
+			return
+		}
+		v[vh.Id] = vh.Vehicle()
+	}
+
+	return
+}
+
+// decodeVehicleFile is a function that decodes a file that contains one vehicle in JSON format
+func decodeVehicleFile(path string) (vh VehicleJSON, err error) {
+	file, err := openFile(path)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	err = json.NewDecoder(file).Decode(&vh)
+	return
+}
+
+// vehicleFiles is a function that returns the paths of the vehicle files of a directory, sorted by name
+func vehicleFiles(dir string) (paths []string, err error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !(strings.HasSuffix(name, ".json") || strings.HasSuffix(name, ".json.gz")) {
+			continue
+		}
+		paths = append(paths, filepath.Join(dir, name))
+	}
+	return
+}
@@ -0,0 +1,200 @@
+package loader
+
+import (
+	"app/internal"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrUnexpectedStatus is an error that represents an unexpected status code of the server
+	ErrUnexpectedStatus = errors.New("unexpected status code")
+)
+
+// ConfigVehicleHTTP is a struct that represents the configuration for VehicleHTTP
+type ConfigVehicleHTTP struct {
+	// Client is the client used for the requests, its timeout is replaced by Timeout
+	Client *http.Client
+	// Timeout is the time limit of each request
+	Timeout time.Duration
+	// Retries is the amount of retries of a failed request, 0 means the default and a negative value disables them
+	Retries int
+	// RetryDelay is the delay before the first retry, doubled on every retry
+	RetryDelay time.Duration
+}
+
+// NewVehicleHTTP is a function that returns a new instance of VehicleHTTP
+func NewVehicleHTTP(url string, cfg *ConfigVehicleHTTP) *VehicleHTTP {
+	// default values
+	defaultConfig := &ConfigVehicleHTTP{
+		Client:     &http.Client{},
+		Timeout:    10 * time.Second,
+		Retries:    3,
+		RetryDelay: 500 * time.Millisecond,
+	}
+	if cfg != nil {
+		if cfg.Client != nil {
+			defaultConfig.Client = cfg.Client
+		}
+		if cfg.Timeout > 0 {
+			defaultConfig.Timeout = cfg.Timeout
+		}
+		if cfg.Retries > 0 {
+			defaultConfig.Retries = cfg.Retries
+		}
+		if cfg.Retries < 0 {
+			defaultConfig.Retries = 0
+		}
+		if cfg.RetryDelay > 0 {
+			defaultConfig.RetryDelay = cfg.RetryDelay
+		}
+	}
+	client := *defaultConfig.Client
+	client.Timeout = defaultConfig.Timeout
+
+	return &VehicleHTTP{
+		url:        url,
+		client:     &client,
+		retries:    defaultConfig.Retries,
+		retryDelay: defaultConfig.RetryDelay,
+	}
+}
+
+// VehicleHTTP is a struct that implements the LoaderVehicle and Probe interfaces for a dataset served over HTTP.
+// Requests are conditional, so an unchanged dataset is not downloaded again
+type VehicleHTTP struct {
+	// url is the url of the dataset, in JSON or, if the url ends with .ndjson, in NDJSON format
+	url string
+	// client is the client used for the requests
+	client *http.Client
+	// retries is the amount of retries of a failed request
+	retries int
+	// retryDelay is the delay before the first retry
+	retryDelay time.Duration
+
+	// mu guards the fields below
+	mu sync.Mutex
+	// db is the last dataset downloaded
+	db map[int]internal.Vehicle
+	// etag is the ETag of the last dataset downloaded
+	etag string
+	// lastModified is the Last-Modified of the last dataset downloaded
+	lastModified string
+	// version is incremented every time a new dataset is downloaded
+	version int
+	// seenVersion is the version seen by the last call to Changed
+	seenVersion int
+	// committedVersion is the version marked as loaded
+	committedVersion int
+}
+
+// Load is a method that loads the vehicles, downloading them only if they changed
+func (l *VehicleHTTP) Load() (v map[int]internal.Vehicle, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err = l.fetch(); err != nil {
+		return
+	}
+
+	// copy db
+	v = make(map[int]internal.Vehicle)
+	for key, value := range l.db {
+		v[key] = value
+	}
+	return
+}
+
+// Changed is a method that returns true if the dataset changed since the last commit
+func (l *VehicleHTTP) Changed() (changed bool, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err = l.fetch(); err != nil {
+		return
+	}
+	l.seenVersion = l.version
+	changed = l.seenVersion != l.committedVersion
+	return
+}
+
+// Commit is a method that marks the dataset seen by the last call to Changed as loaded
+func (l *VehicleHTTP) Commit() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.committedVersion = l.seenVersion
+}
+
+// fetch is a method that downloads the dataset if it changed since the last download, retrying failed requests
+func (l *VehicleHTTP) fetch() (err error) {
+	delay := l.retryDelay
+	for attempt := 0; ; attempt++ {
+		var retry bool
+		retry, err = l.fetchOnce()
+		if err == nil || !retry || attempt >= l.retries {
+			return
+		}
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// fetchOnce is a method that makes a single conditional request for the dataset
+func (l *VehicleHTTP) fetchOnce() (retry bool, err error) {
+	// request
+	req, err := http.NewRequest(http.MethodGet, l.url, nil)
+	if err != nil {
+		return
+	}
+	if l.db != nil {
+		if l.etag != "" {
+			req.Header.Set("If-None-Match", l.etag)
+		}
+		if l.lastModified != "" {
+			req.Header.Set("If-Modified-Since", l.lastModified)
+		}
+	}
+
+	res, err := l.client.Do(req)
+	if err != nil {
+		// network errors and timeouts are worth a retry
+		retry = true
+		return
+	}
+	defer res.Body.Close()
+
+	// response
+	switch {
+	case res.StatusCode == http.StatusNotModified:
+		return
+	case res.StatusCode == http.StatusTooManyRequests || res.StatusCode >= 500:
+		retry = true
+		err = fmt.Errorf("%w: %s", ErrUnexpectedStatus, res.Status)
+		return
+	case res.StatusCode != http.StatusOK:
+		err = fmt.Errorf("%w: %s", ErrUnexpectedStatus, res.Status)
+		return
+	}
+
+	// decode dataset
+	var db map[int]internal.Vehicle
+	if strings.HasSuffix(req.URL.Path, ".ndjson") || strings.HasPrefix(res.Header.Get("Content-Type"), "application/x-ndjson") {
+		db, err = decodeNDJSON(res.Body)
+	} else {
+		db, err = decodeJSON(res.Body)
+	}
+	if err != nil {
+		return
+	}
+
+	l.db = db
+	l.etag = res.Header.Get("ETag")
+	l.lastModified = res.Header.Get("Last-Modified")
+	l.version++
+	return
+}
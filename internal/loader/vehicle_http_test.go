@@ -0,0 +1,197 @@
+package loader
+
+import (
+	"app/internal"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// dataset is a stand-in server of a dataset, it answers with 304 when the ETag of the client is the current one
+type dataset struct {
+	// mu guards the fields below
+	mu sync.Mutex
+	// version is the version of the dataset, it is also its ETag
+	version int
+	// brand is the brand of the only vehicle of the dataset
+	brand string
+	// downloads is the amount of responses with the dataset
+	downloads int
+	// notModified is the amount of responses with 304
+	notModified int
+}
+
+// set is a method that replaces the dataset with a new version
+func (d *dataset) set(brand string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.version++
+	d.brand = brand
+}
+
+// counts is a method that returns the amount of downloads and of 304 responses
+func (d *dataset) counts() (downloads, notModified int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.downloads, d.notModified
+}
+
+// ServeHTTP is a method that serves the dataset
+func (d *dataset) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	etag := fmt.Sprintf(`"v%d"`, d.version)
+	if r.Header.Get("If-None-Match") == etag {
+		d.notModified++
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	d.downloads++
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprintf(w, `[{"id": 1, "brand": %q, "model": "H2", "year": 2008, "max_speed": 143}]`, d.brand)
+}
+
+func TestVehicleHTTP_Load_NotModified(t *testing.T) {
+	ds := &dataset{}
+	ds.set("Hummer")
+	srv := httptest.NewServer(ds)
+	defer srv.Close()
+	ld := NewVehicleHTTP(srv.URL, nil)
+
+	// - first load downloads the dataset
+	v, err := ld.Load()
+	if err != nil {
+		t.Fatalf("first load: %v", err)
+	}
+	if v[1].Brand != "Hummer" {
+		t.Fatalf("first load: brand = %q, want Hummer", v[1].Brand)
+	}
+
+	// - second load is answered with 304 and returns the same vehicles
+	v, err = ld.Load()
+	if err != nil {
+		t.Fatalf("second load: %v", err)
+	}
+	if v[1].Brand != "Hummer" {
+		t.Fatalf("second load: brand = %q, want Hummer", v[1].Brand)
+	}
+	if downloads, notModified := ds.counts(); downloads != 1 || notModified != 1 {
+		t.Fatalf("downloads = %d, 304 responses = %d, want 1 and 1", downloads, notModified)
+	}
+}
+
+func TestVehicleHTTP_Load_Retries(t *testing.T) {
+	cases := []struct {
+		name     string
+		retries  int
+		failures int32
+		requests int32
+		wantErr  bool
+	}{
+		{name: "recovers after 5xx", retries: 3, failures: 2, requests: 3},
+		{name: "gives up after the retries", retries: 1, failures: 5, requests: 2, wantErr: true},
+		{name: "negative disables retries", retries: -1, failures: 1, requests: 1, wantErr: true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var requests int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if atomic.AddInt32(&requests, 1) <= c.failures {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				fmt.Fprint(w, `[{"id": 1, "brand": "Hummer"}]`)
+			}))
+			defer srv.Close()
+			ld := NewVehicleHTTP(srv.URL, &ConfigVehicleHTTP{Retries: c.retries, RetryDelay: time.Millisecond})
+
+			_, err := ld.Load()
+			if c.wantErr != (err != nil) {
+				t.Fatalf("err = %v, want error %t", err, c.wantErr)
+			}
+			if c.wantErr && !errors.Is(err, ErrUnexpectedStatus) {
+				t.Fatalf("err = %v, want ErrUnexpectedStatus", err)
+			}
+			if got := atomic.LoadInt32(&requests); got != c.requests {
+				t.Fatalf("requests = %d, want %d", got, c.requests)
+			}
+		})
+	}
+}
+
+func TestVehicleHTTP_Load_Timeout(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+	ld := NewVehicleHTTP(srv.URL, &ConfigVehicleHTTP{Timeout: 50 * time.Millisecond, Retries: -1})
+
+	start := time.Now()
+	_, err := ld.Load()
+	if err == nil {
+		t.Fatal("err = nil, want a timeout")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("load took %s, want it cut by the timeout", elapsed)
+	}
+}
+
+func TestVehicleHTTP_WatcherCheck(t *testing.T) {
+	ds := &dataset{}
+	ds.set("Hummer")
+	srv := httptest.NewServer(ds)
+	defer srv.Close()
+	ld := NewVehicleHTTP(srv.URL, nil)
+
+	var mu sync.Mutex
+	var applied map[int]internal.Vehicle
+	w := NewVehicleWatcher("url", []Probe{ld}, ld, 1, time.Hour, func(v map[int]internal.Vehicle) {
+		mu.Lock()
+		defer mu.Unlock()
+		applied = v
+	})
+	if err := w.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	defer w.Stop()
+
+	// - unchanged dataset: Changed is false and nothing is reloaded
+	reloaded, err := w.Check()
+	if err != nil || reloaded {
+		t.Fatalf("unchanged: reloaded = %t, err = %v, want false and nil", reloaded, err)
+	}
+
+	// - new dataset: Changed is true, the vehicles are reloaded and Commit marks them as loaded
+	ds.set("Chevrolet")
+	reloaded, err = w.Check()
+	if err != nil || !reloaded {
+		t.Fatalf("changed: reloaded = %t, err = %v, want true and nil", reloaded, err)
+	}
+	mu.Lock()
+	brand := applied[1].Brand
+	mu.Unlock()
+	if brand != "Chevrolet" {
+		t.Fatalf("changed: brand = %q, want Chevrolet", brand)
+	}
+
+	// - committed dataset: the next check does not reload it again
+	reloaded, err = w.Check()
+	if err != nil || reloaded {
+		t.Fatalf("committed: reloaded = %t, err = %v, want false and nil", reloaded, err)
+	}
+	if s := w.Status(); s.Reloads != 1 || s.Items != 1 {
+		t.Fatalf("status = %+v, want 1 reload of 1 item", s)
+	}
+}
@@ -3,7 +3,7 @@ package loader
 import (
 	"app/internal"
 	"encoding/json"
-	"os"
+	"io"
 )
 
 // NewVehicleJSONFile is a function that returns a new instance of VehicleJSONFile
@@ -40,15 +40,21 @@ type VehicleJSON struct {
 // Load is a method that loads the vehicles
 func (l *VehicleJSONFile) Load() (v map[int]internal.Vehicle, err error) {
 	// open file
-	file, err := os.Open(l.path)
+	file, err := openFile(l.path)
 	if err != nil {
 		return
 	}
 	defer file.Close()
 
 	// decode file
+	v, err = decodeJSON(file)
+	return
+}
+
+// decodeJSON is a function that decodes an array of vehicles in JSON format
+func decodeJSON(r io.Reader) (v map[int]internal.Vehicle, err error) {
 	var vehiclesJSON []VehicleJSON
-	err = json.NewDecoder(file).Decode(&vehiclesJSON)
+	err = json.NewDecoder(r).Decode(&vehiclesJSON)
 	if err != nil {
 		return
 	}
@@ -56,27 +62,32 @@ func (l *VehicleJSONFile) Load() (v map[int]internal.Vehicle, err error) {
 	// serialize vehicles
 	v = make(map[int]internal.Vehicle)
 	for _, vh := range vehiclesJSON {
-		v[vh.Id] = internal.Vehicle{
-			Id: vh.Id,
-			VehicleAttributes: internal.VehicleAttributes{
-				Brand:           vh.Brand,
-				Model:           vh.Model,
-				Registration:    vh.Registration,
-				Color:           vh.Color,
-				FabricationYear: vh.FabricationYear,
-				Capacity:        vh.Capacity,
-				MaxSpeed:        vh.MaxSpeed,
-				FuelType:        vh.FuelType,
-				Transmission:    vh.Transmission,
-				Weight:          vh.Weight,
-				Dimensions: internal.Dimensions{
-					Height: vh.Height,
-					Length: vh.Length,
-					Width:  vh.Width,
-				},
-			},
-		}
+		v[vh.Id] = vh.Vehicle()
 	}
 
 	return
 }
+
+// Vehicle is a method that serializes the vehicle in JSON format into a vehicle
+func (vh VehicleJSON) Vehicle() internal.Vehicle {
+	return internal.Vehicle{
+		Id: vh.Id,
+		VehicleAttributes: internal.VehicleAttributes{
+			Brand:           vh.Brand,
+			Model:           vh.Model,
+			Registration:    vh.Registration,
+			Color:           vh.Color,
+			FabricationYear: vh.FabricationYear,
+			Capacity:        vh.Capacity,
+			MaxSpeed:        vh.MaxSpeed,
+			FuelType:        vh.FuelType,
+			Transmission:    vh.Transmission,
+			Weight:          vh.Weight,
+			Dimensions: internal.Dimensions{
+				Height: vh.Height,
+				Length: vh.Length,
+				Width:  vh.Width,
+			},
+		},
+	}
+}
@@ -0,0 +1,57 @@
+package loader
+
+import (
+	"app/internal"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// NewVehicleNDJSONFile is a function that returns a new instance of VehicleNDJSONFile
+func NewVehicleNDJSONFile(path string) *VehicleNDJSONFile {
+	return &VehicleNDJSONFile{
+		path: path,
+	}
+}
+
+// VehicleNDJSONFile is a struct that implements the LoaderVehicle interface for newline delimited JSON files
+type VehicleNDJSONFile struct {
+	// path is the path to the file that contains one vehicle in JSON format per line
+	path string
+}
+
+// Load is a method that loads the vehicles
+func (l *VehicleNDJSONFile) Load() (v map[int]internal.Vehicle, err error) {
+	// open file
+	file, err := openFile(l.path)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	// decode file
+	v, err = decodeNDJSON(file)
+	return
+}
+
+// decodeNDJSON is a function that decodes vehicles in JSON format, one per line
+func decodeNDJSON(r io.Reader) (v map[int]internal.Vehicle, err error) {
+	v = make(map[int]internal.Vehicle)
+	dec := json.NewDecoder(r)
+	for line := 1; ; line++ {
+		var vh VehicleJSON
+		err = dec.Decode(&vh)
+		if errors.Is(err, io.EOF) {
+			err = nil
+			break
+		}
+		if err != nil {
+			err = fmt.Errorf("record %d: %w", line, err)
+			return
+		}
+		v[vh.Id] = vh.Vehicle()
+	}
+
+	return
+}
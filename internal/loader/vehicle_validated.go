@@ -0,0 +1,51 @@
+package loader
+
+import (
+	"app/internal"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// NewVehicleValidated is a function that returns a new instance of VehicleValidated
+func NewVehicleValidated(ld internal.VehicleLoader, vl internal.VehicleValidator) *VehicleValidated {
+	return &VehicleValidated{
+		ld: ld,
+		vl: vl,
+	}
+}
+
+// VehicleValidated is a struct that implements the LoaderVehicle interface validating the vehicles of another loader
+type VehicleValidated struct {
+	// ld is the loader of the vehicles
+	ld internal.VehicleLoader
+	// vl is the validator of the vehicles
+	vl internal.VehicleValidator
+}
+
+// Load is a method that loads the vehicles, failing with the violations of every invalid vehicle
+func (l *VehicleValidated) Load() (v map[int]internal.Vehicle, err error) {
+	v, err = l.ld.Load()
+	if err != nil {
+		return
+	}
+
+	// validate vehicles, in order of id so the error is stable
+	ids := make([]int, 0, len(v))
+	for id := range v {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	var errs []error
+	for _, id := range ids {
+		if errValidate := l.vl.Validate(v[id]); errValidate != nil {
+			errs = append(errs, fmt.Errorf("vehicle %d: %w", id, errValidate))
+		}
+	}
+	if len(errs) > 0 {
+		v = nil
+		err = errors.Join(errs...)
+	}
+	return
+}
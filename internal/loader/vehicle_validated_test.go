@@ -0,0 +1,28 @@
+package loader
+
+import (
+	"app/internal"
+	"app/internal/validation"
+	"strings"
+	"testing"
+)
+
+func TestVehicleValidated_Load(t *testing.T) {
+	vl := validation.NewVehicleRules([]validation.Rule{{Field: "brand", Required: true}})
+
+	// - valid
+	ld := NewVehicleValidated(vehicleStub{v: newSources()[1].Loader.(vehicleStub).v}, vl)
+	if v, err := ld.Load(); err != nil || len(v) != 2 {
+		t.Fatalf("valid: vehicles = %v, err = %v, want 2 and nil", v, err)
+	}
+
+	// - invalid vehicles are all reported, and nothing is loaded
+	ld = NewVehicleValidated(vehicleStub{v: map[int]internal.Vehicle{3: {Id: 3}, 4: {Id: 4}}}, vl)
+	v, err := ld.Load()
+	if err == nil || v != nil {
+		t.Fatalf("invalid: vehicles = %v, err = %v, want nil and an error", v, err)
+	}
+	if msg := err.Error(); !strings.Contains(msg, "vehicle 3") || !strings.Contains(msg, "vehicle 4") {
+		t.Fatalf("err = %q, want the vehicles 3 and 4", msg)
+	}
+}
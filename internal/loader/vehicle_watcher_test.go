@@ -0,0 +1,95 @@
+package loader
+
+import (
+	"app/internal"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// writeDataset is a function that writes a dataset file and sets its modification time,
+// so every write is seen as a change regardless of the resolution of the file system
+func writeDataset(t *testing.T, path, content string, modTime time.Time) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+}
+
+func TestVehicleWatcher_Check(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vehicles.json")
+	start := time.Now().Add(-time.Hour)
+	writeDataset(t, path, `[{"id": 1, "brand": "Hummer"}]`, start)
+
+	var mu sync.Mutex
+	var applied map[int]internal.Vehicle
+	w := NewVehicleWatcher(path, []Probe{NewFileProbe(path)}, NewVehicleJSONFile(path), 1, time.Hour, func(v map[int]internal.Vehicle) {
+		mu.Lock()
+		defer mu.Unlock()
+		applied = v
+	})
+	if err := w.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	defer w.Stop()
+	if s := w.Status(); s.Items != 1 || s.Reloads != 0 {
+		t.Fatalf("started: status = %+v, want the vehicle already loaded and no reloads", s)
+	}
+	brands := func() (b []string) {
+		mu.Lock()
+		defer mu.Unlock()
+		for id := 1; id <= len(applied); id++ {
+			b = append(b, applied[id].Brand)
+		}
+		return
+	}
+
+	// - unchanged file: nothing is reloaded
+	if reloaded, err := w.Check(); err != nil || reloaded {
+		t.Fatalf("unchanged: reloaded = %t, err = %v, want false and nil", reloaded, err)
+	}
+
+	// - touched file with the same content: the hash avoids the reload
+	writeDataset(t, path, `[{"id": 1, "brand": "Hummer"}]`, start.Add(time.Minute))
+	if reloaded, err := w.Check(); err != nil || reloaded {
+		t.Fatalf("touched: reloaded = %t, err = %v, want false and nil", reloaded, err)
+	}
+
+	// - changed file: the vehicles are reloaded
+	writeDataset(t, path, `[{"id": 1, "brand": "Hummer"}, {"id": 2, "brand": "Ford"}]`, start.Add(2*time.Minute))
+	if reloaded, err := w.Check(); err != nil || !reloaded {
+		t.Fatalf("changed: reloaded = %t, err = %v, want true and nil", reloaded, err)
+	}
+	if b := brands(); len(b) != 2 || b[1] != "Ford" {
+		t.Fatalf("changed: brands = %v, want Hummer and Ford", b)
+	}
+	if s := w.Status(); s.Reloads != 1 || s.Items != 2 || s.LastError != "" {
+		t.Fatalf("changed: status = %+v, want 1 reload of 2 vehicles", s)
+	}
+
+	// - broken file: the previous vehicles are kept and the error is reported
+	writeDataset(t, path, `[{"id": 3,`, start.Add(3*time.Minute))
+	if reloaded, err := w.Check(); err == nil || reloaded {
+		t.Fatalf("broken: reloaded = %t, err = %v, want false and an error", reloaded, err)
+	}
+	if b := brands(); len(b) != 2 {
+		t.Fatalf("broken: brands = %v, want the previous ones", b)
+	}
+	if s := w.Status(); s.LastError == "" || s.Reloads != 1 {
+		t.Fatalf("broken: status = %+v, want the error and 1 reload", s)
+	}
+
+	// - fixed file: the next reload clears the error
+	writeDataset(t, path, `[{"id": 1, "brand": "Chevrolet"}]`, start.Add(4*time.Minute))
+	if reloaded, err := w.Check(); err != nil || !reloaded {
+		t.Fatalf("fixed: reloaded = %t, err = %v, want true and nil", reloaded, err)
+	}
+	if s := w.Status(); s.LastError != "" || s.Reloads != 2 || s.Items != 1 {
+		t.Fatalf("fixed: status = %+v, want no error and 2 reloads", s)
+	}
+}
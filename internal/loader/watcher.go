@@ -0,0 +1,168 @@
+package loader
+
+import (
+	"app/internal"
+	"sync"
+	"time"
+)
+
+// Probe is an interface that tells whether a source changed since it was last loaded
+type Probe interface {
+	// Changed is a method that returns true if the source changed since the last commit
+	Changed() (changed bool, err error)
+	// Commit is a method that marks the state seen by the last call to Changed as loaded
+	Commit()
+}
+
+// NewWatcher is a function that returns a new instance of Watcher, items is the amount of items already loaded from the sources
+func NewWatcher(source string, probes []Probe, items int, interval time.Duration, reload func() (items int, err error)) *Watcher {
+	// default values
+	defaultInterval := 5 * time.Second
+	if interval > 0 {
+		defaultInterval = interval
+	}
+
+	return &Watcher{
+		probes:   probes,
+		reload:   reload,
+		interval: defaultInterval,
+		loaded:   items,
+		status:   internal.ReloadStatus{Source: source},
+	}
+}
+
+// NewVehicleWatcher is a function that returns a new instance of Watcher that loads the vehicles with ld and passes them to apply,
+// items is the amount of vehicles already loaded
+func NewVehicleWatcher(source string, probes []Probe, ld internal.VehicleLoader, items int, interval time.Duration, apply func(v map[int]internal.Vehicle)) *Watcher {
+	return NewWatcher(source, probes, items, interval, func() (items int, err error) {
+		v, err := ld.Load()
+		if err != nil {
+			return
+		}
+		apply(v)
+		items = len(v)
+		return
+	})
+}
+
+// Watcher is a struct that polls some sources and reloads them when any of them changes
+type Watcher struct {
+	// probes are the checks of the sources that are being watched
+	probes []Probe
+	// reload is the function that reloads the sources and returns the amount of items loaded.
+	// If it fails, whatever was loaded before must be kept
+	reload func() (items int, err error)
+	// interval is the time between two checks of the sources
+	interval time.Duration
+	// loaded is the amount of items loaded from the sources before the watcher was started
+	loaded int
+
+	// mu guards the fields below
+	mu sync.Mutex
+	// status is the status of the reloads
+	status internal.ReloadStatus
+	// stop is the channel used to stop the polling
+	stop chan struct{}
+}
+
+// Start is a method that takes the current state of the sources, with the items already loaded from them, and starts polling them
+func (w *Watcher) Start() (err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	// take the current state of the sources, which is the one already loaded
+	for _, p := range w.probes {
+		if _, err = p.Changed(); err != nil {
+			return
+		}
+		p.Commit()
+	}
+	w.status.Items = w.loaded
+	w.status.LastReload = time.Now()
+
+	// start polling
+	w.stop = make(chan struct{})
+	go w.poll(w.stop)
+	return
+}
+
+// Stop is a method that stops polling the sources
+func (w *Watcher) Stop() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.stop != nil {
+		close(w.stop)
+		w.stop = nil
+	}
+}
+
+// poll is a method that checks the sources every interval until stop is closed
+func (w *Watcher) poll(stop chan struct{}) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			w.Check()
+		}
+	}
+}
+
+// Check is a method that reloads the sources if any of them changed since the last check
+func (w *Watcher) Check() (reloaded bool, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.status.LastCheck = time.Now()
+
+	// check every source, so all of them see their current state before the reload
+	changed := false
+	for _, p := range w.probes {
+		var c bool
+		c, err = p.Changed()
+		if err != nil {
+			w.fail(err)
+			return
+		}
+		changed = changed || c
+	}
+	if !changed {
+		return
+	}
+
+	// reload
+	items, err := w.reload()
+	if err != nil {
+		// the previous data is kept, the sources are checked again on the next poll
+		w.fail(err)
+		return
+	}
+
+	for _, p := range w.probes {
+		p.Commit()
+	}
+	w.status.Items = items
+	w.status.Reloads++
+	w.status.LastReload = w.status.LastCheck
+	w.status.LastError = ""
+	reloaded = true
+	return
+}
+
+// Status is a method that returns the status of the reloads
+func (w *Watcher) Status() (s internal.ReloadStatus) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	s = w.status
+	return
+}
+
+// fail is a method that records a failed reload in the status
+func (w *Watcher) fail(err error) {
+	w.status.LastError = err.Error()
+	w.status.LastErrorAt = w.status.LastCheck
+}
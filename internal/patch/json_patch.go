@@ -0,0 +1,251 @@
+package patch
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrInvalidPatch is an error for a malformed patch or one that can not be applied to the document
+	ErrInvalidPatch = errors.New("patch: invalid patch")
+	// ErrTestFailed is an error for a test operation whose value does not match the document
+	ErrTestFailed = errors.New("patch: test failed")
+)
+
+// Operation is a struct that represents an operation of a JSON Patch (RFC 6902)
+type Operation struct {
+	// Op is the operation: add, remove, replace, move, copy or test
+	Op string `json:"op"`
+	// Path is the JSON pointer to the target of the operation
+	Path string `json:"path"`
+	// From is the JSON pointer to the source of move and copy
+	From string `json:"from,omitempty"`
+	// Value is the value of add, replace and test
+	Value any `json:"value,omitempty"`
+}
+
+// Apply is a function that applies the operations of a JSON Patch (RFC 6902) in order to a document decoded from JSON as generic values.
+// The document is not modified, the patched one is returned. If an operation fails none of them is applied
+func Apply(doc any, ops []Operation) (patched any, err error) {
+	patched = deepCopy(doc)
+	for i, op := range ops {
+		patched, err = apply(patched, op)
+		if err != nil {
+			err = fmt.Errorf("operation %d (%s %s): %w", i, op.Op, op.Path, err)
+			patched = nil
+			return
+		}
+	}
+	return
+}
+
+// apply is a function that applies an operation to a document
+func apply(doc any, op Operation) (any, error) {
+	switch op.Op {
+	case "add":
+		return add(doc, op.Path, deepCopy(op.Value))
+	case "remove":
+		doc, _, err := remove(doc, op.Path)
+		return doc, err
+	case "replace":
+		doc, _, err := remove(doc, op.Path)
+		if err != nil {
+			return nil, err
+		}
+		return add(doc, op.Path, deepCopy(op.Value))
+	case "move":
+		if op.Path != op.From && strings.HasPrefix(op.Path, op.From+"/") {
+			return nil, fmt.Errorf("%w: a location can not be moved into one of its children", ErrInvalidPatch)
+		}
+		doc, value, err := remove(doc, op.From)
+		if err != nil {
+			return nil, err
+		}
+		return add(doc, op.Path, value)
+	case "copy":
+		value, err := get(doc, op.From)
+		if err != nil {
+			return nil, err
+		}
+		return add(doc, op.Path, deepCopy(value))
+	case "test":
+		value, err := get(doc, op.Path)
+		if err != nil {
+			return nil, err
+		}
+		if !reflect.DeepEqual(value, op.Value) {
+			return nil, ErrTestFailed
+		}
+		return doc, nil
+	default:
+		return nil, fmt.Errorf("%w: unknown operation %q", ErrInvalidPatch, op.Op)
+	}
+}
+
+// get is a function that returns the value of a JSON pointer in a document
+func get(doc any, path string) (value any, err error) {
+	tokens, err := parsePointer(path)
+	if err != nil {
+		return
+	}
+	value = doc
+	for _, token := range tokens {
+		switch container := value.(type) {
+		case map[string]any:
+			var ok bool
+			if value, ok = container[token]; !ok {
+				return nil, fmt.Errorf("%w: path %s does not exist", ErrInvalidPatch, path)
+			}
+		case []any:
+			i, errIndex := index(token, len(container)-1)
+			if errIndex != nil {
+				return nil, errIndex
+			}
+			value = container[i]
+		default:
+			return nil, fmt.Errorf("%w: path %s does not exist", ErrInvalidPatch, path)
+		}
+	}
+	return
+}
+
+// add is a function that adds a value at a JSON pointer of a document, replacing the member of an object
+// or inserting the element of an array, and returns the document
+func add(doc any, path string, value any) (any, error) {
+	tokens, err := parsePointer(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return value, nil
+	}
+
+	parent, err := get(doc, pointer(tokens[:len(tokens)-1]))
+	if err != nil {
+		return nil, err
+	}
+	last := tokens[len(tokens)-1]
+	switch container := parent.(type) {
+	case map[string]any:
+		container[last] = value
+		return doc, nil
+	case []any:
+		i := len(container)
+		if last != "-" {
+			if i, err = index(last, len(container)); err != nil {
+				return nil, err
+			}
+		}
+		container = append(container, nil)
+		copy(container[i+1:], container[i:])
+		container[i] = value
+		return set(doc, tokens[:len(tokens)-1], container)
+	default:
+		return nil, fmt.Errorf("%w: path %s does not exist", ErrInvalidPatch, path)
+	}
+}
+
+// remove is a function that removes the value at a JSON pointer of a document, and returns the document and the value
+func remove(doc any, path string) (any, any, error) {
+	tokens, err := parsePointer(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, doc, nil
+	}
+
+	value, err := get(doc, path)
+	if err != nil {
+		return nil, nil, err
+	}
+	parent, _ := get(doc, pointer(tokens[:len(tokens)-1]))
+	last := tokens[len(tokens)-1]
+	switch container := parent.(type) {
+	case map[string]any:
+		delete(container, last)
+		return doc, value, nil
+	default:
+		elements := container.([]any)
+		i, _ := index(last, len(elements)-1)
+		elements = append(elements[:i:i], elements[i+1:]...)
+		doc, err = set(doc, tokens[:len(tokens)-1], elements)
+		return doc, value, err
+	}
+}
+
+// set is a function that replaces the array at some tokens of a document, as arrays can grow and shrink, and returns the document
+func set(doc any, tokens []string, value []any) (any, error) {
+	if len(tokens) == 0 {
+		return value, nil
+	}
+	parent, err := get(doc, pointer(tokens[:len(tokens)-1]))
+	if err != nil {
+		return nil, err
+	}
+	last := tokens[len(tokens)-1]
+	switch container := parent.(type) {
+	case map[string]any:
+		container[last] = value
+	case []any:
+		i, _ := index(last, len(container)-1)
+		container[i] = value
+	}
+	return doc, nil
+}
+
+// parsePointer is a function that returns the unescaped reference tokens of a JSON pointer (RFC 6901)
+func parsePointer(path string) (tokens []string, err error) {
+	if path == "" {
+		return
+	}
+	if !strings.HasPrefix(path, "/") {
+		err = fmt.Errorf("%w: pointer %q does not start with /", ErrInvalidPatch, path)
+		return
+	}
+	for _, token := range strings.Split(path[1:], "/") {
+		tokens = append(tokens, strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~"))
+	}
+	return
+}
+
+// pointer is a function that returns the JSON pointer of some reference tokens
+func pointer(tokens []string) string {
+	var sb strings.Builder
+	for _, token := range tokens {
+		sb.WriteString("/" + strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1"))
+	}
+	return sb.String()
+}
+
+// index is a function that parses the index of an array, up to max
+func index(token string, max int) (i int, err error) {
+	i, err = strconv.Atoi(token)
+	if err != nil || i < 0 || i > max || (token != "0" && strings.HasPrefix(token, "0")) {
+		err = fmt.Errorf("%w: index %s out of range", ErrInvalidPatch, token)
+	}
+	return
+}
+
+// deepCopy is a function that returns a copy of a value decoded from JSON, so a patch never modifies its input
+func deepCopy(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		c := make(map[string]any, len(v))
+		for key, val := range v {
+			c[key] = deepCopy(val)
+		}
+		return c
+	case []any:
+		c := make([]any, len(v))
+		for i, val := range v {
+			c[i] = deepCopy(val)
+		}
+		return c
+	default:
+		return v
+	}
+}
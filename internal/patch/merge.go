@@ -0,0 +1,29 @@
+package patch
+
+// Merge is a function that applies a JSON Merge Patch (RFC 7396) to a document, both decoded from JSON as generic values.
+// The document is not modified, the patched one is returned
+func Merge(doc, patch any) any {
+	p, ok := patch.(map[string]any)
+	if !ok {
+		// a patch that is not an object replaces the whole document
+		return patch
+	}
+
+	target, ok := doc.(map[string]any)
+	if !ok {
+		target = map[string]any{}
+	}
+	result := make(map[string]any, len(target))
+	for key, value := range target {
+		result[key] = value
+	}
+
+	for key, value := range p {
+		if value == nil {
+			delete(result, key)
+			continue
+		}
+		result[key] = Merge(result[key], value)
+	}
+	return result
+}
@@ -0,0 +1,93 @@
+package patch
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// decode is a function that decodes a JSON text as generic values
+func decode(t *testing.T, text string) (value any) {
+	t.Helper()
+	if err := json.Unmarshal([]byte(text), &value); err != nil {
+		t.Fatalf("decode %s: %v", text, err)
+	}
+	return
+}
+
+func TestApply(t *testing.T) {
+	const doc = `{"color": "red", "tags": ["a", "b"], "size": {"width": 1}}`
+	cases := []struct {
+		name string
+		ops  string
+		want string
+		err  error
+	}{
+		{name: "add", ops: `[{"op": "add", "path": "/tags/1", "value": "x"}]`,
+			want: `{"color": "red", "tags": ["a", "x", "b"], "size": {"width": 1}}`},
+		{name: "append", ops: `[{"op": "add", "path": "/tags/-", "value": "c"}]`,
+			want: `{"color": "red", "tags": ["a", "b", "c"], "size": {"width": 1}}`},
+		{name: "remove", ops: `[{"op": "remove", "path": "/size/width"}]`,
+			want: `{"color": "red", "tags": ["a", "b"], "size": {}}`},
+		{name: "test and replace", ops: `[{"op": "test", "path": "/color", "value": "red"}, {"op": "replace", "path": "/color", "value": "blue"}]`,
+			want: `{"color": "blue", "tags": ["a", "b"], "size": {"width": 1}}`},
+		{name: "move", ops: `[{"op": "move", "from": "/color", "path": "/size/color"}]`,
+			want: `{"tags": ["a", "b"], "size": {"width": 1, "color": "red"}}`},
+		{name: "copy", ops: `[{"op": "copy", "from": "/tags/0", "path": "/first"}]`,
+			want: `{"color": "red", "tags": ["a", "b"], "size": {"width": 1}, "first": "a"}`},
+		{name: "escaped pointer", ops: `[{"op": "add", "path": "/a~1b~0c", "value": 1}]`,
+			want: `{"color": "red", "tags": ["a", "b"], "size": {"width": 1}, "a/b~c": 1}`},
+		{name: "failed test", ops: `[{"op": "replace", "path": "/color", "value": "blue"}, {"op": "test", "path": "/color", "value": "red"}]`, err: ErrTestFailed},
+		{name: "missing path", ops: `[{"op": "remove", "path": "/wheels"}]`, err: ErrInvalidPatch},
+		{name: "index out of range", ops: `[{"op": "add", "path": "/tags/5", "value": "x"}]`, err: ErrInvalidPatch},
+		{name: "move into a child", ops: `[{"op": "move", "from": "/size", "path": "/size/inner"}]`, err: ErrInvalidPatch},
+		{name: "unknown operation", ops: `[{"op": "merge", "path": "/color"}]`, err: ErrInvalidPatch},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			original := decode(t, doc)
+			var ops []Operation
+			if err := json.Unmarshal([]byte(c.ops), &ops); err != nil {
+				t.Fatal(err)
+			}
+
+			patched, err := Apply(original, ops)
+			if !errors.Is(err, c.err) {
+				t.Fatalf("err = %v, want %v", err, c.err)
+			}
+			if c.err == nil && !reflect.DeepEqual(patched, decode(t, c.want)) {
+				t.Fatalf("patched = %v, want %s", patched, c.want)
+			}
+			// - the document is not modified
+			if !reflect.DeepEqual(original, decode(t, doc)) {
+				t.Fatalf("document = %v, want it unchanged", original)
+			}
+		})
+	}
+}
+
+func TestMerge(t *testing.T) {
+	cases := []struct {
+		name  string
+		doc   string
+		patch string
+		want  string
+	}{
+		{name: "replace and add", doc: `{"a": 1}`, patch: `{"a": 2, "b": 3}`, want: `{"a": 2, "b": 3}`},
+		{name: "null removes", doc: `{"a": 1, "b": 2}`, patch: `{"a": null}`, want: `{"b": 2}`},
+		{name: "nested", doc: `{"a": {"b": 1, "c": 2}}`, patch: `{"a": {"c": null, "d": 3}}`, want: `{"a": {"b": 1, "d": 3}}`},
+		{name: "not an object", doc: `{"a": 1}`, patch: `[1]`, want: `[1]`},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			doc := decode(t, c.doc)
+			if got := Merge(doc, decode(t, c.patch)); !reflect.DeepEqual(got, decode(t, c.want)) {
+				t.Fatalf("merged = %v, want %s", got, c.want)
+			}
+			if !reflect.DeepEqual(doc, decode(t, c.doc)) {
+				t.Fatalf("document = %v, want it unchanged", doc)
+			}
+		})
+	}
+}
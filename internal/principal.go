@@ -0,0 +1,86 @@
+package internal
+
+import "errors"
+
+var (
+	// ErrUnauthenticated is an error that represents credentials that are missing, unknown, expired or forged
+	ErrUnauthenticated = errors.New("unauthenticated")
+)
+
+// Scope is a permission granted to a principal
+type Scope string
+
+const (
+	// ScopeRead allows to read the vehicles
+	ScopeRead Scope = "vehicles:read"
+	// ScopeWrite allows to create and update vehicles, and includes ScopeRead
+	ScopeWrite Scope = "vehicles:write"
+	// ScopeAdmin allows to delete vehicles and administrate the server, and includes ScopeWrite
+	ScopeAdmin Scope = "vehicles:admin"
+)
+
+// scopeLevels are the scopes by level, a scope includes the ones of lower levels
+var scopeLevels = map[Scope]int{
+	ScopeRead:  1,
+	ScopeWrite: 2,
+	ScopeAdmin: 3,
+}
+
+// Valid is a method that returns true if the scope is one of the known scopes
+func (s Scope) Valid() bool {
+	_, ok := scopeLevels[s]
+	return ok
+}
+
+// Principal is a struct that represents the authenticated client of a request
+type Principal struct {
+	// Subject is the name of the client, such as the owner of an API key
+	Subject string `json:"subject"`
+	// KeyID is the id of the API key or of the signing key of the token the client authenticated with
+	KeyID string `json:"key_id"`
+	// Scopes are the scopes granted to the client
+	Scopes []Scope `json:"scopes"`
+	// Tenant is the tenant the client is bound to, empty if it may act on any tenant
+	Tenant string `json:"tenant,omitempty"`
+}
+
+// HasScope is a method that returns true if the principal was granted the scope, or one that includes it
+func (p Principal) HasScope(scope Scope) bool {
+	for _, s := range p.Scopes {
+		if s.Valid() && scopeLevels[s] >= scopeLevels[scope] {
+			return true
+		}
+	}
+	return false
+}
+
+// Authenticator is an interface that represents the authentication of the clients by their credentials
+type Authenticator interface {
+	// AuthenticateKey is a method that returns the principal of an API key
+	AuthenticateKey(key string) (p Principal, err error)
+	// AuthenticateToken is a method that returns the principal of a signed bearer token
+	AuthenticateToken(token string) (p Principal, err error)
+}
+
+// Role is the role of a principal, which decides the fields of the vehicles it may see and modify
+type Role string
+
+const (
+	// RoleReader is the role of the principals that can only read the vehicles
+	RoleReader Role = "reader"
+	// RoleWriter is the role of the principals that can create and update vehicles
+	RoleWriter Role = "writer"
+	// RoleAdmin is the role of the principals that administrate the vehicles
+	RoleAdmin Role = "admin"
+)
+
+// Role is a method that returns the role of the principal, by its highest scope
+func (p Principal) Role() Role {
+	switch {
+	case p.HasScope(ScopeAdmin):
+		return RoleAdmin
+	case p.HasScope(ScopeWrite):
+		return RoleWriter
+	}
+	return RoleReader
+}
@@ -0,0 +1,215 @@
+package repository
+
+import (
+	"app/internal"
+	"sort"
+	"sync"
+)
+
+// NewFleetMap is a function that returns a new instance of FleetMap
+func NewFleetMap() *FleetMap {
+	return &FleetMap{db: make(map[int]internal.Fleet), fleetOf: make(map[int]int)}
+}
+
+// FleetMap is a struct that represents a fleet repository
+type FleetMap struct {
+	// mu guards db and fleetOf
+	mu sync.RWMutex
+	// db is a map of fleets
+	db map[int]internal.Fleet
+	// fleetOf is the id of the fleet of each assigned vehicle, by vehicle id
+	fleetOf map[int]int
+	// lastId is the id of the last fleet created
+	lastId int
+}
+
+// FindAll is a method that returns a map of all fleets
+func (r *FleetMap) FindAll() (f map[int]internal.Fleet, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	f = make(map[int]internal.Fleet, len(r.db))
+	for id, fleet := range r.db {
+		f[id] = copyFleet(fleet)
+	}
+	return
+}
+
+// FindById is a method that returns a fleet by id
+func (r *FleetMap) FindById(id int) (f internal.Fleet, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	f, ok := r.db[id]
+	if !ok {
+		err = internal.NewRepositoryError(internal.ErrFleetNotFound, "id", id)
+		return
+	}
+	f = copyFleet(f)
+	return
+}
+
+// Create is a method that creates a new fleet without vehicles, returning it with its assigned id
+func (r *FleetMap) Create(f internal.Fleet) (created internal.Fleet, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err = r.unique(0, f.Name); err != nil {
+		return
+	}
+	r.lastId++
+	created = internal.Fleet{Id: r.lastId, Name: f.Name, Depot: f.Depot, VehicleIds: []int{}}
+	r.db[created.Id] = created
+	created = copyFleet(created)
+	return
+}
+
+// Update is a method that replaces the name and the depot of a fleet, its vehicles are kept
+func (r *FleetMap) Update(f internal.Fleet) (updated internal.Fleet, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	updated, ok := r.db[f.Id]
+	if !ok {
+		err = internal.NewRepositoryError(internal.ErrFleetNotFound, "id", f.Id)
+		return
+	}
+	if err = r.unique(f.Id, f.Name); err != nil {
+		return
+	}
+	updated.Name, updated.Depot = f.Name, f.Depot
+	r.db[f.Id] = updated
+	updated = copyFleet(updated)
+	return
+}
+
+// Delete is a method that deletes a fleet, its vehicles are no longer assigned to any fleet
+func (r *FleetMap) Delete(id int) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	f, ok := r.db[id]
+	if !ok {
+		err = internal.NewRepositoryError(internal.ErrFleetNotFound, "id", id)
+		return
+	}
+	for _, vehicleId := range f.VehicleIds {
+		delete(r.fleetOf, vehicleId)
+	}
+	delete(r.db, id)
+	return
+}
+
+// Assign is a method that assigns vehicles to a fleet, atomically. Vehicles already in the fleet are kept
+func (r *FleetMap) Assign(id int, vehicleIds []int) (f internal.Fleet, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	f, ok := r.db[id]
+	if !ok {
+		err = internal.NewRepositoryError(internal.ErrFleetNotFound, "id", id)
+		return
+	}
+	// - check every vehicle before assigning any
+	for _, vehicleId := range vehicleIds {
+		if other, ok := r.fleetOf[vehicleId]; ok && other != id {
+			err = internal.NewRepositoryError(internal.ErrVehicleAssigned, "vehicle_id", vehicleId, "fleet_id", other)
+			return
+		}
+	}
+	// - assign
+	for _, vehicleId := range vehicleIds {
+		if _, ok := r.fleetOf[vehicleId]; ok {
+			continue
+		}
+		r.fleetOf[vehicleId] = id
+		f.VehicleIds = append(f.VehicleIds, vehicleId)
+	}
+	sort.Ints(f.VehicleIds)
+	r.db[id] = f
+	f = copyFleet(f)
+	return
+}
+
+// Unassign is a method that removes vehicles from a fleet, atomically
+func (r *FleetMap) Unassign(id int, vehicleIds []int) (f internal.Fleet, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	f, ok := r.db[id]
+	if !ok {
+		err = internal.NewRepositoryError(internal.ErrFleetNotFound, "id", id)
+		return
+	}
+	// - check every vehicle before removing any
+	removed := make(map[int]bool, len(vehicleIds))
+	for _, vehicleId := range vehicleIds {
+		if r.fleetOf[vehicleId] != id {
+			err = internal.NewRepositoryError(internal.ErrVehicleNotInFleet, "vehicle_id", vehicleId, "fleet_id", id)
+			return
+		}
+		removed[vehicleId] = true
+	}
+	// - remove
+	kept := make([]int, 0, len(f.VehicleIds))
+	for _, vehicleId := range f.VehicleIds {
+		if removed[vehicleId] {
+			delete(r.fleetOf, vehicleId)
+			continue
+		}
+		kept = append(kept, vehicleId)
+	}
+	f.VehicleIds = kept
+	r.db[id] = f
+	f = copyFleet(f)
+	return
+}
+
+// Release is a method that removes deleted vehicles from their fleets, the vehicles in no fleet are ignored
+func (r *FleetMap) Release(vehicleIds []int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// - group the vehicles by fleet
+	removed := make(map[int]map[int]bool)
+	for _, vehicleId := range vehicleIds {
+		id, ok := r.fleetOf[vehicleId]
+		if !ok {
+			continue
+		}
+		if removed[id] == nil {
+			removed[id] = make(map[int]bool)
+		}
+		removed[id][vehicleId] = true
+		delete(r.fleetOf, vehicleId)
+	}
+	// - remove
+	for id, vehicles := range removed {
+		f := r.db[id]
+		kept := make([]int, 0, len(f.VehicleIds))
+		for _, vehicleId := range f.VehicleIds {
+			if !vehicles[vehicleId] {
+				kept = append(kept, vehicleId)
+			}
+		}
+		f.VehicleIds = kept
+		r.db[id] = f
+	}
+}
+
+// unique is a method that returns an error if a fleet other than id has the name, the caller must hold the lock
+func (r *FleetMap) unique(id int, name string) (err error) {
+	for _, f := range r.db {
+		if f.Id != id && f.Name == name {
+			err = internal.NewRepositoryError(internal.ErrFleetAlreadyExists, "name", name)
+			return
+		}
+	}
+	return
+}
+
+// copyFleet is a function that returns a copy of a fleet that does not share its vehicle ids
+func copyFleet(f internal.Fleet) internal.Fleet {
+	f.VehicleIds = append([]int{}, f.VehicleIds...)
+	return f
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"app/internal"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestFleetMap_Release(t *testing.T) {
+	cases := []struct {
+		name   string
+		delete func(rp *VehicleMap) error
+		want   []int
+	}{
+		{name: "delete", delete: func(rp *VehicleMap) error { return rp.Delete(2) }, want: []int{1, 3}},
+		{name: "delete by filter", delete: func(rp *VehicleMap) error {
+			_, err := rp.DeleteByFilter(internal.VehicleFilter{Brand: "Ford"}, false)
+			return err
+		}, want: []int{1, 3}},
+		{name: "delete by filter dry run", delete: func(rp *VehicleMap) error {
+			_, err := rp.DeleteByFilter(internal.VehicleFilter{Brand: "Ford"}, true)
+			return err
+		}, want: []int{1, 2, 3}},
+		{name: "transaction", delete: func(rp *VehicleMap) error {
+			return rp.Transaction(func(tx internal.VehicleRepository) error { return tx.Delete(3) })
+		}, want: []int{1, 2}},
+		{name: "failed transaction", delete: func(rp *VehicleMap) error {
+			rp.Transaction(func(tx internal.VehicleRepository) error {
+				tx.Delete(3)
+				return errors.New("rollback")
+			})
+			return nil
+		}, want: []int{1, 2, 3}},
+		{name: "swap", delete: func(rp *VehicleMap) error {
+			rp.Swap(newVehicles(1))
+			return nil
+		}, want: []int{1}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			rp := NewVehicleMap(newVehicles(4))
+			fm := NewFleetMap()
+			rp.OnDelete(fm.Release)
+			fleet, _ := fm.Create(internal.Fleet{Name: "north"})
+			other, _ := fm.Create(internal.Fleet{Name: "south"})
+			if _, err := fm.Assign(fleet.Id, []int{1, 2, 3}); err != nil {
+				t.Fatalf("assign: %v", err)
+			}
+
+			// - the deleted vehicles leave their fleet
+			if err := c.delete(rp); err != nil {
+				t.Fatalf("delete: %v", err)
+			}
+			f, _ := fm.FindById(fleet.Id)
+			if !reflect.DeepEqual(f.VehicleIds, c.want) {
+				t.Fatalf("vehicles = %v, want %v", f.VehicleIds, c.want)
+			}
+
+			// - and can be assigned to another fleet once created again
+			for id := 1; id <= 3; id++ {
+				if _, err := rp.FindById(id); err == nil {
+					continue
+				}
+				if err := rp.Create(internal.Vehicle{Id: id}); err != nil {
+					t.Fatalf("create %d: %v", id, err)
+				}
+				if _, err := fm.Assign(other.Id, []int{id}); err != nil {
+					t.Fatalf("assign %d to another fleet: %v", id, err)
+				}
+			}
+		})
+	}
+}
@@ -3,6 +3,8 @@ package repository
 import (
 	"app/internal"
 	"fmt"
+	"sort"
+	"sync"
 )
 
 // NewVehicleMap is a function that returns a new instance of VehicleMap
@@ -17,12 +19,114 @@ func NewVehicleMap(db map[int]internal.Vehicle) *VehicleMap {
 
 // VehicleMap is a struct that represents a vehicle repository
 type VehicleMap struct {
+	// mu guards db, so every operation works on a consistent view even while the data is swapped
+	mu sync.RWMutex
 	// db is a map of vehicles
 	db map[int]internal.Vehicle
+	// loaded are the vehicles of the last dataset swapped or merged in, to tell them from the changes made since then
+	loaded map[int]internal.Vehicle
+	// limit is the maximum amount of vehicles that can be created, 0 means no limit
+	limit int
+	// onDelete is called with the ids of the deleted vehicles, nil means nobody is told
+	onDelete func(ids []int)
+}
+
+// SetLimit is a method that sets the maximum amount of vehicles, 0 means no limit.
+// The vehicles that exceed it are kept, but no more can be created
+func (r *VehicleMap) SetLimit(limit int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.limit = limit
+}
+
+// OnDelete is a method that sets the function called with the ids of the vehicles that are deleted,
+// or that are no longer in the vehicles swapped in. It is called while the lock is held, so it must not use the repository
+func (r *VehicleMap) OnDelete(fn func(ids []int)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onDelete = fn
+}
+
+// deleted is a method that calls the OnDelete function with the ids of the deleted vehicles, the caller must hold the lock
+func (r *VehicleMap) deleted(ids []int) {
+	if r.onDelete != nil && len(ids) > 0 {
+		r.onDelete(ids)
+	}
+}
+
+// missing is a method that returns the sorted ids of the vehicles that are not in db, the caller must hold the lock
+func (r *VehicleMap) missing(db map[int]internal.Vehicle) (ids []int) {
+	for id := range r.db {
+		if _, ok := db[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+	return
+}
+
+// fits is a method that returns an error if n more vehicles exceed the limit, the caller must hold the lock
+func (r *VehicleMap) fits(n int) (err error) {
+	if r.limit > 0 && n > 0 && len(r.db)+n > r.limit {
+		err = internal.NewRepositoryError(internal.ErrQuotaExceeded, "limit", r.limit)
+	}
+	return
+}
+
+// Swap is a method that atomically replaces all the vehicles, which are the dataset the next Merge is compared with
+func (r *VehicleMap) Swap(db map[int]internal.Vehicle) {
+	// default db
+	defaultDb := make(map[int]internal.Vehicle)
+	if db != nil {
+		defaultDb = db
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.deleted(r.missing(defaultDb))
+	r.db = defaultDb
+	r.loaded = make(map[int]internal.Vehicle, len(defaultDb))
+	for key, value := range defaultDb {
+		r.loaded[key] = value
+	}
+}
+
+// Merge is a method that atomically replaces the vehicles of the previous dataset with the ones of db.
+// The changes made since the previous dataset was swapped or merged in are kept:
+// the vehicles created or updated since then are not replaced, and the deleted ones are not restored
+func (r *VehicleMap) Merge(db map[int]internal.Vehicle) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// - the new dataset
+	merged := make(map[int]internal.Vehicle, len(db))
+	for key, value := range db {
+		merged[key] = value
+	}
+	// - the changes since the previous dataset
+	for key, value := range r.db {
+		if prev, ok := r.loaded[key]; !ok || prev != value {
+			merged[key] = value
+		}
+	}
+	for key := range r.loaded {
+		if _, ok := r.db[key]; !ok {
+			delete(merged, key)
+		}
+	}
+	r.deleted(r.missing(merged))
+	r.db = merged
+	r.loaded = make(map[int]internal.Vehicle, len(db))
+	for key, value := range db {
+		r.loaded[key] = value
+	}
 }
 
 // FindAll is a method that returns a map of all vehicles
 func (r *VehicleMap) FindAll() (v map[int]internal.Vehicle, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
 	v = make(map[int]internal.Vehicle)
 
 	// copy db
@@ -35,9 +139,21 @@ func (r *VehicleMap) FindAll() (v map[int]internal.Vehicle, err error) {
 
 // Create is a method that creates a new vehicle
 func (r *VehicleMap) Create(v internal.Vehicle) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	err = r.create(v)
+	return
+}
+
+// create is a method that creates a new vehicle, the caller must hold the lock
+func (r *VehicleMap) create(v internal.Vehicle) (err error) {
 	//check if id already exists
 	if _, ok := r.db[v.Id]; ok {
-		err = fmt.Errorf("vehicle with id %d already exists", v.Id)
+		err = internal.NewRepositoryError(internal.ErrVehicleAlreadyExists, "id", v.Id)
+		return
+	}
+	if err = r.fits(1); err != nil {
 		return
 	}
 	r.db[v.Id] = v
@@ -46,6 +162,9 @@ func (r *VehicleMap) Create(v internal.Vehicle) (err error) {
 
 // FindByColorYear is a method that returns a map of vehicles by color and year
 func (r *VehicleMap) FindByColorYear(color string, year int) (v map[int]internal.Vehicle, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
 	v = make(map[int]internal.Vehicle)
 
 	// copy db
@@ -57,7 +176,7 @@ func (r *VehicleMap) FindByColorYear(color string, year int) (v map[int]internal
 
 	// check if map is empty
 	if len(v) == 0 {
-		err = fmt.Errorf("no vehicles found with color %s and year %d", color, year)
+		err = internal.NewRepositoryError(internal.ErrNoVehiclesFound, "color", color, "year", year)
 		return
 	}
 
@@ -66,6 +185,9 @@ func (r *VehicleMap) FindByColorYear(color string, year int) (v map[int]internal
 
 // FindByBrandRange is a method that returns a map of vehicles by brand and year range
 func (r *VehicleMap) FindByBrandRange(brand string, yearstart int, yearend int) (v map[int]internal.Vehicle, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
 	v = make(map[int]internal.Vehicle)
 
 	// copy db
@@ -77,7 +199,7 @@ func (r *VehicleMap) FindByBrandRange(brand string, yearstart int, yearend int)
 
 	// check if map is empty
 	if len(v) == 0 {
-		err = fmt.Errorf("brand: %s - year range: %d - %d", brand, yearstart, yearend)
+		err = internal.NewRepositoryError(internal.ErrNoVehiclesFound, "brand", brand, "start_year", yearstart, "end_year", yearend)
 		return
 	}
 
@@ -86,6 +208,9 @@ func (r *VehicleMap) FindByBrandRange(brand string, yearstart int, yearend int)
 
 // AverageSpeed is a method that returns the average speed of a vehicle by brand
 func (r *VehicleMap) AverageSpeed(brand string) (average float64, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
 	var totalSpeed float64
 	var totalVehicles float64
 
@@ -99,7 +224,7 @@ func (r *VehicleMap) AverageSpeed(brand string) (average float64, err error) {
 
 	// check if map is empty
 	if totalVehicles == 0 {
-		err = fmt.Errorf("no se encontraron vehículos de la marca %s", brand)
+		err = internal.NewRepositoryError(internal.ErrNoVehiclesFound, "brand", brand)
 		return
 	}
 
@@ -107,30 +232,69 @@ func (r *VehicleMap) AverageSpeed(brand string) (average float64, err error) {
 	return
 }
 
-// CreateBatch is a method that creates multiple vehicles
-func (r *VehicleMap) CreateBatch(v []internal.Vehicle) (err error) {
-	for _, vehicle := range v {
-		if _, ok := r.db[vehicle.Id]; ok {
-			err = fmt.Errorf("vehicle with id %d already exists", vehicle.Id)
-			return
+// CreateBatch is a method that creates multiple vehicles. If any vehicle fails nothing is created, unless partial
+// is set, then the rest of them are created up to the limit. The error is a BatchError with every failing vehicle.
+// With dryRun nothing is created
+func (r *VehicleMap) CreateBatch(v []internal.Vehicle, partial, dryRun bool) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// resolve the failing vehicles first: ids that exist, or that an earlier vehicle of the batch has
+	failed := internal.BatchError{}
+	seen := make(map[int]bool)
+	for i, vehicle := range v {
+		if _, ok := r.db[vehicle.Id]; ok || seen[vehicle.Id] {
+			failed[i] = internal.NewRepositoryError(internal.ErrVehicleAlreadyExists, "id", vehicle.Id)
+			continue
 		}
+		seen[vehicle.Id] = true
 	}
-	for _, vehicle := range v {
-		err = r.Create(vehicle)
-		if err != nil {
-			err = fmt.Errorf("error creating vehicle %s - %s with id %d: %w", vehicle.Brand, vehicle.Model, vehicle.Id, err)
+	if len(failed) > 0 {
+		err = failed
+	}
+	// the vehicles that would be created must fit in the limit, in partial mode the ones beyond it fail
+	if errLimit := r.fits(len(v) - len(failed)); errLimit != nil {
+		if !partial {
+			if err == nil {
+				err = errLimit
+			}
 			return
 		}
+		room := r.limit - len(r.db)
+		for i := range v {
+			if _, ok := failed[i]; ok {
+				continue
+			}
+			if room > 0 {
+				room--
+				continue
+			}
+			failed[i] = errLimit
+		}
+		err = failed
+	}
+	if dryRun || (err != nil && !partial) {
+		return
+	}
+
+	// write
+	for i, vehicle := range v {
+		if _, ok := failed[i]; !ok {
+			r.db[vehicle.Id] = vehicle
+		}
 	}
 	return
 }
 
 // UpdateSpeed is a method that updates a vehicle
 func (r *VehicleMap) UpdateSpeed(id int, speed float64) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	// check if vehicle exists
 	vehicle, ok := r.db[id]
 	if !ok {
-		err = fmt.Errorf("vehicle with id %d not found", id)
+		err = internal.NewRepositoryError(internal.ErrVehicleNotFound, "id", id)
 		return
 	}
 
@@ -141,6 +305,9 @@ func (r *VehicleMap) UpdateSpeed(id int, speed float64) (err error) {
 
 // FindByFuelType is a method that returns a map of vehicles by fuel type
 func (r *VehicleMap) FindByFuelType(fuelType string) (v map[int]internal.Vehicle, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
 	v = make(map[int]internal.Vehicle)
 
 	// copy db
@@ -152,7 +319,7 @@ func (r *VehicleMap) FindByFuelType(fuelType string) (v map[int]internal.Vehicle
 
 	// check if map is empty
 	if len(v) == 0 {
-		err = fmt.Errorf("no se encontraron vehículos con combustible: %s", fuelType)
+		err = internal.NewRepositoryError(internal.ErrNoVehiclesFound, "fuel_type", fuelType)
 		return
 	}
 
@@ -161,21 +328,28 @@ func (r *VehicleMap) FindByFuelType(fuelType string) (v map[int]internal.Vehicle
 
 // Delete is a method that deletes a vehicle
 func (r *VehicleMap) Delete(id int) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	// check if vehicle exists
 	if _, ok := r.db[id]; !ok {
-		err = fmt.Errorf("vehicle with id %d not found", id)
+		err = internal.NewRepositoryError(internal.ErrVehicleNotFound, "id", id)
 		return
 	}
 	delete(r.db, id)
+	r.deleted([]int{id})
 	return
 }
 
 // UpdateFuelType is a method that updates a vehicle
 func (r *VehicleMap) UpdateFuelType(id int, fuelType string) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	// check if vehicle exists
 	vehicle, ok := r.db[id]
 	if !ok {
-		err = fmt.Errorf("vehicle with id %d not found", id)
+		err = internal.NewRepositoryError(internal.ErrVehicleNotFound, "id", id)
 		return
 	}
 
@@ -186,6 +360,9 @@ func (r *VehicleMap) UpdateFuelType(id int, fuelType string) (err error) {
 
 // FindByDimensions is a method that returns a map of vehicles by dimensions
 func (r *VehicleMap) FindByDimensions(minlength, maxlength, minwidth, maxwidth float64) (v map[int]internal.Vehicle, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
 	v = make(map[int]internal.Vehicle)
 
 	// copy db
@@ -197,9 +374,231 @@ func (r *VehicleMap) FindByDimensions(minlength, maxlength, minwidth, maxwidth f
 
 	// check if map is empty
 	if len(v) == 0 {
-		err = fmt.Errorf("no se encontraron vehículos con esas dimensiones")
+		err = internal.NewRepositoryError(internal.ErrNoVehiclesFound, "length", fmt.Sprintf("%g-%g", minlength, maxlength), "width", fmt.Sprintf("%g-%g", minwidth, maxwidth))
+		return
+	}
+
+	return
+}
+
+// FindByFilter is a method that returns a map of the vehicles that match the filter
+func (r *VehicleMap) FindByFilter(f internal.VehicleFilter) (v map[int]internal.Vehicle, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	v = make(map[int]internal.Vehicle)
+
+	// copy db
+	for key, value := range r.db {
+		if f.Match(value) {
+			v[key] = value
+		}
+	}
+
+	return
+}
+
+// FindPage is a method that returns up to limit vehicles that match the filter and have an id greater than afterId, sorted by id.
+// Only the ids of the candidates are collected and sorted, the vehicles of the page are the only ones copied
+func (r *VehicleMap) FindPage(f internal.VehicleFilter, afterId, limit int) (v []internal.Vehicle, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ids := []int{}
+	for id, vehicle := range r.db {
+		if id > afterId && f.Match(vehicle) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+	if limit > 0 && len(ids) > limit {
+		ids = ids[:limit]
+	}
+
+	v = make([]internal.Vehicle, 0, len(ids))
+	for _, id := range ids {
+		v = append(v, r.db[id])
+	}
+	return
+}
+
+// Import is a method that creates or replaces multiple vehicles applying the policy on id conflicts
+func (r *VehicleMap) Import(v []internal.Vehicle, policy internal.ImportPolicy, dryRun bool) (outcomes []internal.ImportOutcome, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// resolve the outcomes first, so a failed import writes nothing
+	outcomes = make([]internal.ImportOutcome, len(v))
+	seen := make(map[int]bool)
+	for i, vehicle := range v {
+		_, exists := r.db[vehicle.Id]
+		exists = exists || seen[vehicle.Id]
+		seen[vehicle.Id] = true
+
+		switch {
+		case !exists:
+			outcomes[i] = internal.ImportCreated
+		case policy == internal.ImportSkip:
+			outcomes[i] = internal.ImportSkipped
+		case policy == internal.ImportOverwrite:
+			outcomes[i] = internal.ImportUpdated
+		default:
+			outcomes = nil
+			err = internal.NewRepositoryError(internal.ErrVehicleAlreadyExists, "id", vehicle.Id)
+			return
+		}
+	}
+	created := 0
+	for _, outcome := range outcomes {
+		if outcome == internal.ImportCreated {
+			created++
+		}
+	}
+	if err = r.fits(created); err != nil {
+		outcomes = nil
+		return
+	}
+	if dryRun {
+		return
+	}
+
+	// write
+	for i, vehicle := range v {
+		if outcomes[i] != internal.ImportSkipped {
+			r.db[vehicle.Id] = vehicle
+		}
+	}
+	return
+}
+
+// FindById is a method that returns a vehicle by id
+func (r *VehicleMap) FindById(id int) (v internal.Vehicle, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	// check if vehicle exists
+	v, ok := r.db[id]
+	if !ok {
+		err = internal.NewRepositoryError(internal.ErrVehicleNotFound, "id", id)
+		return
+	}
+	return
+}
+
+// Save is a method that creates or replaces a vehicle, returning true if it was created
+func (r *VehicleMap) Save(v internal.Vehicle) (created bool, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, exists := r.db[v.Id]
+	created = !exists
+	if created {
+		if err = r.fits(1); err != nil {
+			return
+		}
+	}
+	r.db[v.Id] = v
+	return
+}
+
+// Update is a method that replaces a vehicle with the result of a function of its current value, atomically.
+// The id of the vehicle can not be changed, and if the function fails the vehicle is not changed
+func (r *VehicleMap) Update(id int, update func(v internal.Vehicle) (internal.Vehicle, error)) (v internal.Vehicle, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// check if vehicle exists
+	current, ok := r.db[id]
+	if !ok {
+		err = internal.NewRepositoryError(internal.ErrVehicleNotFound, "id", id)
+		return
+	}
+
+	v, err = update(current)
+	if err != nil {
+		v = internal.Vehicle{}
+		return
+	}
+	v.Id = id
+	r.db[id] = v
+	return
+}
+
+// UpdateByFilter is a method that replaces the vehicles that match the filter with the result of a function of their
+// current value, atomically, returning their sorted ids. If the function fails for any vehicle, or with dryRun, nothing is written
+func (r *VehicleMap) UpdateByFilter(f internal.VehicleFilter, update func(v internal.Vehicle) (internal.Vehicle, error), dryRun bool) (ids []int, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// resolve the updates first, so a failed update writes nothing
+	ids = r.matching(f)
+	updated := make([]internal.Vehicle, len(ids))
+	for i, id := range ids {
+		updated[i], err = update(r.db[id])
+		if err != nil {
+			ids = nil
+			err = fmt.Errorf("vehicle %d: %w", id, err)
+			return
+		}
+		updated[i].Id = id
+	}
+	if dryRun {
+		return
+	}
+
+	// write
+	for _, vehicle := range updated {
+		r.db[vehicle.Id] = vehicle
+	}
+	return
+}
+
+// DeleteByFilter is a method that deletes the vehicles that match the filter, atomically, returning their sorted ids.
+// With dryRun nothing is deleted
+func (r *VehicleMap) DeleteByFilter(f internal.VehicleFilter, dryRun bool) (ids []int, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ids = r.matching(f)
+	if dryRun {
 		return
 	}
+	for _, id := range ids {
+		delete(r.db, id)
+	}
+	r.deleted(ids)
+	return
+}
 
+// matching is a method that returns the sorted ids of the vehicles that match the filter, the caller must hold the lock
+func (r *VehicleMap) matching(f internal.VehicleFilter) (ids []int) {
+	ids = []int{}
+	for id, vehicle := range r.db {
+		if f.Match(vehicle) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+	return
+}
+
+// Transaction is a method that runs a function with a repository whose changes are applied all at once,
+// only if the function succeeds. No other operation sees the repository in the meantime
+func (r *VehicleMap) Transaction(fn func(rp internal.VehicleRepository) error) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// the function works on a copy, that replaces the vehicles if it succeeds
+	db := make(map[int]internal.Vehicle, len(r.db))
+	for key, value := range r.db {
+		db[key] = value
+	}
+	tx := NewVehicleMap(db)
+	tx.limit = r.limit
+	if err = fn(tx); err != nil {
+		return
+	}
+	r.deleted(r.missing(tx.db))
+	r.db = tx.db
 	return
 }
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"app/internal"
+	"testing"
+)
+
+// newVehicles is a function that returns n vehicles with the ids 1 to n, the even ones of the brand Ford
+func newVehicles(n int) map[int]internal.Vehicle {
+	db := make(map[int]internal.Vehicle, n)
+	for id := 1; id <= n; id++ {
+		v := internal.Vehicle{Id: id}
+		v.Brand = "Chevrolet"
+		if id%2 == 0 {
+			v.Brand = "Ford"
+		}
+		db[id] = v
+	}
+	return db
+}
+
+func TestVehicleMap_FindPage(t *testing.T) {
+	rp := NewVehicleMap(newVehicles(25))
+	f := internal.VehicleFilter{Brand: "Ford"}
+
+	// - the pages go through every matching vehicle once, in id order
+	var ids []int
+	after := 0
+	for {
+		page, err := rp.FindPage(f, after, 5)
+		if err != nil {
+			t.Fatalf("page after %d: %v", after, err)
+		}
+		if len(page) > 5 {
+			t.Fatalf("page after %d: %d vehicles, want at most 5", after, len(page))
+		}
+		if len(page) == 0 {
+			break
+		}
+		for _, v := range page {
+			ids = append(ids, v.Id)
+		}
+		after = page[len(page)-1].Id
+	}
+
+	if len(ids) != 12 {
+		t.Fatalf("ids = %v, want the 12 even ids", ids)
+	}
+	for i, id := range ids {
+		if id != 2*(i+1) {
+			t.Fatalf("ids = %v, want the even ids in order", ids)
+		}
+	}
+}
+
+func TestVehicleMap_Merge(t *testing.T) {
+	rp := NewVehicleMap(nil)
+	rp.Swap(newVehicles(4))
+
+	// - changes through the repository: 1 is updated, 2 is deleted and 5 is created
+	if err := rp.UpdateSpeed(1, 120); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if err := rp.Delete(2); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if err := rp.Create(internal.Vehicle{Id: 5}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	// - the new dataset changes every vehicle, removes 4 and adds 6
+	db := newVehicles(3)
+	for id, v := range db {
+		v.Color = "white"
+		db[id] = v
+	}
+	db[6] = internal.Vehicle{Id: 6}
+	rp.Merge(db)
+
+	v, _ := rp.FindAll()
+	if len(v) != 4 {
+		t.Fatalf("vehicles = %v, want 1, 3, 5 and 6", v)
+	}
+	if v[1].MaxSpeed != 120 || v[1].Color != "" {
+		t.Fatalf("vehicle 1 = %+v, want the update through the repository", v[1])
+	}
+	if v[3].Color != "white" {
+		t.Fatalf("vehicle 3 = %+v, want the one of the new dataset", v[3])
+	}
+	for _, id := range []int{5, 6} {
+		if _, ok := v[id]; !ok {
+			t.Fatalf("vehicle %d missing", id)
+		}
+	}
+
+	// - the next dataset is compared with the merged one: 3 is replaced again, 1 is still kept
+	db = newVehicles(3)
+	rp.Merge(db)
+	v, _ = rp.FindAll()
+	if v[3].Color != "" || v[1].MaxSpeed != 120 {
+		t.Fatalf("vehicles = %v, want 3 of the new dataset and 1 as updated", v)
+	}
+}
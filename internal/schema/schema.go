@@ -0,0 +1,187 @@
+package schema
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Draft is the JSON Schema dialect of the schemas
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema is a struct that represents the subset of JSON Schema supported by the validator:
+// types, required properties, minimum, maximum, enum and pattern
+type Schema struct {
+	// Dialect is the JSON Schema dialect, only set in the root schema
+	Dialect string `json:"$schema,omitempty"`
+	// ID is the identifier of the schema, only set in the root schema
+	ID string `json:"$id,omitempty"`
+	// Title is the title of the schema
+	Title string `json:"title,omitempty"`
+	// Type is the type of the value: object, array, string, number, integer or boolean
+	Type string `json:"type,omitempty"`
+	// Properties are the schemas of the properties of an object
+	Properties map[string]*Schema `json:"properties,omitempty"`
+	// Required are the properties an object must have
+	Required []string `json:"required,omitempty"`
+	// AdditionalProperties is false if an object can not have properties other than Properties
+	AdditionalProperties *bool `json:"additionalProperties,omitempty"`
+	// Items is the schema of the items of an array
+	Items *Schema `json:"items,omitempty"`
+	// Minimum is the minimum value of a number
+	Minimum *float64 `json:"minimum,omitempty"`
+	// ExclusiveMinimum is the value a number must be greater than
+	ExclusiveMinimum *float64 `json:"exclusiveMinimum,omitempty"`
+	// Maximum is the maximum value of a number
+	Maximum *float64 `json:"maximum,omitempty"`
+	// Enum are the allowed values
+	Enum []any `json:"enum,omitempty"`
+	// Pattern is the regular expression a string must match
+	Pattern string `json:"pattern,omitempty"`
+	// Nullable is true if the value may also be null, whatever its type
+	Nullable bool `json:"-"`
+}
+
+// Error is a struct that represents a violation of a schema
+type Error struct {
+	// Pointer is the JSON pointer to the value that violates the schema
+	Pointer string `json:"pointer"`
+	// Message is the description of the violation
+	Message string `json:"message"`
+}
+
+// Error is a method that returns the violation as text
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s", e.Pointer, e.Message)
+}
+
+// Partial is a method that returns a copy of an object schema without required properties, for partial updates
+func (s *Schema) Partial() *Schema {
+	partial := *s
+	partial.Required = nil
+	return &partial
+}
+
+// MergePatch is a method that returns a copy of an object schema for the JSON Merge Patches (RFC 7396) of its values:
+// without required properties, and with every property nullable, as null removes it
+func (s *Schema) MergePatch() *Schema {
+	merge := s.Partial()
+	merge.Properties = make(map[string]*Schema, len(s.Properties))
+	for name, prop := range s.Properties {
+		nullable := *prop
+		nullable.Nullable = true
+		merge.Properties[name] = &nullable
+	}
+	return merge
+}
+
+// Validate is a method that returns all the violations of the schema by a value decoded from JSON
+func (s *Schema) Validate(value any) (errs []*Error) {
+	s.validate("", value, &errs)
+	return
+}
+
+// validate is a method that appends the violations of the schema by the value at the pointer
+func (s *Schema) validate(pointer string, value any, errs *[]*Error) {
+	fail := func(format string, args ...any) {
+		*errs = append(*errs, &Error{Pointer: pointer, Message: fmt.Sprintf(format, args...)})
+	}
+
+	// type
+	if value == nil && s.Nullable {
+		return
+	}
+	if s.Type != "" && !hasType(value, s.Type) {
+		fail("must be of type %s", s.Type)
+		return
+	}
+
+	// enum
+	if len(s.Enum) > 0 {
+		found := false
+		for _, allowed := range s.Enum {
+			if fmt.Sprint(allowed) == fmt.Sprint(value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			fail("must be one of %v", s.Enum)
+		}
+	}
+
+	switch val := value.(type) {
+	case map[string]any:
+		// missing properties are pointed as if they were there, so forms can show the error on their field
+		for _, name := range s.Required {
+			if _, ok := val[name]; !ok {
+				*errs = append(*errs, &Error{Pointer: pointer + "/" + escape(name), Message: "is required"})
+			}
+		}
+		names := make([]string, 0, len(val))
+		for name := range val {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			prop, ok := s.Properties[name]
+			if !ok {
+				if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+					*errs = append(*errs, &Error{Pointer: pointer + "/" + escape(name), Message: "unknown property"})
+				}
+				continue
+			}
+			prop.validate(pointer+"/"+escape(name), val[name], errs)
+		}
+	case []any:
+		if s.Items != nil {
+			for i, item := range val {
+				s.Items.validate(pointer+"/"+strconv.Itoa(i), item, errs)
+			}
+		}
+	case float64:
+		if s.Minimum != nil && val < *s.Minimum {
+			fail("must be greater than or equal to %v", *s.Minimum)
+		}
+		if s.ExclusiveMinimum != nil && val <= *s.ExclusiveMinimum {
+			fail("must be greater than %v", *s.ExclusiveMinimum)
+		}
+		if s.Maximum != nil && val > *s.Maximum {
+			fail("must be less than or equal to %v", *s.Maximum)
+		}
+	case string:
+		if s.Pattern != "" {
+			re, err := regexp.Compile(s.Pattern)
+			if err == nil && !re.MatchString(val) {
+				fail("must match the pattern %s", s.Pattern)
+			}
+		}
+	}
+}
+
+// hasType is a function that returns true if a value decoded from JSON is of the JSON Schema type
+func hasType(value any, typ string) bool {
+	switch val := value.(type) {
+	case map[string]any:
+		return typ == "object"
+	case []any:
+		return typ == "array"
+	case string:
+		return typ == "string"
+	case bool:
+		return typ == "boolean"
+	case float64:
+		return typ == "number" || (typ == "integer" && val == math.Trunc(val))
+	case nil:
+		return typ == "null"
+	}
+	return false
+}
+
+// escape is a function that escapes a property name as a JSON pointer token
+func escape(name string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(name)
+}
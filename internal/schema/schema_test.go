@@ -0,0 +1,57 @@
+package schema
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSchema_Validate(t *testing.T) {
+	closed := false
+	min := 1.0
+	s := &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"id":    {Type: "integer", Minimum: &min},
+			"color": {Type: "string", Enum: []any{"red", "blue"}},
+			"a/b":   {Type: "string", Pattern: "^[a-z]+$"},
+		},
+		Required:             []string{"id", "color"},
+		AdditionalProperties: &closed,
+	}
+
+	cases := []struct {
+		name     string
+		body     string
+		pointers []string
+	}{
+		{name: "valid", body: `{"id": 1, "color": "red"}`},
+		{name: "missing", body: `{}`, pointers: []string{"/id", "/color"}},
+		{name: "types", body: `{"id": 1.5, "color": 3}`, pointers: []string{"/color", "/id"}},
+		{name: "constraints", body: `{"id": 0, "color": "green", "a/b": "A"}`, pointers: []string{"/a~1b", "/color", "/id"}},
+		{name: "unknown", body: `{"id": 1, "color": "red", "wheels": 4}`, pointers: []string{"/wheels"}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var value any
+			if err := json.Unmarshal([]byte(c.body), &value); err != nil {
+				t.Fatal(err)
+			}
+			errs := s.Validate(value)
+			if len(errs) != len(c.pointers) {
+				t.Fatalf("errors = %v, want %v", errs, c.pointers)
+			}
+			for i, err := range errs {
+				if err.Pointer != c.pointers[i] {
+					t.Fatalf("errors = %v, want %v", errs, c.pointers)
+				}
+			}
+		})
+	}
+
+	// - partial schemas do not require properties
+	var value any
+	json.Unmarshal([]byte(`{"color": "blue"}`), &value)
+	if errs := s.Partial().Validate(value); len(errs) != 0 || len(s.Required) != 2 {
+		t.Fatalf("partial: errors = %v, want none and the original schema unchanged", errs)
+	}
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"app/internal"
+	"errors"
+)
+
+// NewFleetDefault is a function that returns a new instance of FleetDefault
+func NewFleetDefault(rp internal.FleetRepository, sv internal.VehicleService) *FleetDefault {
+	return &FleetDefault{rp: rp, sv: sv}
+}
+
+// FleetDefault is a struct that represents the default service for fleets
+type FleetDefault struct {
+	// rp is the repository that will be used by the service
+	rp internal.FleetRepository
+	// sv is the service of the vehicles that are assigned to the fleets
+	sv internal.VehicleService
+}
+
+// FindAll is a method that returns a map of all fleets
+func (s *FleetDefault) FindAll() (f map[int]internal.Fleet, err error) {
+	f, err = s.rp.FindAll()
+	return
+}
+
+// FindById is a method that returns a fleet by id
+func (s *FleetDefault) FindById(id int) (f internal.Fleet, err error) {
+	f, err = s.rp.FindById(id)
+	return
+}
+
+// Create is a method that creates a new fleet without vehicles
+func (s *FleetDefault) Create(f internal.Fleet) (created internal.Fleet, err error) {
+	created, err = s.rp.Create(f)
+	return
+}
+
+// Update is a method that replaces the name and the depot of a fleet
+func (s *FleetDefault) Update(f internal.Fleet) (updated internal.Fleet, err error) {
+	updated, err = s.rp.Update(f)
+	return
+}
+
+// Delete is a method that deletes a fleet
+func (s *FleetDefault) Delete(id int) (err error) {
+	err = s.rp.Delete(id)
+	return
+}
+
+// Assign is a method that assigns vehicles to a fleet, every vehicle must exist.
+// The vehicles are checked and assigned in a transaction of the vehicles, so none can be deleted meanwhile
+func (s *FleetDefault) Assign(id int, vehicleIds []int) (f internal.Fleet, err error) {
+	err = s.sv.Transaction(func(sv internal.VehicleService) (err error) {
+		for _, vehicleId := range vehicleIds {
+			if _, err = sv.FindById(vehicleId); err != nil {
+				return
+			}
+		}
+		f, err = s.rp.Assign(id, vehicleIds)
+		return
+	})
+	return
+}
+
+// Unassign is a method that removes vehicles from a fleet
+func (s *FleetDefault) Unassign(id int, vehicleIds []int) (f internal.Fleet, err error) {
+	f, err = s.rp.Unassign(id, vehicleIds)
+	return
+}
+
+// Vehicles is a method that returns a map of the vehicles of a fleet that match the filter
+func (s *FleetDefault) Vehicles(id int, vf internal.VehicleFilter) (v map[int]internal.Vehicle, err error) {
+	f, err := s.rp.FindById(id)
+	if err != nil {
+		return
+	}
+
+	v = make(map[int]internal.Vehicle, len(f.VehicleIds))
+	for _, vehicleId := range f.VehicleIds {
+		vh, errFind := s.sv.FindById(vehicleId)
+		switch {
+		case errors.Is(errFind, internal.ErrVehicleNotFound):
+			// - deleted since it was assigned
+			continue
+		case errFind != nil:
+			err = errFind
+			return
+		}
+		if vf.Match(vh) {
+			v[vehicleId] = vh
+		}
+	}
+	return
+}
+
+// Stats is a method that returns the statistics of the vehicles of a fleet
+func (s *FleetDefault) Stats(id int) (st internal.FleetStats, err error) {
+	v, err := s.Vehicles(id, internal.VehicleFilter{})
+	if err != nil {
+		return
+	}
+
+	st = internal.FleetStats{
+		Vehicles:   len(v),
+		ByFuelType: make(map[string]int),
+		ByBrand:    make(map[string]int),
+	}
+	if len(v) == 0 {
+		return
+	}
+	for _, vh := range v {
+		st.AverageMaxSpeed += vh.MaxSpeed
+		st.AverageYear += float64(vh.FabricationYear)
+		st.TotalCapacity += vh.Capacity
+		st.ByFuelType[vh.FuelType]++
+		st.ByBrand[vh.Brand]++
+	}
+	st.AverageMaxSpeed /= float64(len(v))
+	st.AverageYear /= float64(len(v))
+	return
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"app/internal"
+	"app/internal/repository"
+	"errors"
+	"testing"
+)
+
+// newFleetService is a function that returns a fleet service with the vehicles 1 to n and a fleet without vehicles,
+// the deleted vehicles are released from their fleets as in the application
+func newFleetService(t *testing.T, n int) (sv *FleetDefault, rp *repository.VehicleMap, fleet internal.Fleet) {
+	t.Helper()
+	db := make(map[int]internal.Vehicle, n)
+	for id := 1; id <= n; id++ {
+		db[id] = internal.Vehicle{Id: id, VehicleAttributes: internal.VehicleAttributes{Brand: "Ford", Capacity: 5, MaxSpeed: 100}}
+	}
+	rp = repository.NewVehicleMap(db)
+	fm := repository.NewFleetMap()
+	rp.OnDelete(fm.Release)
+	sv = NewFleetDefault(fm, NewVehicleDefault(rp))
+
+	fleet, err := sv.Create(internal.Fleet{Name: "North"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	return
+}
+
+func TestFleetDefault_Assign(t *testing.T) {
+	sv, _, fleet := newFleetService(t, 3)
+
+	// - an unknown vehicle assigns none of the vehicles
+	if _, err := sv.Assign(fleet.Id, []int{1, 9}); !errors.Is(err, internal.ErrVehicleNotFound) {
+		t.Fatalf("unknown vehicle: err = %v, want ErrVehicleNotFound", err)
+	}
+	if f, _ := sv.FindById(fleet.Id); len(f.VehicleIds) != 0 {
+		t.Fatalf("vehicle ids = %v, want none", f.VehicleIds)
+	}
+
+	// - the vehicles of another fleet are rejected with the criteria that caused it
+	if _, err := sv.Assign(fleet.Id, []int{1, 2}); err != nil {
+		t.Fatalf("assign: %v", err)
+	}
+	other, _ := sv.Create(internal.Fleet{Name: "South"})
+	_, err := sv.Assign(other.Id, []int{3, 2})
+	var repoErr *internal.RepositoryError
+	if !errors.Is(err, internal.ErrVehicleAssigned) || !errors.As(err, &repoErr) || repoErr.CriteriaString() != "vehicle_id=2, fleet_id=1" {
+		t.Fatalf("assigned vehicle: err = %v, want ErrVehicleAssigned with vehicle_id=2, fleet_id=1", err)
+	}
+	if _, err := sv.Assign(99, []int{3}); !errors.Is(err, internal.ErrFleetNotFound) {
+		t.Fatalf("unknown fleet: err = %v, want ErrFleetNotFound", err)
+	}
+}
+
+// deletingFinder is a struct that deletes the vehicles right after finding them, like a concurrent request would
+type deletingFinder struct {
+	internal.VehicleService
+	// rp is the repository the vehicles are deleted from
+	rp *repository.VehicleMap
+}
+
+// FindById is a method that returns a vehicle by id and deletes it
+func (s deletingFinder) FindById(id int) (v internal.Vehicle, err error) {
+	if v, err = s.VehicleService.FindById(id); err != nil {
+		return
+	}
+	err = s.rp.Delete(id)
+	return
+}
+
+func TestFleetDefault_Assign_Delete(t *testing.T) {
+	_, rp, fleet := newFleetService(t, 3)
+	fm := repository.NewFleetMap()
+	rp.OnDelete(fm.Release)
+	sv := NewFleetDefault(fm, deletingFinder{VehicleService: NewVehicleDefault(rp), rp: rp})
+	fleet, _ = sv.Create(fleet)
+
+	// - the vehicles are checked in the transaction, so they can not be deleted between the check and the assignment
+	sv.Assign(fleet.Id, []int{1, 2})
+	f, err := sv.FindById(fleet.Id)
+	if err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	for _, id := range f.VehicleIds {
+		if _, err := rp.FindById(id); err != nil {
+			t.Fatalf("vehicle %d of the fleet: %v", id, err)
+		}
+	}
+}
@@ -44,8 +44,8 @@ func (s *VehicleDefault) AverageSpeed(brand string) (average float64, err error)
 }
 
 // CreateBatch is a method that creates multiple vehicles
-func (s *VehicleDefault) CreateBatch(v []internal.Vehicle) (err error) {
-	err = s.rp.CreateBatch(v)
+func (s *VehicleDefault) CreateBatch(v []internal.Vehicle, partial, dryRun bool) (err error) {
+	err = s.rp.CreateBatch(v, partial, dryRun)
 	return
 }
 
@@ -78,3 +78,59 @@ func (s *VehicleDefault) FindByDimensions(minlength, maxlength, minwidth, maxwid
 	v, err = s.rp.FindByDimensions(minlength, maxlength, minwidth, maxwidth)
 	return
 }
+
+// FindByFilter is a method that returns a map of the vehicles that match the filter
+func (s *VehicleDefault) FindByFilter(f internal.VehicleFilter) (v map[int]internal.Vehicle, err error) {
+	v, err = s.rp.FindByFilter(f)
+	return
+}
+
+// FindPage is a method that returns a page of the vehicles that match the filter, sorted by id
+func (s *VehicleDefault) FindPage(f internal.VehicleFilter, afterId, limit int) (v []internal.Vehicle, err error) {
+	v, err = s.rp.FindPage(f, afterId, limit)
+	return
+}
+
+// Import is a method that creates or replaces multiple vehicles applying the policy on id conflicts
+func (s *VehicleDefault) Import(v []internal.Vehicle, policy internal.ImportPolicy, dryRun bool) (outcomes []internal.ImportOutcome, err error) {
+	outcomes, err = s.rp.Import(v, policy, dryRun)
+	return
+}
+
+// FindById is a method that returns a vehicle by id
+func (s *VehicleDefault) FindById(id int) (v internal.Vehicle, err error) {
+	v, err = s.rp.FindById(id)
+	return
+}
+
+// Save is a method that creates or replaces a vehicle, returning true if it was created
+func (s *VehicleDefault) Save(v internal.Vehicle) (created bool, err error) {
+	created, err = s.rp.Save(v)
+	return
+}
+
+// Update is a method that replaces a vehicle with the result of a function of its current value, atomically
+func (s *VehicleDefault) Update(id int, update func(v internal.Vehicle) (internal.Vehicle, error)) (v internal.Vehicle, err error) {
+	v, err = s.rp.Update(id, update)
+	return
+}
+
+// UpdateByFilter is a method that replaces the vehicles that match the filter with the result of a function of their current value, atomically
+func (s *VehicleDefault) UpdateByFilter(f internal.VehicleFilter, update func(v internal.Vehicle) (internal.Vehicle, error), dryRun bool) (ids []int, err error) {
+	ids, err = s.rp.UpdateByFilter(f, update, dryRun)
+	return
+}
+
+// DeleteByFilter is a method that deletes the vehicles that match the filter, atomically
+func (s *VehicleDefault) DeleteByFilter(f internal.VehicleFilter, dryRun bool) (ids []int, err error) {
+	ids, err = s.rp.DeleteByFilter(f, dryRun)
+	return
+}
+
+// Transaction is a method that runs a function with a service whose changes are applied all at once, only if the function succeeds
+func (s *VehicleDefault) Transaction(fn func(sv internal.VehicleService) error) (err error) {
+	err = s.rp.Transaction(func(rp internal.VehicleRepository) error {
+		return fn(NewVehicleDefault(rp))
+	})
+	return
+}
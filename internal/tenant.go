@@ -0,0 +1,15 @@
+package internal
+
+import "errors"
+
+// DefaultTenant is the tenant of the requests that do not name one, and of the servers with a single fleet
+const DefaultTenant = "default"
+
+var (
+	// ErrTenantNotFound is an error that represents a tenant that does not exist
+	ErrTenantNotFound = errors.New("tenant not found")
+	// ErrTenantForbidden is an error that represents a tenant other than the one of the credentials
+	ErrTenantForbidden = errors.New("tenant forbidden")
+	// ErrQuotaExceeded is an error that represents a change that exceeds the quota of a tenant
+	ErrQuotaExceeded = errors.New("quota exceeded")
+)
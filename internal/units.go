@@ -0,0 +1,142 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+)
+
+// The canonical units of the measures of a vehicle, the ones of the datasets and the ones stored by the repository
+const (
+	// SpeedUnit is the canonical unit of the speed
+	SpeedUnit = "mph"
+	// LengthUnit is the canonical unit of the dimensions
+	LengthUnit = "cm"
+	// WeightUnit is the canonical unit of the weight
+	WeightUnit = "kg"
+)
+
+// The factors from the canonical units to the ones of the unit systems
+const (
+	// kmhPerMph is the number of kilometers per hour in a mile per hour
+	kmhPerMph = 1.609344
+	// inPerCm is the number of inches in a centimeter
+	inPerCm = 1 / 2.54
+	// lbPerKg is the number of pounds in a kilogram
+	lbPerKg = 2.20462
+)
+
+// UnitSystem is a system of units of the measures of a vehicle
+type UnitSystem string
+
+const (
+	// UnitsMetric is the metric system: kilometers per hour, centimeters and kilograms
+	UnitsMetric UnitSystem = "metric"
+	// UnitsImperial is the imperial system: miles per hour, inches and pounds
+	UnitsImperial UnitSystem = "imperial"
+)
+
+var (
+	// ErrUnknownUnitSystem is an error for an unsupported unit system
+	ErrUnknownUnitSystem = errors.New("unknown unit system")
+)
+
+// ParseUnitSystem is a function that returns the unit system of its name, metric if the name is empty
+func ParseUnitSystem(name string) (u UnitSystem, err error) {
+	switch u = UnitSystem(name); u {
+	case "":
+		u = UnitsMetric
+	case UnitsMetric, UnitsImperial:
+	default:
+		err = fmt.Errorf("%w: %s", ErrUnknownUnitSystem, name)
+	}
+	return
+}
+
+// SpeedUnit is a method that returns the unit of the speed in the unit system
+func (u UnitSystem) SpeedUnit() string {
+	if u == UnitsImperial {
+		return SpeedUnit
+	}
+	return "km/h"
+}
+
+// LengthUnit is a method that returns the unit of the dimensions in the unit system
+func (u UnitSystem) LengthUnit() string {
+	if u == UnitsImperial {
+		return "in"
+	}
+	return LengthUnit
+}
+
+// WeightUnit is a method that returns the unit of the weight in the unit system
+func (u UnitSystem) WeightUnit() string {
+	if u == UnitsImperial {
+		return "lb"
+	}
+	return WeightUnit
+}
+
+// factors is a method that returns the factors from the canonical units to the unit system,
+// of the speed, the dimensions and the weight
+func (u UnitSystem) factors() (speed, length, weight float64) {
+	if u == UnitsImperial {
+		return 1, inPerCm, lbPerKg
+	}
+	return kmhPerMph, 1, 1
+}
+
+// Speed is a method that converts a speed from the canonical unit to the unit system
+func (u UnitSystem) Speed(mph float64) float64 {
+	speed, _, _ := u.factors()
+	return mph * speed
+}
+
+// CanonicalSpeed is a method that converts a speed from the unit system to the canonical unit
+func (u UnitSystem) CanonicalSpeed(speed float64) float64 {
+	factor, _, _ := u.factors()
+	return speed / factor
+}
+
+// Length is a method that converts a dimension from the canonical unit to the unit system
+func (u UnitSystem) Length(cm float64) float64 {
+	_, length, _ := u.factors()
+	return cm * length
+}
+
+// CanonicalLength is a method that converts a dimension from the unit system to the canonical unit
+func (u UnitSystem) CanonicalLength(length float64) float64 {
+	_, factor, _ := u.factors()
+	return length / factor
+}
+
+// Weight is a method that converts a weight from the canonical unit to the unit system
+func (u UnitSystem) Weight(kg float64) float64 {
+	_, _, weight := u.factors()
+	return kg * weight
+}
+
+// CanonicalWeight is a method that converts a weight from the unit system to the canonical unit
+func (u UnitSystem) CanonicalWeight(weight float64) float64 {
+	_, _, factor := u.factors()
+	return weight / factor
+}
+
+// Vehicle is a method that returns the vehicle with its measures converted from the canonical units to the unit system
+func (u UnitSystem) Vehicle(v Vehicle) Vehicle {
+	v.MaxSpeed = u.Speed(v.MaxSpeed)
+	v.Weight = u.Weight(v.Weight)
+	v.Height = u.Length(v.Height)
+	v.Length = u.Length(v.Length)
+	v.Width = u.Length(v.Width)
+	return v
+}
+
+// CanonicalVehicle is a method that returns the vehicle with its measures converted from the unit system to the canonical units
+func (u UnitSystem) CanonicalVehicle(v Vehicle) Vehicle {
+	v.MaxSpeed = u.CanonicalSpeed(v.MaxSpeed)
+	v.Weight = u.CanonicalWeight(v.Weight)
+	v.Height = u.CanonicalLength(v.Height)
+	v.Length = u.CanonicalLength(v.Length)
+	v.Width = u.CanonicalLength(v.Width)
+	return v
+}
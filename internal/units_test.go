@@ -0,0 +1,61 @@
+package internal
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestParseUnitSystem(t *testing.T) {
+	cases := []struct {
+		name string
+		u    UnitSystem
+		err  error
+	}{
+		{name: "", u: UnitsMetric},
+		{name: "metric", u: UnitsMetric},
+		{name: "imperial", u: UnitsImperial},
+		{name: "nautical", u: "nautical", err: ErrUnknownUnitSystem},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			u, err := ParseUnitSystem(c.name)
+			if u != c.u || !errors.Is(err, c.err) {
+				t.Fatalf("unit system = %q, err = %v, want %q and %v", u, err, c.u, c.err)
+			}
+		})
+	}
+}
+
+func TestUnitSystem_Vehicle(t *testing.T) {
+	v := Vehicle{Id: 1}
+	v.MaxSpeed, v.Weight, v.Height, v.Length, v.Width = 100, 1000, 254, 0, 127
+
+	// - metric: the speed in km/h, the rest as they are
+	metric := UnitsMetric.Vehicle(v)
+	if math.Abs(metric.MaxSpeed-160.93) > 0.01 || metric.Weight != v.Weight || metric.Height != v.Height || UnitsMetric.SpeedUnit() != "km/h" {
+		t.Fatalf("metric vehicle = %+v, want km/h, centimeters and kilograms", metric)
+	}
+
+	// - imperial
+	imperial := UnitsImperial.Vehicle(v)
+	if imperial.MaxSpeed != v.MaxSpeed || imperial.Height != 100 || imperial.Width != 50 || imperial.Length != 0 || math.Abs(imperial.Weight-2204.62) > 0.01 {
+		t.Fatalf("imperial vehicle = %+v, want inches and pounds", imperial)
+	}
+	if UnitsImperial.LengthUnit() != "in" || UnitsImperial.WeightUnit() != "lb" || UnitsImperial.SpeedUnit() != SpeedUnit {
+		t.Fatalf("imperial units = %s %s %s", UnitsImperial.LengthUnit(), UnitsImperial.WeightUnit(), UnitsImperial.SpeedUnit())
+	}
+
+	// - back to the canonical units
+	back := UnitsImperial.CanonicalVehicle(imperial)
+	for name, pair := range map[string][2]float64{
+		"max_speed": {back.MaxSpeed, v.MaxSpeed},
+		"weight":    {back.Weight, v.Weight},
+		"height":    {back.Height, v.Height},
+		"width":     {back.Width, v.Width},
+	} {
+		if math.Abs(pair[0]-pair[1]) > 1e-9 {
+			t.Fatalf("%s = %v, want %v", name, pair[0], pair[1])
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package validation
+
+import (
+	"app/internal/tools"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Rule is a struct that represents the constraints of a field of a vehicle, named as in JSON
+type Rule struct {
+	// Field is the name of the field
+	Field string `json:"field"`
+	// Required is true if the field must have a non-zero value, otherwise a zero value skips the rest of constraints
+	Required bool `json:"required,omitempty"`
+	// Min is the minimum value of a numeric field
+	Min *float64 `json:"min,omitempty"`
+	// ExclusiveMin is the value a numeric field must be greater than
+	ExclusiveMin *float64 `json:"exclusive_min,omitempty"`
+	// Max is the maximum value of a numeric field
+	Max *float64 `json:"max,omitempty"`
+	// MaxYearOffset sets the maximum value of a numeric field to the current year plus the offset
+	MaxYearOffset *int `json:"max_year_offset,omitempty"`
+	// Enum are the allowed values of a text field
+	Enum []string `json:"enum,omitempty"`
+	// Pattern is the regular expression a text field must match
+	Pattern string `json:"pattern,omitempty"`
+	// When is the condition of the vehicle for the rule to apply, the rule always applies if it is nil
+	When *Condition `json:"when,omitempty"`
+}
+
+// Condition is a struct that represents a condition on a field of a vehicle, named as in JSON
+type Condition struct {
+	// Field is the name of the field
+	Field string `json:"field"`
+	// Equals is the value the field must have
+	Equals any `json:"equals,omitempty"`
+	// In are the values the field may have
+	In []any `json:"in,omitempty"`
+}
+
+// Match is a method that returns true if the value of the field meets the condition
+func (c *Condition) Match(value any) bool {
+	if c.Equals != nil && !equalValues(value, c.Equals) {
+		return false
+	}
+	if len(c.In) == 0 {
+		return true
+	}
+	for _, allowed := range c.In {
+		if equalValues(value, allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// equalValues is a function that compares two field values, numbers are equal regardless of their type
+func equalValues(a, b any) bool {
+	if na, ok := toFloat(a); ok {
+		nb, ok := toFloat(b)
+		return ok && na == nb
+	}
+	return a == b
+}
+
+// Check is a method that returns the violations of the rule by the value of the field
+func (r Rule) Check(value any) (errs []*tools.FieldError) {
+	fail := func(format string, args ...any) {
+		errs = append(errs, &tools.FieldError{Field: r.Field, Msg: fmt.Sprintf(format, args...)})
+	}
+
+	// zero value
+	if isZero(value) {
+		if r.Required {
+			fail("field is required")
+		}
+		return
+	}
+
+	// numeric constraints
+	if r.Min != nil || r.ExclusiveMin != nil || r.Max != nil || r.MaxYearOffset != nil {
+		number, ok := toFloat(value)
+		if !ok {
+			fail("must be a number")
+			return
+		}
+		if r.Min != nil && number < *r.Min {
+			fail("must be greater than or equal to %v", *r.Min)
+		}
+		if r.ExclusiveMin != nil && number <= *r.ExclusiveMin {
+			fail("must be greater than %v", *r.ExclusiveMin)
+		}
+		if max, ok := r.max(); ok && number > max {
+			fail("must be less than or equal to %v", max)
+		}
+	}
+
+	// text constraints
+	if len(r.Enum) > 0 || r.Pattern != "" {
+		text, ok := value.(string)
+		if !ok {
+			fail("must be a string")
+			return
+		}
+		if r.Pattern != "" {
+			if matched, err := regexp.MatchString(r.Pattern, text); err != nil || !matched {
+				fail("must match the pattern %s", r.Pattern)
+			}
+		}
+	}
+	if len(r.Enum) > 0 {
+		text, ok := value.(string)
+		if !ok {
+			fail("must be a string")
+			return
+		}
+		found := false
+		for _, allowed := range r.Enum {
+			if text == allowed {
+				found = true
+				break
+			}
+		}
+		if !found {
+			fail("must be one of: %s", strings.Join(r.Enum, ", "))
+		}
+	}
+
+	return
+}
+
+// max is a method that returns the effective maximum of the rule, false if it has none
+func (r Rule) max() (max float64, ok bool) {
+	if r.Max != nil {
+		max, ok = *r.Max, true
+	}
+	if r.MaxYearOffset != nil {
+		yearMax := float64(time.Now().Year() + *r.MaxYearOffset)
+		if !ok || yearMax < max {
+			max, ok = yearMax, true
+		}
+	}
+	return
+}
+
+// isZero is a function that returns true if the value is missing or the zero value of its type
+func isZero(value any) bool {
+	switch val := value.(type) {
+	case nil:
+		return true
+	case string:
+		return val == ""
+	case int:
+		return val == 0
+	case float64:
+		return val == 0
+	}
+	return false
+}
+
+// toFloat is a function that returns the value of a number, decoded from JSON or not
+func toFloat(value any) (number float64, ok bool) {
+	switch val := value.(type) {
+	case int:
+		return float64(val), true
+	case float64:
+		return val, true
+	}
+	return
+}
@@ -0,0 +1,65 @@
+package validation
+
+import (
+	"app/internal"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+)
+
+var (
+	// ErrInvalidRule is an error that represents a rule that can not be applied
+	ErrInvalidRule = errors.New("invalid rule")
+)
+
+// RulesFile is a struct that represents the content of a rules file
+type RulesFile struct {
+	// Rules are the rules, in order
+	Rules []Rule `json:"rules"`
+}
+
+// LoadRulesFile is a function that reads the rules from a JSON file and checks that they can be applied
+func LoadRulesFile(path string) (rules []Rule, err error) {
+	// open file
+	file, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	// decode file
+	var rf RulesFile
+	dec := json.NewDecoder(file)
+	dec.DisallowUnknownFields()
+	if err = dec.Decode(&rf); err != nil {
+		return
+	}
+
+	// check rules
+	fields := VehicleFields(internal.Vehicle{})
+	for i, rule := range rf.Rules {
+		if _, ok := fields[rule.Field]; !ok {
+			err = fmt.Errorf("%w %d: unknown field %q", ErrInvalidRule, i, rule.Field)
+			return
+		}
+		if rule.When != nil {
+			if _, ok := fields[rule.When.Field]; !ok {
+				err = fmt.Errorf("%w %d: unknown condition field %q", ErrInvalidRule, i, rule.When.Field)
+				return
+			}
+		}
+		if _, errPattern := regexp.Compile(rule.Pattern); errPattern != nil {
+			err = fmt.Errorf("%w %d: %v", ErrInvalidRule, i, errPattern)
+			return
+		}
+		if rule.Min != nil && rule.Max != nil && *rule.Min > *rule.Max {
+			err = fmt.Errorf("%w %d: min is greater than max", ErrInvalidRule, i)
+			return
+		}
+	}
+
+	rules = rf.Rules
+	return
+}
@@ -0,0 +1,70 @@
+package validation
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadRulesFile(t *testing.T) {
+	// - sample rules of the repository, with a condition on the fuel type
+	rules, err := LoadRulesFile(filepath.Join("..", "..", "docs", "rules", "electric_fleet.json"))
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	vr := NewVehicleRules(rules)
+
+	v := newVehicle()
+	v.FuelType, v.Transmission = "hybrid", "manual"
+	if err := vr.Validate(v); err != nil {
+		t.Fatalf("hybrid manual: err = %v, want nil", err)
+	}
+	v.FuelType = "electric"
+	if errs, ok := AsErrors(vr.Validate(v)); !ok || len(errs) != 1 || errs[0].Field != "transmission" {
+		t.Fatalf("electric manual: violations = %v, want transmission", errs)
+	}
+
+}
+
+func TestLoadRulesFile_Errors(t *testing.T) {
+	cases := []struct {
+		name    string
+		content string
+		invalid bool
+	}{
+		{name: "unknown field", content: `{"rules": [{"field": "wheels", "required": true}]}`, invalid: true},
+		{name: "unknown condition field", content: `{"rules": [{"field": "brand", "when": {"field": "wheels", "equals": 4}}]}`, invalid: true},
+		{name: "min greater than max", content: `{"rules": [{"field": "year", "min": 2020, "max": 2010}]}`, invalid: true},
+		{name: "malformed pattern", content: `{"rules": [{"field": "registration", "pattern": "["}]}`, invalid: true},
+		{name: "unknown constraint", content: `{"rules": [{"field": "year", "maximum": 2010}]}`},
+		{name: "malformed", content: `{"rules": [`},
+	}
+	dir := t.TempDir()
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			path := filepath.Join(dir, "rules.json")
+			if err := os.WriteFile(path, []byte(c.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			_, err := LoadRulesFile(path)
+			if err == nil || errors.Is(err, ErrInvalidRule) != c.invalid {
+				t.Fatalf("err = %v, want an error, ErrInvalidRule %t", err, c.invalid)
+			}
+		})
+	}
+}
+
+func TestVehicleRules_Set(t *testing.T) {
+	vr := NewVehicleRules(nil)
+	vr.Set([]Rule{{Field: "brand", Required: true}})
+
+	if rules := vr.Rules(); len(rules) != 1 || rules[0].Field != "brand" {
+		t.Fatalf("rules = %+v, want only brand", rules)
+	}
+	v := newVehicle()
+	v.FabricationYear = 0
+	if err := vr.Validate(v); err != nil {
+		t.Fatalf("err = %v, want nil with the replaced rules", err)
+	}
+}
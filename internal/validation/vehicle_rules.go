@@ -0,0 +1,130 @@
+package validation
+
+import (
+	"app/internal"
+	"app/internal/tools"
+	"errors"
+	"strings"
+	"sync"
+)
+
+// Errors is a collection of violations of the validation rules
+type Errors []*tools.FieldError
+
+// Error is a method that returns all the violations in a single message
+func (e Errors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "; ")
+}
+
+// AsErrors is a function that returns the violations of an error returned by a validator, false if it is another error
+func AsErrors(err error) (errs Errors, ok bool) {
+	ok = errors.As(err, &errs)
+	return
+}
+
+// ptr is a function that returns a pointer to the value, for the optional constraints of the rules
+func ptr[T any](value T) *T {
+	return &value
+}
+
+// DefaultRules is a function that returns the default rules of the vehicles
+func DefaultRules() []Rule {
+	return []Rule{
+		{Field: "id", Required: true, ExclusiveMin: ptr(0.0)},
+		{Field: "brand", Required: true},
+		{Field: "model", Required: true},
+		{Field: "registration", Required: true, Pattern: "^[A-Za-z0-9-]+$"},
+		{Field: "color", Required: true},
+		// the first automobile is from 1886, the next year models are already on sale
+		{Field: "year", Required: true, Min: ptr(1886.0), MaxYearOffset: ptr(1)},
+		{Field: "passengers", Required: true, Min: ptr(1.0), Max: ptr(100.0)},
+		{Field: "max_speed", Required: true, ExclusiveMin: ptr(0.0)},
+		{Field: "fuel_type", Required: true, Enum: []string{"gas", "gasoline", "diesel", "biodiesel", "electric", "hybrid"}},
+		{Field: "transmission", Required: true, Enum: []string{"automatic", "manual", "semi-automatic"}},
+		{Field: "weight", Required: true, ExclusiveMin: ptr(0.0)},
+		{Field: "height", Required: true, ExclusiveMin: ptr(0.0)},
+		// length is optional, the datasets do not always have it
+		{Field: "length", ExclusiveMin: ptr(0.0)},
+		{Field: "width", Required: true, ExclusiveMin: ptr(0.0)},
+	}
+}
+
+// NewVehicleRules is a function that returns a new instance of VehicleRules
+func NewVehicleRules(rules []Rule) *VehicleRules {
+	// default values
+	defaultRules := DefaultRules()
+	if rules != nil {
+		defaultRules = rules
+	}
+
+	return &VehicleRules{rules: defaultRules}
+}
+
+// VehicleRules is a struct that implements the VehicleValidator interface with a list of rules,
+// which can be replaced at runtime
+type VehicleRules struct {
+	// mu guards rules
+	mu sync.RWMutex
+	// rules are the rules checked, in order
+	rules []Rule
+}
+
+// Rules is a method that returns the current rules
+func (vr *VehicleRules) Rules() (rules []Rule) {
+	vr.mu.RLock()
+	defer vr.mu.RUnlock()
+
+	rules = append(rules, vr.rules...)
+	return
+}
+
+// Set is a method that replaces the rules
+func (vr *VehicleRules) Set(rules []Rule) {
+	vr.mu.Lock()
+	defer vr.mu.Unlock()
+
+	vr.rules = rules
+}
+
+// Validate is a method that returns all the violations of the rules by the vehicle
+func (vr *VehicleRules) Validate(v internal.Vehicle) (err error) {
+	vr.mu.RLock()
+	defer vr.mu.RUnlock()
+	fields := VehicleFields(v)
+
+	var errs Errors
+	for _, rule := range vr.rules {
+		if rule.When != nil && !rule.When.Match(fields[rule.When.Field]) {
+			continue
+		}
+		errs = append(errs, rule.Check(fields[rule.Field])...)
+	}
+	if len(errs) > 0 {
+		err = errs
+	}
+	return
+}
+
+// VehicleFields is a function that returns the fields of a vehicle, named as in JSON
+func VehicleFields(v internal.Vehicle) map[string]any {
+	return map[string]any{
+		"id":           v.Id,
+		"brand":        v.Brand,
+		"model":        v.Model,
+		"registration": v.Registration,
+		"color":        v.Color,
+		"year":         v.FabricationYear,
+		"passengers":   v.Capacity,
+		"max_speed":    v.MaxSpeed,
+		"fuel_type":    v.FuelType,
+		"transmission": v.Transmission,
+		"weight":       v.Weight,
+		"height":       v.Height,
+		"length":       v.Length,
+		"width":        v.Width,
+	}
+}
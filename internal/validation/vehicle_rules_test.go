@@ -0,0 +1,70 @@
+package validation
+
+import (
+	"app/internal"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// newVehicle is a function that returns a vehicle that satisfies the default rules
+func newVehicle() internal.Vehicle {
+	v := internal.Vehicle{Id: 1}
+	v.Brand, v.Model, v.Registration, v.Color = "Ford", "Focus", "REG-1", "red"
+	v.FabricationYear, v.Capacity, v.MaxSpeed = 2020, 5, 120
+	v.FuelType, v.Transmission = "gasoline", "manual"
+	v.Weight, v.Height, v.Width = 1300, 150, 180
+	return v
+}
+
+func TestVehicleRules_Validate(t *testing.T) {
+	vr := NewVehicleRules(nil)
+
+	// - valid, the length is optional
+	if err := vr.Validate(newVehicle()); err != nil {
+		t.Fatalf("valid: err = %v, want nil", err)
+	}
+
+	// - every violation is reported at once
+	v := newVehicle()
+	v.Brand, v.FabricationYear, v.FuelType, v.Capacity = "", 1800, "steam", 0
+	errs, ok := AsErrors(vr.Validate(v))
+	if !ok {
+		t.Fatalf("invalid: want the violations of the rules")
+	}
+	fields := make(map[string]bool)
+	for _, err := range errs {
+		fields[err.Field] = true
+	}
+	for _, field := range []string{"brand", "year", "fuel_type", "passengers"} {
+		if !fields[field] {
+			t.Fatalf("violations = %v, want one of %s", errs, field)
+		}
+	}
+	if len(errs) != 4 {
+		t.Fatalf("violations = %v, want 4", errs)
+	}
+}
+
+func TestVehicleRules_Validate_Year(t *testing.T) {
+	next := time.Now().Year() + 1
+	cases := []struct {
+		year  int
+		valid bool
+	}{
+		{year: 1885, valid: false},
+		{year: 1886, valid: true},
+		{year: next, valid: true},
+		{year: next + 1, valid: false},
+	}
+	vr := NewVehicleRules(nil)
+	for _, c := range cases {
+		t.Run(strconv.Itoa(c.year), func(t *testing.T) {
+			v := newVehicle()
+			v.FabricationYear = c.year
+			if err := vr.Validate(v); (err == nil) != c.valid {
+				t.Fatalf("err = %v, want valid %t", err, c.valid)
+			}
+		})
+	}
+}
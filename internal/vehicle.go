@@ -2,11 +2,11 @@ package internal
 
 // Dimensions is a struct that represents a dimension in 3d
 type Dimensions struct {
-	// Height is the height of the dimension
+	// Height is the height of the dimension, in centimeters
 	Height float64
-	// Length is the length of the dimension
+	// Length is the length of the dimension, in centimeters
 	Length float64
-	// Width is the width of the dimension
+	// Width is the width of the dimension, in centimeters
 	Width float64
 }
 
@@ -24,13 +24,13 @@ type VehicleAttributes struct {
 	FabricationYear int
 	// Capacity is the capacity of people of the vehicle
 	Capacity int
-	// MaxSpeed is the maximum speed of the vehicle
+	// MaxSpeed is the maximum speed of the vehicle, in miles per hour
 	MaxSpeed float64
 	// FuelType is the fuel type of the vehicle
 	FuelType string
 	// Transmission is the transmission of the vehicle
 	Transmission string
-	// Weight is the weight of the vehicle
+	// Weight is the weight of the vehicle, in kilograms
 	Weight float64
 	// Dimensions is the dimensions of the vehicle
 	Dimensions
@@ -40,6 +40,8 @@ type VehicleAttributes struct {
 type Vehicle struct {
 	// Id is the unique identifier of the vehicle
 	Id int
+	// Source is the name of the source the vehicle was loaded from, empty if it was created through the API
+	Source string
 
 	// VehicleAttribue is the attributes of a vehicle
 	VehicleAttributes
@@ -0,0 +1,37 @@
+package internal
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// BatchError is an error of a batch of vehicles, with the error of every failing vehicle by its index in the batch
+type BatchError map[int]error
+
+// Indexes is a method that returns the sorted indexes of the failing vehicles
+func (e BatchError) Indexes() (indexes []int) {
+	for i := range e {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+	return
+}
+
+// Error is a method that returns the errors of all the failing vehicles
+func (e BatchError) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, i := range e.Indexes() {
+		msgs = append(msgs, fmt.Sprintf("item %d: %s", i, e[i]))
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Unwrap is a method that returns the errors of all the failing vehicles, sorted by index
+func (e BatchError) Unwrap() []error {
+	errs := make([]error, 0, len(e))
+	for _, i := range e.Indexes() {
+		errs = append(errs, e[i])
+	}
+	return errs
+}
@@ -0,0 +1,61 @@
+package internal
+
+// VehicleFilter is a struct that represents the criteria to select vehicles, zero values are ignored
+type VehicleFilter struct {
+	// Brand is the brand of the vehicles
+	Brand string
+	// Model is the model of the vehicles
+	Model string
+	// Color is the color of the vehicles
+	Color string
+	// FuelType is the fuel type of the vehicles
+	FuelType string
+	// Year is the fabrication year of the vehicles
+	Year int
+	// StartYear is the minimum fabrication year of the vehicles
+	StartYear int
+	// EndYear is the maximum fabrication year of the vehicles
+	EndYear int
+	// MinLength is the minimum length of the vehicles
+	MinLength float64
+	// MaxLength is the maximum length of the vehicles
+	MaxLength float64
+	// MinWidth is the minimum width of the vehicles
+	MinWidth float64
+	// MaxWidth is the maximum width of the vehicles
+	MaxWidth float64
+}
+
+// IsZero is a method that returns true if the filter has no criteria, so it matches every vehicle
+func (f VehicleFilter) IsZero() bool {
+	return f == VehicleFilter{}
+}
+
+// Match is a method that returns true if the vehicle meets all the criteria of the filter
+func (f VehicleFilter) Match(v Vehicle) bool {
+	switch {
+	case f.Brand != "" && v.Brand != f.Brand:
+		return false
+	case f.Model != "" && v.Model != f.Model:
+		return false
+	case f.Color != "" && v.Color != f.Color:
+		return false
+	case f.FuelType != "" && v.FuelType != f.FuelType:
+		return false
+	case f.Year != 0 && v.FabricationYear != f.Year:
+		return false
+	case f.StartYear != 0 && v.FabricationYear < f.StartYear:
+		return false
+	case f.EndYear != 0 && v.FabricationYear > f.EndYear:
+		return false
+	case f.MinLength != 0 && v.Length < f.MinLength:
+		return false
+	case f.MaxLength != 0 && v.Length > f.MaxLength:
+		return false
+	case f.MinWidth != 0 && v.Width < f.MinWidth:
+		return false
+	case f.MaxWidth != 0 && v.Width > f.MaxWidth:
+		return false
+	}
+	return true
+}
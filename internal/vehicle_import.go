@@ -0,0 +1,25 @@
+package internal
+
+// ImportPolicy is the policy applied when an imported vehicle has the id of an existing one
+type ImportPolicy string
+
+const (
+	// ImportSkip keeps the existing vehicle
+	ImportSkip ImportPolicy = "skip"
+	// ImportOverwrite replaces the existing vehicle
+	ImportOverwrite ImportPolicy = "overwrite"
+	// ImportFail makes the whole import fail
+	ImportFail ImportPolicy = "fail"
+)
+
+// ImportOutcome is the outcome of an imported vehicle
+type ImportOutcome string
+
+const (
+	// ImportCreated is the outcome of a new vehicle
+	ImportCreated ImportOutcome = "created"
+	// ImportUpdated is the outcome of a vehicle that replaced an existing one
+	ImportUpdated ImportOutcome = "updated"
+	// ImportSkipped is the outcome of a vehicle that was ignored because its id exists
+	ImportSkipped ImportOutcome = "skipped"
+)
@@ -1,5 +1,65 @@
 package internal
 
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrVehicleNotFound is an error for a vehicle id that does not exist
+	ErrVehicleNotFound = errors.New("vehicle not found")
+	// ErrVehicleAlreadyExists is an error for a vehicle id that already exists
+	ErrVehicleAlreadyExists = errors.New("vehicle already exists")
+	// ErrNoVehiclesFound is an error for a query that matches no vehicles
+	ErrNoVehiclesFound = errors.New("no vehicles found")
+)
+
+// NewRepositoryError is a function that returns a new instance of RepositoryError,
+// the criteria are given as field and value pairs
+func NewRepositoryError(err error, criteria ...any) *RepositoryError {
+	e := &RepositoryError{Err: err}
+	for i := 0; i+1 < len(criteria); i += 2 {
+		e.Criteria = append(e.Criteria, Criterion{Field: fmt.Sprint(criteria[i]), Value: criteria[i+1]})
+	}
+	return e
+}
+
+// RepositoryError is an error of a vehicle repository, one of its sentinel errors with the criteria that caused it
+type RepositoryError struct {
+	// Err is the sentinel error
+	Err error
+	// Criteria are the fields and values that caused the error
+	Criteria []Criterion
+}
+
+// Criterion is a field and a value of a query
+type Criterion struct {
+	// Field is the JSON name of the field
+	Field string
+	// Value is the value of the field
+	Value any
+}
+
+// Error is a method that returns the sentinel error with the criteria
+func (e *RepositoryError) Error() string {
+	return e.Err.Error() + ": " + e.CriteriaString()
+}
+
+// Unwrap is a method that returns the sentinel error
+func (e *RepositoryError) Unwrap() error {
+	return e.Err
+}
+
+// CriteriaString is a method that returns the criteria as a list of field=value
+func (e *RepositoryError) CriteriaString() string {
+	criteria := make([]string, 0, len(e.Criteria))
+	for _, c := range e.Criteria {
+		criteria = append(criteria, fmt.Sprintf("%s=%v", c.Field, c.Value))
+	}
+	return strings.Join(criteria, ", ")
+}
+
 // VehicleRepository is an interface that represents a vehicle repository
 type VehicleRepository interface {
 	// FindAll is a method that returns a map of all vehicles
@@ -12,8 +72,10 @@ type VehicleRepository interface {
 	FindByBrandRange(brand string, startYear, endYear int) (v map[int]Vehicle, err error)
 	//AverageSpeed is a method that returns the average speed of a vehicle by brand
 	AverageSpeed(brand string) (average float64, err error)
-	// CreateBatch is a method that creates multiple vehicles
-	CreateBatch(v []Vehicle) (err error)
+	// CreateBatch is a method that creates multiple vehicles. If any vehicle fails nothing is created, unless partial
+	// is set, then the rest of them are created up to the quota. The error is a BatchError with every failing vehicle.
+	// With dryRun nothing is created
+	CreateBatch(v []Vehicle, partial, dryRun bool) (err error)
 	// UpdateSpeed is a method that updates the speed of a vehicle
 	UpdateSpeed(id int, speed float64) (err error)
 	// FindByFuelType is a method that returns a map of vehicles by fuel type
@@ -24,4 +86,28 @@ type VehicleRepository interface {
 	UpdateFuelType(id int, fuelType string) (err error)
 	// FindByDimensions is a method that returns a map of vehicles by dimensions
 	FindByDimensions(minlength, maxlength, minwidth, maxwidth float64) (v map[int]Vehicle, err error)
+	// FindByFilter is a method that returns a map of the vehicles that match the filter, it may be empty
+	FindByFilter(f VehicleFilter) (v map[int]Vehicle, err error)
+	// FindPage is a method that returns up to limit vehicles that match the filter and have an id greater than afterId,
+	// sorted by id. Reading the pages in turn goes through the vehicles without copying all of them at once
+	FindPage(f VehicleFilter, afterId, limit int) (v []Vehicle, err error)
+	// Import is a method that creates or replaces multiple vehicles applying the policy on id conflicts,
+	// returning the outcome of each vehicle. With dryRun nothing is written
+	Import(v []Vehicle, policy ImportPolicy, dryRun bool) (outcomes []ImportOutcome, err error)
+	// FindById is a method that returns a vehicle by id
+	FindById(id int) (v Vehicle, err error)
+	// Save is a method that creates or replaces a vehicle, returning true if it was created
+	Save(v Vehicle) (created bool, err error)
+	// Update is a method that replaces a vehicle with the result of a function of its current value, atomically.
+	// If the function fails the vehicle is not changed
+	Update(id int, update func(v Vehicle) (Vehicle, error)) (v Vehicle, err error)
+	// UpdateByFilter is a method that replaces the vehicles that match the filter with the result of a function of their
+	// current value, atomically, returning their sorted ids. If the function fails for any vehicle, or with dryRun, nothing is written
+	UpdateByFilter(f VehicleFilter, update func(v Vehicle) (Vehicle, error), dryRun bool) (ids []int, err error)
+	// DeleteByFilter is a method that deletes the vehicles that match the filter, atomically, returning their sorted ids.
+	// With dryRun nothing is deleted
+	DeleteByFilter(f VehicleFilter, dryRun bool) (ids []int, err error)
+	// Transaction is a method that runs a function with a repository whose changes are applied all at once,
+	// only if the function succeeds. No other operation sees the repository in the meantime
+	Transaction(fn func(rp VehicleRepository) error) (err error)
 }
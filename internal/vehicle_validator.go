@@ -0,0 +1,7 @@
+package internal
+
+// VehicleValidator is an interface that represents the validation rules of vehicles
+type VehicleValidator interface {
+	// Validate is a method that returns all the violations of the rules by the vehicle
+	Validate(v Vehicle) (err error)
+}
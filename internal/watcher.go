@@ -0,0 +1,27 @@
+package internal
+
+import "time"
+
+// ReloadStatus is a struct that represents the status of the reloads of a source, such as the vehicles dataset
+type ReloadStatus struct {
+	// Source is the source that is being watched
+	Source string `json:"source"`
+	// Items is the amount of items, such as vehicles, loaded by the last reload
+	Items int `json:"items"`
+	// Reloads is the amount of successful reloads since the watcher started
+	Reloads int `json:"reloads"`
+	// LastCheck is the last time the source was checked for changes
+	LastCheck time.Time `json:"last_check"`
+	// LastReload is the last time the dataset was reloaded successfully
+	LastReload time.Time `json:"last_reload"`
+	// LastError is the error of the last failed reload, empty if the last reload succeeded
+	LastError string `json:"last_error,omitempty"`
+	// LastErrorAt is the time of the last failed reload
+	LastErrorAt time.Time `json:"last_error_at"`
+}
+
+// Watcher is an interface that represents a watcher that reloads a source when it changes
+type Watcher interface {
+	// Status is a method that returns the status of the reloads
+	Status() (s ReloadStatus)
+}